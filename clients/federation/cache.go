@@ -0,0 +1,110 @@
+package federation
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCacheTTL is how long a Cache holds a stellar.toml file or a
+	// federation response.
+	DefaultCacheTTL = 10 * time.Minute
+
+	// DefaultCacheSize is the number of entries a Cache holds.
+	DefaultCacheSize = 1000
+)
+
+// Cache holds the stellar.toml files and federation responses a client has
+// received, so that lookups repeated within TTL are answered without a
+// request.  Stellar.toml files are keyed by domain, and federation responses
+// by domain, query type and query.  Only successful lookups are cached.  The
+// zero value is an empty cache ready to use, and a cache may be shared by
+// several clients.
+type Cache struct {
+	// TTL is how long an entry is used for.  Defaults to DefaultCacheTTL.
+	TTL time.Duration
+
+	// Size is the most entries the cache holds.  The least recently used is
+	// evicted to make room for a new one.  Defaults to DefaultCacheSize.
+	Size int
+
+	lock    sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+// cacheEntry is a value held by a Cache.
+type cacheEntry struct {
+	key      string
+	value    interface{}
+	storedAt time.Time
+}
+
+// Len returns the number of entries in the cache.
+func (c *Cache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.entries)
+}
+
+// get returns the value stored under `key`, if it has not expired.
+func (c *Cache) get(key string) (interface{}, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := el.Value.(*cacheEntry)
+	if time.Since(entry.storedAt) >= c.ttl() {
+		c.lru.Remove(el)
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	c.lru.MoveToFront(el)
+	return entry.value, true
+}
+
+// put stores `value` under `key`, evicting the least recently used entry if
+// the cache is full.
+func (c *Cache) put(key string, value interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.entries == nil {
+		c.entries = map[string]*list.Element{}
+		c.lru = list.New()
+	}
+
+	if el, ok := c.entries[key]; ok {
+		c.lru.Remove(el)
+		delete(c.entries, key)
+	}
+
+	for len(c.entries) >= c.size() {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+
+	entry := &cacheEntry{key: key, value: value, storedAt: time.Now()}
+	c.entries[key] = c.lru.PushFront(entry)
+}
+
+func (c *Cache) ttl() time.Duration {
+	if c.TTL <= 0 {
+		return DefaultCacheTTL
+	}
+	return c.TTL
+}
+
+func (c *Cache) size() int {
+	if c.Size <= 0 {
+		return DefaultCacheSize
+	}
+	return c.Size
+}
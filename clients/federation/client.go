@@ -1,6 +1,7 @@
 package federation
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -8,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/stellar/go/address"
+	"github.com/stellar/go/clients/stellartoml"
 	proto "github.com/stellar/go/protocols/federation"
 	"github.com/stellar/go/support/errors"
 )
@@ -23,6 +25,12 @@ func (c *Client) LookupByAddress(addy string) (*proto.NameResponse, error) {
 		return nil, errors.Wrap(err, "parse address failed")
 	}
 
+	key := federationKey(domain, "name", addy)
+	if cached, ok := c.cached(key); ok {
+		resp := cached.(proto.NameResponse)
+		return &resp, nil
+	}
+
 	fserv, err := c.getFederationServer(domain)
 	if err != nil {
 		return nil, errors.Wrap(err, "lookup federation server failed")
@@ -36,10 +44,12 @@ func (c *Client) LookupByAddress(addy string) (*proto.NameResponse, error) {
 		return nil, errors.Wrap(err, "get federation failed")
 	}
 
-	if resp.MemoType != "" && resp.Memo.String() == "" {
-		return nil, errors.New("Invalid federation response (memo)")
+	err = validateNameResponse(&resp)
+	if err != nil {
+		return nil, err
 	}
 
+	c.cache(key, resp)
 	return &resp, nil
 }
 
@@ -57,6 +67,12 @@ func (c *Client) LookupByAccountID(aid string) (*proto.IDResponse, error) {
 		return nil, errors.New("homedomain not set")
 	}
 
+	key := federationKey(domain, "id", aid)
+	if cached, ok := c.cached(key); ok {
+		resp := cached.(proto.IDResponse)
+		return &resp, nil
+	}
+
 	fserv, err := c.getFederationServer(domain)
 	if err != nil {
 		return nil, errors.Wrap(err, "lookup federation server failed")
@@ -70,13 +86,98 @@ func (c *Client) LookupByAccountID(aid string) (*proto.IDResponse, error) {
 		return nil, errors.Wrap(err, "get federation failed")
 	}
 
+	err = validateIDResponse(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	c.cache(key, resp)
+	return &resp, nil
+}
+
+// LookupByTransactionID performs a federated lookup following to the stellar
+// federation protocol using the "txid" type request, which asks the federation
+// server of `domain` for the address of the sender of the transaction with
+// the hex-encoded hash `txid`.
+func (c *Client) LookupByTransactionID(domain, txid string) (*proto.TxIDResponse, error) {
+	hash, err := hex.DecodeString(txid)
+	if err != nil || len(hash) != 32 {
+		return nil, errors.New("invalid transaction id")
+	}
+
+	key := federationKey(domain, "txid", txid)
+	if cached, ok := c.cached(key); ok {
+		resp := cached.(proto.TxIDResponse)
+		return &resp, nil
+	}
+
+	fserv, err := c.getFederationServer(domain)
+	if err != nil {
+		return nil, errors.Wrap(err, "lookup federation server failed")
+	}
+
+	url := c.url(fserv, "txid", txid)
+
+	var resp proto.TxIDResponse
+	err = c.getJSON(url, &resp)
+	if err != nil {
+		return nil, errors.Wrap(err, "get federation failed")
+	}
+
+	err = validateTxIDResponse(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	c.cache(key, resp)
+	return &resp, nil
+}
+
+// LookupForward performs a federated lookup following to the stellar
+// federation protocol using the "forward" type request, which asks the
+// federation server of `domain` for the account and memo that forward a
+// payment to the destination described by `query`.  The parameters `query`
+// should hold depend on the server, such as a "forward_type" of "bank_account"
+// along with the account's "swift" and "acct" numbers.
+func (c *Client) LookupForward(domain string, query url.Values) (*proto.NameResponse, error) {
+	qstr := url.Values{}
+	for k, v := range query {
+		qstr[k] = v
+	}
+	qstr.Set("type", "forward")
+
+	key := federationKey(domain, "forward", qstr.Encode())
+	if cached, ok := c.cached(key); ok {
+		resp := cached.(proto.NameResponse)
+		return &resp, nil
+	}
+
+	fserv, err := c.getFederationServer(domain)
+	if err != nil {
+		return nil, errors.Wrap(err, "lookup federation server failed")
+	}
+
+	url := fmt.Sprintf("%s?%s", fserv, qstr.Encode())
+
+	var resp proto.NameResponse
+	err = c.getJSON(url, &resp)
+	if err != nil {
+		return nil, errors.Wrap(err, "get federation failed")
+	}
+
+	err = validateNameResponse(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	c.cache(key, resp)
 	return &resp, nil
 }
 
 func (c *Client) getFederationServer(domain string) (string, error) {
-	stoml, err := c.StellarTOML.GetStellarToml(domain)
+	stoml, err := c.getStellarToml(domain)
 	if err != nil {
-		return "", errors.Wrap(err, "get stellar.toml failed")
+		return "", err
 	}
 
 	if stoml.FederationServer == "" {
@@ -90,6 +191,47 @@ func (c *Client) getFederationServer(domain string) (string, error) {
 	return stoml.FederationServer, nil
 }
 
+// getStellarToml returns the stellar.toml file of `domain`, from the cache if
+// it holds it.
+func (c *Client) getStellarToml(domain string) (*stellartoml.Response, error) {
+	key := "toml\x00" + domain
+	if cached, ok := c.cached(key); ok {
+		stoml := cached.(stellartoml.Response)
+		return &stoml, nil
+	}
+
+	stoml, err := c.StellarTOML.GetStellarToml(domain)
+	if err != nil {
+		return nil, errors.Wrap(err, "get stellar.toml failed")
+	}
+
+	c.cache(key, *stoml)
+	return stoml, nil
+}
+
+// cached returns the value stored under `key` in the client's cache, if it
+// has one and the value is there.
+func (c *Client) cached(key string) (interface{}, bool) {
+	if c.Cache == nil {
+		return nil, false
+	}
+	return c.Cache.get(key)
+}
+
+// cache stores `value` under `key` in the client's cache, if it has one.
+// Values are stored by value, so that callers cannot change them.
+func (c *Client) cache(key string, value interface{}) {
+	if c.Cache != nil {
+		c.Cache.put(key, value)
+	}
+}
+
+// federationKey returns the key under which the response to a query of type
+// `typ` for `q`, made to the federation server of `domain`, is cached.
+func federationKey(domain, typ, q string) string {
+	return strings.Join([]string{"federation", domain, typ, q}, "\x00")
+}
+
 // getJSON populates `dest` with the contents at `url`, provided the request
 // succeeds and the json can be successfully decoded.
 func (c *Client) getJSON(url string, dest interface{}) error {
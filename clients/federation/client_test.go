@@ -3,12 +3,15 @@ package federation
 import (
 	"errors"
 	"net/http"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stellar/go/clients/stellartoml"
 	"github.com/stellar/go/support/http/httptest"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLookupByAddress(t *testing.T) {
@@ -120,6 +123,210 @@ func TestLookupByAddress(t *testing.T) {
 	}
 }
 
+func TestLookupByAddress_Validation(t *testing.T) {
+	hmock := httptest.NewClient()
+	tomlmock := &stellartoml.MockClient{}
+	c := &Client{StellarTOML: tomlmock, HTTP: hmock}
+	tomlmock.On("GetStellarToml", "stellar.org").Return(&stellartoml.Response{
+		FederationServer: "https://stellar.org/federation",
+	}, nil)
+
+	cases := []struct {
+		Name   string
+		Resp   map[string]interface{}
+		Field  string
+		Reason string
+	}{
+		{"bad account id", map[string]interface{}{
+			"account_id": "GASTNVNLHVR3NFO3QACMHCJT3JUSIV4NBXDHDO4VTPDTNN65W3B2766D",
+		}, "account_id", "is not a valid account id"},
+		{"short account id", map[string]interface{}{
+			"account_id": "GAAQEAYEAUDAOCAJBIFQYDIOB4IBCEQTCQKRNDG2",
+		}, "account_id", "is not a valid account id"},
+		{"memo without type", map[string]interface{}{
+			"memo": "123",
+		}, "memo", "is set without memo_type"},
+		{"type without memo", map[string]interface{}{
+			"memo_type": "id",
+		}, "memo", "is missing"},
+		{"bad id", map[string]interface{}{
+			"memo_type": "id",
+			"memo":      "-1",
+		}, "memo", "is not a valid id"},
+		{"long text", map[string]interface{}{
+			"memo_type": "text",
+			"memo":      strings.Repeat("a", MaxTextMemoLength+1),
+		}, "memo", "is longer than 28 bytes"},
+		{"short hash", map[string]interface{}{
+			"memo_type": "hash",
+			"memo":      "AAAA",
+		}, "memo", "is not a base64 encoded 32 byte hash"},
+		{"unknown type", map[string]interface{}{
+			"memo_type": "return",
+			"memo":      "AAAA",
+		}, "memo_type", `"return" is not one of text, id or hash`},
+	}
+
+	for _, kase := range cases {
+		if _, ok := kase.Resp["account_id"]; !ok {
+			kase.Resp["account_id"] = "GASTNVNLHVR3NFO3QACMHCJT3JUSIV4NBXDHDO4VTPDTNN65W3B2766C"
+		}
+		hmock.On("GET", "https://stellar.org/federation").ReturnJSON(http.StatusOK, kase.Resp)
+
+		_, err := c.LookupByAddress("scott*stellar.org")
+		if assert.IsType(t, &InvalidResponseError{}, err, kase.Name) {
+			assert.Equal(t, kase.Field, err.(*InvalidResponseError).Field, kase.Name)
+			assert.Equal(t, kase.Reason, err.(*InvalidResponseError).Reason, kase.Name)
+		}
+	}
+
+	// a hash memo holds 32 bytes encoded in base64
+	hmock.On("GET", "https://stellar.org/federation").
+		ReturnJSON(http.StatusOK, map[string]interface{}{
+			"account_id": "GASTNVNLHVR3NFO3QACMHCJT3JUSIV4NBXDHDO4VTPDTNN65W3B2766C",
+			"memo_type":  "hash",
+			"memo":       "AQIDBAUGBwgJCgsMDQ4PEBESExQVFhcYGRobHB0eHyA=",
+		})
+	resp, err := c.LookupByAddress("scott*stellar.org")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "hash", resp.MemoType)
+	}
+}
+
+func TestClient_Cache(t *testing.T) {
+	hmock := httptest.NewClient()
+	tomlmock := &stellartoml.MockClient{}
+	c := &Client{StellarTOML: tomlmock, HTTP: hmock, Cache: &Cache{}}
+
+	tomlmock.On("GetStellarToml", "stellar.org").Return(&stellartoml.Response{
+		FederationServer: "https://stellar.org/federation",
+	}, nil)
+	hmock.On("GET", "https://stellar.org/federation").
+		ReturnJSON(http.StatusOK, map[string]string{
+			"account_id": "GASTNVNLHVR3NFO3QACMHCJT3JUSIV4NBXDHDO4VTPDTNN65W3B2766C",
+		})
+
+	resp, err := c.LookupByAddress("scott*stellar.org")
+	require.NoError(t, err)
+	resp.AccountID = "changed by the caller"
+
+	// the response and stellar.toml are served from the cache
+	hmock.On("GET", "https://stellar.org/federation").ReturnError("kaboom!")
+	resp, err = c.LookupByAddress("scott*stellar.org")
+	require.NoError(t, err)
+	assert.Equal(t, "GASTNVNLHVR3NFO3QACMHCJT3JUSIV4NBXDHDO4VTPDTNN65W3B2766C", resp.AccountID)
+	tomlmock.AssertNumberOfCalls(t, "GetStellarToml", 1)
+
+	// other queries to the same domain reuse its stellar.toml, and failures
+	// are not cached
+	_, err = c.LookupByAddress("bartek*stellar.org")
+	assert.Error(t, err)
+	_, err = c.LookupByAddress("bartek*stellar.org")
+	assert.Error(t, err)
+	tomlmock.AssertNumberOfCalls(t, "GetStellarToml", 1)
+	assert.Equal(t, 2, c.Cache.Len())
+
+	// expired entries are fetched again
+	c.Cache.TTL = time.Nanosecond
+	_, err = c.LookupByAddress("scott*stellar.org")
+	assert.Error(t, err)
+	tomlmock.AssertNumberOfCalls(t, "GetStellarToml", 2)
+}
+
+func TestCache(t *testing.T) {
+	cache := &Cache{Size: 2}
+	cache.put("a", 1)
+	cache.put("b", 2)
+
+	// reading a makes b the least recently used
+	v, ok := cache.get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	cache.put("c", 3)
+
+	_, ok = cache.get("b")
+	assert.False(t, ok)
+	_, ok = cache.get("a")
+	assert.True(t, ok)
+	_, ok = cache.get("c")
+	assert.True(t, ok)
+
+	// replacing an entry does not evict another
+	cache.put("c", 4)
+	assert.Equal(t, 2, cache.Len())
+	v, _ = cache.get("c")
+	assert.Equal(t, 4, v)
+}
+
+func TestLookupByTransactionID(t *testing.T) {
+	hmock := httptest.NewClient()
+	tomlmock := &stellartoml.MockClient{}
+	c := &Client{StellarTOML: tomlmock, HTTP: hmock}
+	txid := "5f3f6b9b5b1ce3d3b8c0bcb1d7a0b69e0ce0a5ad12ab4ae4a1bf0c58fb8c0e0e"
+
+	tomlmock.On("GetStellarToml", "stellar.org").Return(&stellartoml.Response{
+		FederationServer: "https://stellar.org/federation",
+	}, nil)
+	hmock.On("GET", "https://stellar.org/federation?q="+txid+"&type=txid").
+		ReturnJSON(http.StatusOK, map[string]string{
+			"stellar_address": "scott*stellar.org",
+		})
+	resp, err := c.LookupByTransactionID("stellar.org", txid)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "scott*stellar.org", resp.Address)
+	}
+
+	// an invalid transaction id is not sent
+	_, err = c.LookupByTransactionID("stellar.org", "abc")
+	if assert.Error(t, err) {
+		assert.Equal(t, "invalid transaction id", err.Error())
+	}
+
+	// an invalid address
+	hmock.On("GET", "https://stellar.org/federation?q="+txid+"&type=txid").
+		ReturnJSON(http.StatusOK, map[string]string{
+			"stellar_address": "scott",
+		})
+	_, err = c.LookupByTransactionID("stellar.org", txid)
+	assert.IsType(t, &InvalidResponseError{}, err)
+}
+
+func TestLookupForward(t *testing.T) {
+	hmock := httptest.NewClient()
+	tomlmock := &stellartoml.MockClient{}
+	c := &Client{StellarTOML: tomlmock, HTTP: hmock}
+	query := url.Values{
+		"forward_type": {"bank_account"},
+		"swift":        {"BOPBPHMM"},
+		"acct":         {"2382376"},
+	}
+
+	tomlmock.On("GetStellarToml", "bank.org").Return(&stellartoml.Response{
+		FederationServer: "https://bank.org/federation",
+	}, nil)
+	hmock.On("GET", "https://bank.org/federation?acct=2382376&forward_type=bank_account&swift=BOPBPHMM&type=forward").
+		ReturnJSON(http.StatusOK, map[string]string{
+			"account_id": "GASTNVNLHVR3NFO3QACMHCJT3JUSIV4NBXDHDO4VTPDTNN65W3B2766C",
+			"memo_type":  "id",
+			"memo":       "42",
+		})
+	resp, err := c.LookupForward("bank.org", query)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "GASTNVNLHVR3NFO3QACMHCJT3JUSIV4NBXDHDO4VTPDTNN65W3B2766C", resp.AccountID)
+		assert.Equal(t, "id", resp.MemoType)
+		assert.Equal(t, "42", resp.Memo.String())
+	}
+	// the caller's query is left unchanged
+	assert.Empty(t, query.Get("type"))
+
+	hmock.On("GET", "https://bank.org/federation?acct=2382376&forward_type=bank_account&swift=BOPBPHMM&type=forward").
+		ReturnNotFound()
+	_, err = c.LookupForward("bank.org", query)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "failed with (404)")
+	}
+}
+
 func TestLookupByID(t *testing.T) {
 	// HACK: until we improve our mocking scenario, this is just a smoke test.
 	// When/if it breaks, please write this test correctly.  That, or curse
@@ -31,6 +31,10 @@ type Client struct {
 	HTTP        HTTP
 	Horizon     Horizon
 	AllowHTTP   bool
+
+	// Cache, if set, holds the stellar.toml files and federation responses
+	// the client receives, so that repeated lookups are answered from it.
+	Cache *Cache
 }
 
 // Horizon represents a horizon client that can be consulted for data when
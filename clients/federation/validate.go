@@ -0,0 +1,94 @@
+package federation
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+
+	"github.com/stellar/go/address"
+	proto "github.com/stellar/go/protocols/federation"
+	"github.com/stellar/go/strkey"
+)
+
+// MaxTextMemoLength is the most bytes a text memo can hold.
+const MaxTextMemoLength = 28
+
+// InvalidResponseError is the error returned when a federation server sends a
+// response that does not follow the federation protocol.
+type InvalidResponseError struct {
+	// Field is the json name of the invalid field of the response.
+	Field string
+
+	// Reason describes what is wrong with the field.
+	Reason string
+}
+
+func (err *InvalidResponseError) Error() string {
+	return fmt.Sprintf("invalid federation response: %s %s", err.Field, err.Reason)
+}
+
+// validateNameResponse checks that `resp` holds a valid account id and, if it
+// holds a memo, that the memo's value is valid for its type.
+func validateNameResponse(resp *proto.NameResponse) error {
+	_, err := strkey.ParseAccountID(resp.AccountID)
+	if err != nil {
+		return &InvalidResponseError{Field: "account_id", Reason: "is not a valid account id"}
+	}
+
+	memo := resp.Memo.String()
+	if resp.MemoType == "" {
+		if memo != "" {
+			return &InvalidResponseError{Field: "memo", Reason: "is set without memo_type"}
+		}
+		return nil
+	}
+
+	if memo == "" {
+		return &InvalidResponseError{Field: "memo", Reason: "is missing"}
+	}
+
+	switch resp.MemoType {
+	case "text":
+		if len(memo) > MaxTextMemoLength {
+			return &InvalidResponseError{
+				Field:  "memo",
+				Reason: fmt.Sprintf("is longer than %d bytes", MaxTextMemoLength),
+			}
+		}
+	case "id":
+		_, err := strconv.ParseUint(memo, 10, 64)
+		if err != nil {
+			return &InvalidResponseError{Field: "memo", Reason: "is not a valid id"}
+		}
+	case "hash":
+		hash, err := base64.StdEncoding.DecodeString(memo)
+		if err != nil || len(hash) != 32 {
+			return &InvalidResponseError{Field: "memo", Reason: "is not a base64 encoded 32 byte hash"}
+		}
+	default:
+		return &InvalidResponseError{
+			Field:  "memo_type",
+			Reason: fmt.Sprintf("%q is not one of text, id or hash", resp.MemoType),
+		}
+	}
+
+	return nil
+}
+
+// validateIDResponse checks that `resp` holds a valid stellar address.
+func validateIDResponse(resp *proto.IDResponse) error {
+	return validateAddress(resp.Address)
+}
+
+// validateTxIDResponse checks that `resp` holds a valid stellar address.
+func validateTxIDResponse(resp *proto.TxIDResponse) error {
+	return validateAddress(resp.Address)
+}
+
+func validateAddress(addy string) error {
+	_, _, err := address.Split(addy)
+	if err != nil {
+		return &InvalidResponseError{Field: "stellar_address", Reason: "is not a valid stellar address"}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package horizon
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCacheTTL is how long a Cache serves the responses for immutable
+	// resources without asking horizon for them again.
+	DefaultCacheTTL = time.Hour
+
+	// DefaultCacheSize is the number of responses a Cache holds.
+	DefaultCacheSize = 1000
+)
+
+// rateLimitHeaders are the headers in which horizon reports the rate limit at
+// the time of a response.  They are not cached, so that a cached response does
+// not replay a rate limit that has since reset.
+var rateLimitHeaders = []string{"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"}
+
+// immutablePath matches the paths of resources that never change once
+// horizon has them: ledgers, transactions and operations.
+var immutablePath = regexp.MustCompile(`/(ledgers/[0-9]+|transactions/[0-9a-f]{64}|operations/[0-9]+)$`)
+
+// Cache caches the responses to a client's requests.  Use its Middleware as
+// one of the client's middleware.
+//
+// The responses for ledgers, transactions and operations, which never change,
+// are served from the cache for TTL without a request being sent.  The
+// responses for other resources are cached if horizon sent an ETag for them,
+// and are revalidated with If-None-Match each time they are requested, so
+// that horizon needs to send them again only if they changed.
+//
+// Only successful responses to GET requests are cached, and streams are never
+// cached.  Responses served from the cache carry no rate limit headers, except
+// for those of the response with which horizon revalidated them.  The zero
+// value is an empty cache ready to use.
+type Cache struct {
+	// TTL is how long responses for immutable resources are served without
+	// a request.  Defaults to DefaultCacheTTL.
+	TTL time.Duration
+
+	// Size is the most responses the cache holds.  The oldest is evicted to
+	// make room for a new one.  Defaults to DefaultCacheSize.
+	Size int
+
+	lock    sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+// cacheEntry is a response held by a Cache.
+type cacheEntry struct {
+	status    int
+	header    http.Header
+	body      []byte
+	etag      string
+	immutable bool
+	storedAt  time.Time
+}
+
+// Middleware serves the requests it can from the cache, and caches the
+// responses to the others.
+func (c *Cache) Middleware(next Doer) Doer {
+	return DoerFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method != "GET" || req.Header.Get("Accept") == "text/event-stream" {
+			return next.Do(req)
+		}
+
+		key := req.URL.String()
+		entry := c.get(key)
+		if entry != nil && entry.immutable && time.Since(entry.storedAt) < c.ttl() {
+			return entry.response(req), nil
+		}
+
+		out := req
+		if entry != nil && entry.etag != "" {
+			out = withHeader(req, "If-None-Match", entry.etag)
+		}
+
+		resp, err := next.Do(out)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusNotModified && entry != nil {
+			resp.Body.Close()
+			c.put(key, entry.refreshed())
+
+			cached := entry.response(req)
+			for _, name := range rateLimitHeaders {
+				if value := resp.Header.Get(name); value != "" {
+					cached.Header.Set(name, value)
+				}
+			}
+			return cached, nil
+		}
+
+		etag := resp.Header.Get("ETag")
+		immutable := immutablePath.MatchString(req.URL.Path)
+		if resp.StatusCode != http.StatusOK || (etag == "" && !immutable) {
+			return resp, nil
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		header := cloneHeader(resp.Header)
+		for _, name := range rateLimitHeaders {
+			header.Del(name)
+		}
+
+		c.put(key, &cacheEntry{
+			status:    resp.StatusCode,
+			header:    header,
+			body:      body,
+			etag:      etag,
+			immutable: immutable,
+			storedAt:  time.Now(),
+		})
+
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+		return resp, nil
+	})
+}
+
+// Len returns the number of responses in the cache.
+func (c *Cache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.entries)
+}
+
+func (c *Cache) get(key string) *cacheEntry {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.entries[key]
+}
+
+// put stores `entry` under `key`, evicting the oldest entry if the cache is
+// full.
+func (c *Cache) put(key string, entry *cacheEntry) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.entries == nil {
+		c.entries = map[string]*cacheEntry{}
+	}
+
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.size() {
+		var oldest string
+		for k, e := range c.entries {
+			if oldest == "" || e.storedAt.Before(c.entries[oldest].storedAt) {
+				oldest = k
+			}
+		}
+		delete(c.entries, oldest)
+	}
+
+	c.entries[key] = entry
+}
+
+func (c *Cache) ttl() time.Duration {
+	if c.TTL <= 0 {
+		return DefaultCacheTTL
+	}
+	return c.TTL
+}
+
+func (c *Cache) size() int {
+	if c.Size <= 0 {
+		return DefaultCacheSize
+	}
+	return c.Size
+}
+
+// refreshed returns a copy of the entry stored now, after horizon confirmed it
+// is still current.
+func (e *cacheEntry) refreshed() *cacheEntry {
+	refreshed := *e
+	refreshed.storedAt = time.Now()
+	return &refreshed
+}
+
+// response returns the cached response as the response to `req`.
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.status, http.StatusText(e.status)),
+		StatusCode:    e.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        cloneHeader(e.header),
+		Body:          ioutil.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
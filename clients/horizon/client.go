@@ -1,13 +1,13 @@
 package horizon
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/xdr"
@@ -17,7 +17,13 @@ import (
 // HomeDomainForAccount returns the home domain for the provided strkey-encoded
 // account id.
 func (c *Client) HomeDomainForAccount(aid string) (string, error) {
-	a, err := c.LoadAccount(aid)
+	return c.HomeDomainForAccountContext(context.Background(), aid)
+}
+
+// HomeDomainForAccountContext is like HomeDomainForAccount, but loads the
+// account using the provided context.
+func (c *Client) HomeDomainForAccountContext(ctx context.Context, aid string) (string, error) {
+	a, err := c.LoadAccountContext(ctx, aid)
 	if err != nil {
 		return "", errors.Wrap(err, "load account failed")
 	}
@@ -26,68 +32,39 @@ func (c *Client) HomeDomainForAccount(aid string) (string, error) {
 
 // LoadAccount loads the account state from horizon. err can be either error
 // object or horizon.Error object.
-func (c *Client) LoadAccount(accountID string) (account Account, err error) {
-	resp, err := c.HTTP.Get(c.URL + "/accounts/" + accountID)
-	if err != nil {
-		return
-	}
+func (c *Client) LoadAccount(accountID string) (Account, error) {
+	return c.LoadAccountContext(context.Background(), accountID)
+}
 
-	err = decodeResponse(resp, &account)
+// LoadAccountContext is like LoadAccount, but the request is cancelled if ctx
+// is done before the response is received.
+func (c *Client) LoadAccountContext(ctx context.Context, accountID string) (account Account, err error) {
+	err = c.loadResource(ctx, c.URL+"/accounts/"+accountID, &account)
 	return
 }
 
 // LoadAccountOffers loads the account offers from horizon. err can be either
 // error object or horizon.Error object.
-func (c *Client) LoadAccountOffers(accountID string, params ...interface{}) (offers OffersPage, err error) {
-
-	endpoint := ""
-	query := url.Values{}
-
-	for _, param := range params {
-		switch param := param.(type) {
-		case At:
-			endpoint = string(param)
-		case Limit:
-			query.Add("limit", strconv.Itoa(int(param)))
-		case Order:
-			query.Add("order", string(param))
-		case Cursor:
-			query.Add("cursor", string(param))
-		default:
-			err = fmt.Errorf("Undefined parameter: %+v", param)
-			return
-		}
-	}
-
-	if endpoint == "" {
-		endpoint = fmt.Sprintf(
-			"%s/accounts/%s/offers?%s",
-			c.URL,
-			accountID,
-			query.Encode(),
-		)
-	}
-
-	// ensure our endpoint is a real url
-	_, err = url.Parse(endpoint)
-	if err != nil {
-		err = errors.Wrap(err, "failed to parse endpoint")
-		return
-	}
-
-	resp, err := c.HTTP.Get(endpoint)
-	if err != nil {
-		err = errors.Wrap(err, "failed to load endpoint")
-		return
-	}
+func (c *Client) LoadAccountOffers(accountID string, params ...interface{}) (OffersPage, error) {
+	return c.LoadAccountOffersContext(context.Background(), accountID, params...)
+}
 
-	err = decodeResponse(resp, &offers)
+// LoadAccountOffersContext is like LoadAccountOffers, but the request is
+// cancelled if ctx is done before the response is received.
+func (c *Client) LoadAccountOffersContext(ctx context.Context, accountID string, params ...interface{}) (offers OffersPage, err error) {
+	err = c.loadCollection(ctx, fmt.Sprintf("/accounts/%s/offers", accountID), nil, params, &offers)
 	return
 }
 
 // LoadMemo loads memo for a transaction in Payment
-func (c *Client) LoadMemo(p *Payment) (err error) {
-	res, err := c.HTTP.Get(p.Links.Transaction.Href)
+func (c *Client) LoadMemo(p *Payment) error {
+	return c.LoadMemoContext(context.Background(), p)
+}
+
+// LoadMemoContext is like LoadMemo, but the request is cancelled if ctx is
+// done before the response is received.
+func (c *Client) LoadMemoContext(ctx context.Context, p *Payment) (err error) {
+	res, err := c.get(ctx, p.Links.Transaction.Href)
 	if err != nil {
 		return errors.Wrap(err, "load transaction failed")
 	}
@@ -99,8 +76,17 @@ func (c *Client) LoadMemo(p *Payment) (err error) {
 func (c *Client) SequenceForAccount(
 	accountID string,
 ) (xdr.SequenceNumber, error) {
+	return c.SequenceForAccountContext(context.Background(), accountID)
+}
+
+// SequenceForAccountContext is like SequenceForAccount, but loads the account
+// using the provided context.
+func (c *Client) SequenceForAccountContext(
+	ctx context.Context,
+	accountID string,
+) (xdr.SequenceNumber, error) {
 
-	a, err := c.LoadAccount(accountID)
+	a, err := c.LoadAccountContext(ctx, accountID)
 	if err != nil {
 		return 0, errors.Wrap(err, "load account failed")
 	}
@@ -114,171 +100,384 @@ func (c *Client) SequenceForAccount(
 }
 
 // LoadOrderBook loads order book for given selling and buying assets.
-func (c *Client) LoadOrderBook(selling Asset, buying Asset) (orderBook OrderBookSummary, err error) {
+func (c *Client) LoadOrderBook(selling Asset, buying Asset) (OrderBookSummary, error) {
+	return c.LoadOrderBookContext(context.Background(), selling, buying)
+}
+
+// LoadOrderBookContext is like LoadOrderBook, but the request is cancelled if
+// ctx is done before the response is received.
+func (c *Client) LoadOrderBookContext(ctx context.Context, selling Asset, buying Asset) (orderBook OrderBookSummary, err error) {
 	query := url.Values{}
+	addAsset(query, "selling_", selling)
+	addAsset(query, "buying_", buying)
 
-	query.Add("selling_asset_type", selling.Type)
-	query.Add("selling_asset_code", selling.Code)
-	query.Add("selling_asset_issuer", selling.Issuer)
+	err = c.loadResource(ctx, c.URL+"/order_book?"+query.Encode(), &orderBook)
+	return
+}
 
-	query.Add("buying_asset_type", buying.Type)
-	query.Add("buying_asset_code", buying.Code)
-	query.Add("buying_asset_issuer", buying.Issuer)
+// LoadPaths loads the paths by which `sourceAccount` could pay
+// `destinationAmount` of `destinationAsset` to `destinationAccount`, one for
+// each asset the source account holds that can be sent.  Use
+// PayWithCheapestPath to pay along the cheapest of them.
+func (c *Client) LoadPaths(
+	sourceAccount, destinationAccount string,
+	destinationAsset Asset,
+	destinationAmount string,
+) (PathsPage, error) {
+	return c.LoadPathsContext(context.Background(), sourceAccount, destinationAccount, destinationAsset, destinationAmount)
+}
 
-	resp, err := c.HTTP.Get(c.URL + "/order_book?" + query.Encode())
-	if err != nil {
-		return
-	}
+// LoadPathsContext is like LoadPaths, but the request is cancelled if ctx is
+// done before the response is received.
+func (c *Client) LoadPathsContext(
+	ctx context.Context,
+	sourceAccount, destinationAccount string,
+	destinationAsset Asset,
+	destinationAmount string,
+) (page PathsPage, err error) {
+	query := url.Values{}
+	query.Set("source_account", sourceAccount)
+	query.Set("destination_account", destinationAccount)
+	addAsset(query, "destination_", destinationAsset)
+	query.Set("destination_amount", destinationAmount)
 
-	err = decodeResponse(resp, &orderBook)
+	err = c.loadCollection(ctx, "/paths", query, nil, &page)
 	return
 }
 
-func (c *Client) stream(ctx context.Context, baseURL string, cursor *Cursor, handler func(data []byte) error) error {
-	query := url.Values{}
-	if cursor != nil {
-		query.Set("cursor", string(*cursor))
-	}
+// Root loads the root resource of horizon, which describes the horizon and
+// stellar-core versions and the range of ledgers available.
+func (c *Client) Root() (Root, error) {
+	return c.RootContext(context.Background())
+}
 
-	for {
-		req, err := http.NewRequest("GET", fmt.Sprintf("%s?%s", baseURL, query.Encode()), nil)
-		if err != nil {
-			return err
-		}
-		req.Header.Set("Accept", "text/event-stream")
+// RootContext is like Root, but the request is cancelled if ctx is done before
+// the response is received.
+func (c *Client) RootContext(ctx context.Context) (root Root, err error) {
+	err = c.loadResource(ctx, c.URL, &root)
+	return
+}
 
-		resp, err := c.HTTP.Do(req)
-		if err != nil {
-			return err
-		}
-		defer resp.Body.Close()
-		scanner := bufio.NewScanner(resp.Body)
-		scanner.Split(splitSSE)
-
-		var objectBytes []byte
-
-		for scanner.Scan() {
-			// Check if ctx is not cancelled
-			select {
-			case <-ctx.Done():
-				return nil
-			default:
-				// Continue streaming
-			}
-
-			if len(scanner.Bytes()) == 0 {
-				continue
-			}
-
-			ev, err := parseEvent(scanner.Bytes())
-			if err != nil {
-				return err
-			}
-
-			if ev.Event != "message" {
-				continue
-			}
-
-			switch data := ev.Data.(type) {
-			case string:
-				err = handler([]byte(data))
-				objectBytes = []byte(data)
-			case []byte:
-				err = handler(data)
-				objectBytes = data
-			default:
-				err = errors.New("Invalid ev.Data type")
-			}
-			if err != nil {
-				return err
-			}
-		}
+// LoadTransaction loads the transaction with the provided hash.
+func (c *Client) LoadTransaction(hash string) (Transaction, error) {
+	return c.LoadTransactionContext(context.Background(), hash)
+}
 
-		err = scanner.Err()
+// LoadTransactionContext is like LoadTransaction, but the request is
+// cancelled if ctx is done before the response is received.
+func (c *Client) LoadTransactionContext(ctx context.Context, hash string) (transaction Transaction, err error) {
+	err = c.loadResource(ctx, c.URL+"/transactions/"+hash, &transaction)
+	return
+}
 
-		// Start streaming from the next object:
-		// - if there was no error OR
-		// - if connection was lost
-		if err == nil || err == io.ErrUnexpectedEOF {
-			object := struct {
-				PT string `json:"paging_token"`
-			}{}
+// LoadTransactions loads a page of transactions.  Pass ForAccount or ForLedger
+// to only load the transactions of one account or ledger.
+func (c *Client) LoadTransactions(params ...interface{}) (TransactionsPage, error) {
+	return c.LoadTransactionsContext(context.Background(), params...)
+}
 
-			err := json.Unmarshal(objectBytes, &object)
-			if err != nil {
-				return errors.Wrap(err, "Error unmarshaling objectBytes")
-			}
+// LoadTransactionsContext is like LoadTransactions, but the request is
+// cancelled if ctx is done before the response is received.
+func (c *Client) LoadTransactionsContext(ctx context.Context, params ...interface{}) (page TransactionsPage, err error) {
+	err = c.loadCollection(ctx, "/transactions", nil, params, &page)
+	return
+}
 
-			if object.PT != "" {
-				query.Set("cursor", object.PT)
-			} else {
-				return errors.New("no paging_token in object: cannot continue")
-			}
+// LoadOperations loads a page of operations.  Pass ForAccount, ForLedger or
+// ForTransaction to only load the operations of one account, ledger or
+// transaction.
+func (c *Client) LoadOperations(params ...interface{}) (OperationsPage, error) {
+	return c.LoadOperationsContext(context.Background(), params...)
+}
 
-			continue
-		}
+// LoadOperationsContext is like LoadOperations, but the request is cancelled
+// if ctx is done before the response is received.
+func (c *Client) LoadOperationsContext(ctx context.Context, params ...interface{}) (page OperationsPage, err error) {
+	err = c.loadCollection(ctx, "/operations", nil, params, &page)
+	return
+}
 
-		if err != nil {
-			return err
-		}
-	}
+// LoadEffects loads a page of effects.  Pass ForAccount, ForLedger or
+// ForTransaction to only load the effects of one account, ledger or
+// transaction.
+func (c *Client) LoadEffects(params ...interface{}) (EffectsPage, error) {
+	return c.LoadEffectsContext(context.Background(), params...)
+}
+
+// LoadEffectsContext is like LoadEffects, but the request is cancelled if ctx
+// is done before the response is received.
+func (c *Client) LoadEffectsContext(ctx context.Context, params ...interface{}) (page EffectsPage, err error) {
+	err = c.loadCollection(ctx, "/effects", nil, params, &page)
+	return
+}
+
+// LoadPayments loads a page of payments.  Pass ForAccount, ForLedger or
+// ForTransaction to only load the payments of one account, ledger or
+// transaction.
+func (c *Client) LoadPayments(params ...interface{}) (PaymentsPage, error) {
+	return c.LoadPaymentsContext(context.Background(), params...)
+}
+
+// LoadPaymentsContext is like LoadPayments, but the request is cancelled if
+// ctx is done before the response is received.
+func (c *Client) LoadPaymentsContext(ctx context.Context, params ...interface{}) (page PaymentsPage, err error) {
+	err = c.loadCollection(ctx, "/payments", nil, params, &page)
+	return
+}
+
+// LoadLedger loads the ledger with the provided sequence number.
+func (c *Client) LoadLedger(sequence int32) (Ledger, error) {
+	return c.LoadLedgerContext(context.Background(), sequence)
+}
+
+// LoadLedgerContext is like LoadLedger, but the request is cancelled if ctx is
+// done before the response is received.
+func (c *Client) LoadLedgerContext(ctx context.Context, sequence int32) (ledger Ledger, err error) {
+	err = c.loadResource(ctx, fmt.Sprintf("%s/ledgers/%d", c.URL, sequence), &ledger)
+	return
+}
+
+// LoadLedgers loads a page of ledgers.
+func (c *Client) LoadLedgers(params ...interface{}) (LedgersPage, error) {
+	return c.LoadLedgersContext(context.Background(), params...)
+}
+
+// LoadLedgersContext is like LoadLedgers, but the request is cancelled if ctx
+// is done before the response is received.
+func (c *Client) LoadLedgersContext(ctx context.Context, params ...interface{}) (page LedgersPage, err error) {
+	err = c.loadCollection(ctx, "/ledgers", nil, params, &page)
+	return
+}
+
+// LoadAssets loads a page of asset statistics.  Pass AssetCode or AssetIssuer
+// to filter the assets loaded.
+func (c *Client) LoadAssets(params ...interface{}) (AssetsPage, error) {
+	return c.LoadAssetsContext(context.Background(), params...)
+}
+
+// LoadAssetsContext is like LoadAssets, but the request is cancelled if ctx is
+// done before the response is received.
+func (c *Client) LoadAssetsContext(ctx context.Context, params ...interface{}) (page AssetsPage, err error) {
+	err = c.loadCollection(ctx, "/assets", nil, params, &page)
+	return
+}
+
+// LoadTrades loads a page of the trades between the provided base and counter
+// assets.
+func (c *Client) LoadTrades(base, counter Asset, params ...interface{}) (TradesPage, error) {
+	return c.LoadTradesContext(context.Background(), base, counter, params...)
+}
+
+// LoadTradesContext is like LoadTrades, but the request is cancelled if ctx is
+// done before the response is received.
+func (c *Client) LoadTradesContext(ctx context.Context, base, counter Asset, params ...interface{}) (page TradesPage, err error) {
+	err = c.loadCollection(ctx, "/trades", tradesQuery(base, counter), params, &page)
+	return
+}
+
+// LoadTradeAggregations loads a page of the trades between the provided base
+// and counter assets from `start` until `end`, aggregated into buckets of
+// `resolution`.
+func (c *Client) LoadTradeAggregations(
+	base, counter Asset,
+	start, end time.Time,
+	resolution time.Duration,
+	params ...interface{},
+) (TradeAggregationsPage, error) {
+	return c.LoadTradeAggregationsContext(context.Background(), base, counter, start, end, resolution, params...)
+}
+
+// LoadTradeAggregationsContext is like LoadTradeAggregations, but the request
+// is cancelled if ctx is done before the response is received.
+func (c *Client) LoadTradeAggregationsContext(
+	ctx context.Context,
+	base, counter Asset,
+	start, end time.Time,
+	resolution time.Duration,
+	params ...interface{},
+) (page TradeAggregationsPage, err error) {
+	query := tradeAggregationsQuery(base, counter, start, end, resolution)
+	err = c.loadCollection(ctx, "/trade_aggregations", query, params, &page)
+	return
+}
+
+// tradesQuery returns the query selecting the trades between the provided
+// base and counter assets.
+func tradesQuery(base, counter Asset) url.Values {
+	query := url.Values{}
+	addAsset(query, "base_", base)
+	addAsset(query, "counter_", counter)
+	return query
+}
 
-	return nil
+// tradeAggregationsQuery returns the query selecting the aggregations of the
+// trades between the provided base and counter assets.
+func tradeAggregationsQuery(
+	base, counter Asset,
+	start, end time.Time,
+	resolution time.Duration,
+) url.Values {
+	query := tradesQuery(base, counter)
+	query.Set("start_time", strconv.FormatInt(millis(start), 10))
+	query.Set("end_time", strconv.FormatInt(millis(end), 10))
+	query.Set("resolution", strconv.FormatInt(int64(resolution/time.Millisecond), 10))
+	return query
 }
 
 // StreamLedgers streams incoming ledgers. Use context.WithCancel to stop streaming or
 // context.Background() if you want to stream indefinitely.
-func (c *Client) StreamLedgers(ctx context.Context, cursor *Cursor, handler LedgerHandler) (err error) {
+func (c *Client) StreamLedgers(ctx context.Context, cursor *Cursor, handler LedgerHandler) error {
 	url := fmt.Sprintf("%s/ledgers", c.URL)
 	return c.stream(ctx, url, cursor, func(data []byte) error {
 		var ledger Ledger
-		err = json.Unmarshal(data, &ledger)
+		err := json.Unmarshal(data, &ledger)
 		if err != nil {
 			return errors.Wrap(err, "Error unmarshaling data")
 		}
-		handler(ledger)
-		return nil
+		return handler(ledger)
 	})
 }
 
-// StreamPayments streams incoming payments. Use context.WithCancel to stop streaming or
-// context.Background() if you want to stream indefinitely.
-func (c *Client) StreamPayments(ctx context.Context, accountID string, cursor *Cursor, handler PaymentHandler) (err error) {
+// StreamPayments streams incoming payments, which are *CreateAccount, *Payment,
+// *PathPayment or *AccountMerge operations. Use context.WithCancel to stop
+// streaming or context.Background() if you want to stream indefinitely.
+func (c *Client) StreamPayments(ctx context.Context, accountID string, cursor *Cursor, handler PaymentHandler) error {
 	url := fmt.Sprintf("%s/accounts/%s/payments", c.URL, accountID)
 	return c.stream(ctx, url, cursor, func(data []byte) error {
-		var payment Payment
-		err = json.Unmarshal(data, &payment)
+		payment, err := UnmarshalOperation(data)
 		if err != nil {
 			return errors.Wrap(err, "Error unmarshaling data")
 		}
-		handler(payment)
-		return nil
+		return handler(payment)
 	})
 }
 
 // StreamTransactions streams incoming transactions. Use context.WithCancel to stop streaming or
 // context.Background() if you want to stream indefinitely.
-func (c *Client) StreamTransactions(ctx context.Context, accountID string, cursor *Cursor, handler TransactionHandler) (err error) {
+func (c *Client) StreamTransactions(ctx context.Context, accountID string, cursor *Cursor, handler TransactionHandler) error {
 	url := fmt.Sprintf("%s/accounts/%s/transactions", c.URL, accountID)
 	return c.stream(ctx, url, cursor, func(data []byte) error {
 		var transaction Transaction
-		err = json.Unmarshal(data, &transaction)
+		err := json.Unmarshal(data, &transaction)
+		if err != nil {
+			return errors.Wrap(err, "Error unmarshaling data")
+		}
+		return handler(transaction)
+	})
+}
+
+// StreamOperations streams incoming operations of every type.  The stream can
+// be scoped to an account or ledger with the ForAccount or ForLedger params.
+// Use context.WithCancel to stop streaming or context.Background() if you want
+// to stream indefinitely.
+func (c *Client) StreamOperations(ctx context.Context, cursor *Cursor, handler OperationHandler, params ...interface{}) error {
+	url, err := c.streamURL("/operations", nil, params)
+	if err != nil {
+		return err
+	}
+
+	return c.stream(ctx, url, cursor, func(data []byte) error {
+		op, err := UnmarshalOperation(data)
+		if err != nil {
+			return errors.Wrap(err, "Error unmarshaling data")
+		}
+		return handler(op)
+	})
+}
+
+// StreamEffects streams incoming effects of every type.  The stream can be
+// scoped to an account or ledger with the ForAccount or ForLedger params.  Use
+// context.WithCancel to stop streaming or context.Background() if you want to
+// stream indefinitely.
+func (c *Client) StreamEffects(ctx context.Context, cursor *Cursor, handler EffectHandler, params ...interface{}) error {
+	url, err := c.streamURL("/effects", nil, params)
+	if err != nil {
+		return err
+	}
+
+	return c.stream(ctx, url, cursor, func(data []byte) error {
+		effect, err := UnmarshalEffect(data)
+		if err != nil {
+			return errors.Wrap(err, "Error unmarshaling data")
+		}
+		return handler(effect)
+	})
+}
+
+// StreamTrades streams incoming trades between the base and counter assets.
+// Use context.WithCancel to stop streaming or context.Background() if you want
+// to stream indefinitely.
+func (c *Client) StreamTrades(ctx context.Context, base, counter Asset, cursor *Cursor, handler TradeHandler) error {
+	query := url.Values{}
+	addAsset(query, "base_", base)
+	addAsset(query, "counter_", counter)
+
+	url := fmt.Sprintf("%s/trades?%s", c.URL, query.Encode())
+	return c.stream(ctx, url, cursor, func(data []byte) error {
+		var trade Trade
+		err := json.Unmarshal(data, &trade)
+		if err != nil {
+			return errors.Wrap(err, "Error unmarshaling data")
+		}
+		return handler(trade)
+	})
+}
+
+// StreamOffers streams the offers of an account as they are created or
+// updated. Use context.WithCancel to stop streaming or context.Background() if
+// you want to stream indefinitely.
+func (c *Client) StreamOffers(ctx context.Context, accountID string, cursor *Cursor, handler OfferHandler) error {
+	url := fmt.Sprintf("%s/accounts/%s/offers", c.URL, accountID)
+	return c.stream(ctx, url, cursor, func(data []byte) error {
+		var offer Offer
+		err := json.Unmarshal(data, &offer)
+		if err != nil {
+			return errors.Wrap(err, "Error unmarshaling data")
+		}
+		return handler(offer)
+	})
+}
+
+// StreamOrderBook streams the order book of the selling and buying assets,
+// calling handler with a new summary each time the order book changes.  Order
+// book summaries have no cursor, so a stream that reconnects starts again from
+// the current state of the order book. Use context.WithCancel to stop
+// streaming or context.Background() if you want to stream indefinitely.
+func (c *Client) StreamOrderBook(ctx context.Context, selling, buying Asset, handler OrderBookHandler) error {
+	query := url.Values{}
+	addAsset(query, "selling_", selling)
+	addAsset(query, "buying_", buying)
+
+	url := fmt.Sprintf("%s/order_book?%s", c.URL, query.Encode())
+	return c.stream(ctx, url, nil, func(data []byte) error {
+		var orderBook OrderBookSummary
+		err := json.Unmarshal(data, &orderBook)
 		if err != nil {
 			return errors.Wrap(err, "Error unmarshaling data")
 		}
-		handler(transaction)
-		return nil
+		return handler(orderBook)
 	})
 }
 
 // SubmitTransaction submits a transaction to the network. err can be either error object or horizon.Error object.
 func (c *Client) SubmitTransaction(
 	transactionEnvelopeXdr string,
+) (TransactionSuccess, error) {
+	return c.SubmitTransactionContext(context.Background(), transactionEnvelopeXdr)
+}
+
+// SubmitTransactionContext is like SubmitTransaction, but the request is
+// cancelled if ctx is done before the response is received.  Note that a
+// transaction whose submission was cancelled may still have been received by
+// horizon and be included in a ledger.
+func (c *Client) SubmitTransactionContext(
+	ctx context.Context,
+	transactionEnvelopeXdr string,
 ) (response TransactionSuccess, err error) {
 	v := url.Values{}
 	v.Set("tx", transactionEnvelopeXdr)
 
-	resp, err := c.HTTP.PostForm(c.URL+"/transactions", v)
+	resp, err := c.postForm(ctx, c.URL+"/transactions", v)
 	if err != nil {
 		err = errors.Wrap(err, "http post failed")
 		return
@@ -291,3 +490,35 @@ func (c *Client) SubmitTransaction(
 
 	return
 }
+
+// get makes a GET request to `endpoint` that is cancelled when ctx is done.
+func (c *Client) get(ctx context.Context, endpoint string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.do(ctx, req)
+}
+
+// loadResource loads the single resource at `endpoint` into `dest`.
+func (c *Client) loadResource(ctx context.Context, endpoint string, dest interface{}) error {
+	resp, err := c.get(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+
+	return decodeResponse(resp, dest)
+}
+
+// postForm makes a form encoded POST request to `endpoint` that is cancelled
+// when ctx is done.
+func (c *Client) postForm(ctx context.Context, endpoint string, data url.Values) (*http.Response, error) {
+	req, err := http.NewRequest("POST", endpoint, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return c.do(ctx, req)
+}
@@ -0,0 +1,263 @@
+package horizon
+
+import (
+	"encoding/json"
+
+	"github.com/stellar/go/support/errors"
+)
+
+// Effect is the response for one of horizon's effect types.  Values are
+// pointers to the struct of their type, such as *AccountCreated or
+// *TrustlineCreated, and can be told apart with a type switch.  Effects of
+// types this package does not know about are returned as a *BaseEffect.
+type Effect interface {
+	// Base returns the fields common to effects of every type.
+	Base() BaseEffect
+}
+
+// BaseEffect holds the fields common to effects of every type.
+type BaseEffect struct {
+	Links struct {
+		Operation Link `json:"operation"`
+		Succeeds  Link `json:"succeeds"`
+		Precedes  Link `json:"precedes"`
+	} `json:"_links"`
+
+	ID      string `json:"id"`
+	PT      string `json:"paging_token"`
+	Account string `json:"account"`
+	Type    string `json:"type"`
+	TypeI   int32  `json:"type_i"`
+}
+
+// Base implements Effect.
+func (effect BaseEffect) Base() BaseEffect {
+	return effect
+}
+
+type AccountCreated struct {
+	BaseEffect
+	StartingBalance string `json:"starting_balance"`
+}
+
+type AccountRemoved struct {
+	BaseEffect
+}
+
+type AccountCredited struct {
+	BaseEffect
+	AssetType   string `json:"asset_type"`
+	AssetCode   string `json:"asset_code,omitempty"`
+	AssetIssuer string `json:"asset_issuer,omitempty"`
+	Amount      string `json:"amount"`
+}
+
+type AccountDebited struct {
+	BaseEffect
+	AssetType   string `json:"asset_type"`
+	AssetCode   string `json:"asset_code,omitempty"`
+	AssetIssuer string `json:"asset_issuer,omitempty"`
+	Amount      string `json:"amount"`
+}
+
+type AccountThresholdsUpdated struct {
+	BaseEffect
+	LowThreshold  int32 `json:"low_threshold"`
+	MedThreshold  int32 `json:"med_threshold"`
+	HighThreshold int32 `json:"high_threshold"`
+}
+
+type AccountHomeDomainUpdated struct {
+	BaseEffect
+	HomeDomain string `json:"home_domain"`
+}
+
+// AccountFlagsUpdated is the response for an account_flags_updated effect.
+// Flags that were not changed are nil.
+type AccountFlagsUpdated struct {
+	BaseEffect
+	AuthRequired  *bool `json:"auth_required_flag,omitempty"`
+	AuthRevocable *bool `json:"auth_revokable_flag,omitempty"`
+}
+
+type SignerCreated struct {
+	BaseEffect
+	Weight    int32  `json:"weight"`
+	PublicKey string `json:"public_key"`
+}
+
+type SignerRemoved struct {
+	BaseEffect
+	Weight    int32  `json:"weight"`
+	PublicKey string `json:"public_key"`
+}
+
+type SignerUpdated struct {
+	BaseEffect
+	Weight    int32  `json:"weight"`
+	PublicKey string `json:"public_key"`
+}
+
+type TrustlineCreated struct {
+	BaseEffect
+	AssetType   string `json:"asset_type"`
+	AssetCode   string `json:"asset_code,omitempty"`
+	AssetIssuer string `json:"asset_issuer,omitempty"`
+	Limit       string `json:"limit"`
+}
+
+type TrustlineRemoved struct {
+	BaseEffect
+	AssetType   string `json:"asset_type"`
+	AssetCode   string `json:"asset_code,omitempty"`
+	AssetIssuer string `json:"asset_issuer,omitempty"`
+	Limit       string `json:"limit"`
+}
+
+type TrustlineUpdated struct {
+	BaseEffect
+	AssetType   string `json:"asset_type"`
+	AssetCode   string `json:"asset_code,omitempty"`
+	AssetIssuer string `json:"asset_issuer,omitempty"`
+	Limit       string `json:"limit"`
+}
+
+type TrustlineAuthorized struct {
+	BaseEffect
+	Trustor   string `json:"trustor"`
+	AssetType string `json:"asset_type"`
+	AssetCode string `json:"asset_code"`
+}
+
+type TrustlineDeauthorized struct {
+	BaseEffect
+	Trustor   string `json:"trustor"`
+	AssetType string `json:"asset_type"`
+	AssetCode string `json:"asset_code"`
+}
+
+// TradeEffect is the response for a trade effect.  It is named so as not to
+// collide with Trade, the response of the trades endpoint.
+type TradeEffect struct {
+	BaseEffect
+	Seller            string `json:"seller"`
+	OfferID           int64  `json:"offer_id"`
+	SoldAmount        string `json:"sold_amount"`
+	SoldAssetType     string `json:"sold_asset_type"`
+	SoldAssetCode     string `json:"sold_asset_code,omitempty"`
+	SoldAssetIssuer   string `json:"sold_asset_issuer,omitempty"`
+	BoughtAmount      string `json:"bought_amount"`
+	BoughtAssetType   string `json:"bought_asset_type"`
+	BoughtAssetCode   string `json:"bought_asset_code,omitempty"`
+	BoughtAssetIssuer string `json:"bought_asset_issuer,omitempty"`
+}
+
+type DataCreated struct {
+	BaseEffect
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type DataRemoved struct {
+	BaseEffect
+	Name string `json:"name"`
+}
+
+type DataUpdated struct {
+	BaseEffect
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// UnmarshalEffect decodes the JSON form of an effect into the struct for its
+// type.
+func UnmarshalEffect(data []byte) (Effect, error) {
+	var base BaseEffect
+	err := json.Unmarshal(data, &base)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode effect")
+	}
+
+	var effect Effect
+	switch base.Type {
+	case "account_created":
+		effect = &AccountCreated{}
+	case "account_removed":
+		effect = &AccountRemoved{}
+	case "account_credited":
+		effect = &AccountCredited{}
+	case "account_debited":
+		effect = &AccountDebited{}
+	case "account_thresholds_updated":
+		effect = &AccountThresholdsUpdated{}
+	case "account_home_domain_updated":
+		effect = &AccountHomeDomainUpdated{}
+	case "account_flags_updated":
+		effect = &AccountFlagsUpdated{}
+	case "signer_created":
+		effect = &SignerCreated{}
+	case "signer_removed":
+		effect = &SignerRemoved{}
+	case "signer_updated":
+		effect = &SignerUpdated{}
+	case "trustline_created":
+		effect = &TrustlineCreated{}
+	case "trustline_removed":
+		effect = &TrustlineRemoved{}
+	case "trustline_updated":
+		effect = &TrustlineUpdated{}
+	case "trustline_authorized":
+		effect = &TrustlineAuthorized{}
+	case "trustline_deauthorized":
+		effect = &TrustlineDeauthorized{}
+	case "trade":
+		effect = &TradeEffect{}
+	case "data_created":
+		effect = &DataCreated{}
+	case "data_removed":
+		effect = &DataRemoved{}
+	case "data_updated":
+		effect = &DataUpdated{}
+	default:
+		return &base, nil
+	}
+
+	err = json.Unmarshal(data, effect)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode %s effect", base.Type)
+	}
+
+	return effect, nil
+}
+
+type EffectsPage struct {
+	Links struct {
+		Self Link `json:"self"`
+		Next Link `json:"next"`
+		Prev Link `json:"prev"`
+	} `json:"_links"`
+	Embedded struct {
+		Records []Effect `json:"records"`
+	} `json:"_embedded"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, decoding each record into the
+// struct for its type.
+func (page *EffectsPage) UnmarshalJSON(data []byte) error {
+	var raw Page
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+
+	page.Links = raw.Links
+	page.Embedded.Records = make([]Effect, len(raw.Embedded.Records))
+	for i, record := range raw.Embedded.Records {
+		page.Embedded.Records[i], err = UnmarshalEffect(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -2,15 +2,84 @@ package horizon
 
 import (
 	"encoding/json"
+	"strings"
+	"time"
 
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/xdr"
 )
 
+// ProblemType identifies the kind of a problem horizon returned, without the
+// url prefix horizon gives it.
+type ProblemType string
+
+// The types of problem horizon returns.
+const (
+	ProblemBadRequest           ProblemType = "bad_request"
+	ProblemBeforeHistory        ProblemType = "before_history"
+	ProblemForbidden            ProblemType = "forbidden"
+	ProblemNotAcceptable        ProblemType = "not_acceptable"
+	ProblemNotFound             ProblemType = "not_found"
+	ProblemNotImplemented       ProblemType = "not_implemented"
+	ProblemRateLimitExceeded    ProblemType = "rate_limit_exceeded"
+	ProblemServerError          ProblemType = "server_error"
+	ProblemStaleHistory         ProblemType = "stale_history"
+	ProblemTimeout              ProblemType = "timeout"
+	ProblemTransactionFailed    ProblemType = "transaction_failed"
+	ProblemTransactionMalformed ProblemType = "transaction_malformed"
+)
+
 func (herr *Error) Error() string {
 	return `Horizon error: "` + herr.Problem.Title + `". Check horizon.Error.Problem for more information.`
 }
 
+// Type returns the type of the problem, such as ProblemNotFound.
+func (herr *Error) Type() ProblemType {
+	return ProblemType(strings.TrimPrefix(herr.Problem.Type, "https://stellar.org/horizon-errors/"))
+}
+
+// IsProblem returns whether `err` is a horizon error with a problem of type
+// `typ`.
+func IsProblem(err error, typ ProblemType) bool {
+	herr, ok := errors.Cause(err).(*Error)
+	return ok && herr.Type() == typ
+}
+
+// IsNotFound returns whether `err` is a horizon not_found problem.
+func IsNotFound(err error) bool {
+	return IsProblem(err, ProblemNotFound)
+}
+
+// IsRateLimitExceeded returns whether `err` is a horizon rate_limit_exceeded
+// problem.
+func IsRateLimitExceeded(err error) bool {
+	return IsProblem(err, ProblemRateLimitExceeded)
+}
+
+// IsBadRequest returns whether `err` is a horizon bad_request problem.
+func IsBadRequest(err error) bool {
+	return IsProblem(err, ProblemBadRequest)
+}
+
+// IsTimeout returns whether `err` is a horizon timeout problem.
+func IsTimeout(err error) bool {
+	return IsProblem(err, ProblemTimeout)
+}
+
+// IsTransactionFailed returns whether `err` is a horizon transaction_failed
+// problem.
+func IsTransactionFailed(err error) bool {
+	return IsProblem(err, ProblemTransactionFailed)
+}
+
+// RateLimit returns the rate limit horizon reported along with the problem.
+func (herr *Error) RateLimit() (RateLimit, bool) {
+	if herr.Response == nil {
+		return RateLimit{}, false
+	}
+	return parseRateLimit(herr.Response.Header, time.Now())
+}
+
 // Envelope extracts the transaction envelope that triggered this error from the
 // extra fields.
 func (herr *Error) Envelope() (*xdr.TransactionEnvelope, error) {
@@ -37,7 +106,7 @@ func (herr *Error) Envelope() (*xdr.TransactionEnvelope, error) {
 
 // ResultCodes extracts a result code summary from the error, if possible.
 func (herr *Error) ResultCodes() (*TransactionResultCodes, error) {
-	if herr.Problem.Type != "transaction_failed" {
+	if herr.Type() != ProblemTransactionFailed {
 		return nil, ErrTransactionNotFailed
 	}
 
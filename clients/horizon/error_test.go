@@ -2,8 +2,11 @@ package horizon
 
 import (
 	"encoding/json"
+	"net/http"
 	"testing"
+	"time"
 
+	"github.com/stellar/go/support/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -27,6 +30,11 @@ func TestError_ResultCodes(t *testing.T) {
 		}
 	}
 
+	// happy path: the problem type in the url form horizon sends
+	herr.Problem.Type = "https://stellar.org/horizon-errors/transaction_failed"
+	_, err = herr.ResultCodes()
+	assert.NoError(t, err)
+
 	// sad path: !transaction_failed
 	herr.Problem.Type = "transaction_success"
 	herr.Problem.Extras = make(map[string]json.RawMessage)
@@ -71,3 +79,45 @@ func TestError_Envelope(t *testing.T) {
 		assert.Contains(t, err.Error(), "xdr decode")
 	}
 }
+
+func TestError_Type(t *testing.T) {
+	herr := &Error{Problem: Problem{Type: "https://stellar.org/horizon-errors/not_found"}}
+	assert.Equal(t, ProblemNotFound, herr.Type())
+
+	herr.Problem.Type = "rate_limit_exceeded"
+	assert.Equal(t, ProblemRateLimitExceeded, herr.Type())
+}
+
+func TestIsProblem(t *testing.T) {
+	notFound := &Error{Problem: Problem{Type: "https://stellar.org/horizon-errors/not_found"}}
+	timeout := &Error{Problem: Problem{Type: "https://stellar.org/horizon-errors/timeout"}}
+
+	assert.True(t, IsNotFound(notFound))
+	assert.True(t, IsNotFound(errors.Wrap(notFound, "load account failed")))
+	assert.False(t, IsNotFound(timeout))
+	assert.True(t, IsTimeout(timeout))
+	assert.False(t, IsRateLimitExceeded(timeout))
+	assert.False(t, IsBadRequest(timeout))
+	assert.False(t, IsTransactionFailed(timeout))
+	assert.True(t, IsProblem(timeout, ProblemTimeout))
+
+	assert.False(t, IsNotFound(errors.New("not_found")))
+	assert.False(t, IsNotFound(nil))
+}
+
+func TestError_RateLimit(t *testing.T) {
+	herr := &Error{Response: &http.Response{Header: http.Header{}}}
+	_, ok := herr.RateLimit()
+	assert.False(t, ok)
+
+	herr.Response.Header.Set("X-RateLimit-Limit", "3600")
+	herr.Response.Header.Set("X-RateLimit-Remaining", "0")
+	herr.Response.Header.Set("X-RateLimit-Reset", "30")
+
+	limit, ok := herr.RateLimit()
+	if assert.True(t, ok) {
+		assert.Equal(t, 3600, limit.Limit)
+		assert.Equal(t, 0, limit.Remaining)
+		assert.WithinDuration(t, time.Now().Add(30*time.Second), limit.Reset, time.Second)
+	}
+}
@@ -0,0 +1,335 @@
+package horizon
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stellar/go/build"
+	"github.com/stellar/go/support/errors"
+	"golang.org/x/net/context"
+)
+
+// DefaultMaxLedgerAge is the age of its latest ledger beyond which a
+// FailoverClient considers a horizon server stale.
+const DefaultMaxLedgerAge = time.Minute
+
+// FailoverClient is a Client that spreads its requests across several horizon
+// servers.  Reads go to the first healthy server and fail over to the next one
+// when a request ends in a transport error.  A server is healthy unless its
+// last request failed or its last health check, run by CheckHealth or
+// Monitor, found it unreachable or found its latest ledger older than
+// MaxLedgerAge.  If no server is healthy, each is tried in turn.
+//
+// Submissions are sent to a single server and are not failed over, because a
+// transaction whose submission failed may still have been received; use
+// SubmitTransactionWithRetry to resubmit safely.
+//
+// Each connection of a stream stays on the server it was made to.  When it is
+// lost the stream reconnects, possibly to another server, and resumes from its
+// cursor.
+type FailoverClient struct {
+	*Client
+
+	// MaxLedgerAge is the age of its latest ledger beyond which a server is
+	// considered stale.  Defaults to DefaultMaxLedgerAge.
+	MaxLedgerAge time.Duration
+
+	http      HTTP
+	lock      sync.Mutex
+	endpoints []*endpoint
+}
+
+// EndpointStatus is the health of one of the servers of a FailoverClient.
+type EndpointStatus struct {
+	URL     string
+	Healthy bool
+
+	// LatestLedger is the sequence of the latest ledger the server had at its
+	// last health check, and LedgerAge how long before the check it closed.
+	LatestLedger int32
+	LedgerAge    time.Duration
+
+	// CheckedAt is when the server was last checked, or the zero time if it
+	// has not been.
+	CheckedAt time.Time
+
+	// Err is the error of the last failed request or health check of the
+	// server, if the server is unhealthy because of one.
+	Err error
+}
+
+// endpoint is the state of one of the servers of a FailoverClient.
+type endpoint struct {
+	base   string
+	status EndpointStatus
+	failed error
+}
+
+// NewFailoverClient returns a client for the horizon servers at `urls`, listed
+// in order of preference, that makes its requests with `httpClient`.
+func NewFailoverClient(urls []string, httpClient HTTP) (*FailoverClient, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("no horizon urls provided")
+	}
+
+	fc := &FailoverClient{http: httpClient}
+	for _, u := range urls {
+		_, err := url.Parse(u)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid horizon url %s", u)
+		}
+
+		base := strings.TrimSuffix(u, "/")
+		fc.endpoints = append(fc.endpoints, &endpoint{
+			base:   base,
+			status: EndpointStatus{URL: base, Healthy: true},
+		})
+	}
+
+	fc.Client = &Client{
+		URL:  fc.endpoints[0].base,
+		HTTP: &failoverHTTP{fc},
+	}
+	return fc, nil
+}
+
+// Endpoints returns the health of each server, in order of preference.
+func (fc *FailoverClient) Endpoints() []EndpointStatus {
+	fc.lock.Lock()
+	defer fc.lock.Unlock()
+
+	statuses := make([]EndpointStatus, len(fc.endpoints))
+	for i, e := range fc.endpoints {
+		statuses[i] = e.status
+	}
+	return statuses
+}
+
+// CheckHealth checks the health of every server by loading its latest ledger,
+// and returns the health of each.
+func (fc *FailoverClient) CheckHealth(ctx context.Context) []EndpointStatus {
+	var wg sync.WaitGroup
+	for _, e := range fc.endpoints {
+		wg.Add(1)
+		go func(e *endpoint) {
+			defer wg.Done()
+			fc.check(ctx, e)
+		}(e)
+	}
+	wg.Wait()
+
+	return fc.Endpoints()
+}
+
+// Monitor checks the health of every server each `interval` until ctx is
+// done.
+func (fc *FailoverClient) Monitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		fc.CheckHealth(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check checks the health of `e`.
+func (fc *FailoverClient) check(ctx context.Context, e *endpoint) {
+	direct := &Client{URL: e.base, HTTP: fc.http}
+	page, err := direct.LoadLedgersContext(ctx, Order(OrderDesc), Limit(1))
+	if err == nil && len(page.Embedded.Records) == 0 {
+		err = errors.New("no ledgers")
+	}
+
+	now := time.Now()
+
+	fc.lock.Lock()
+	defer fc.lock.Unlock()
+
+	e.status.CheckedAt = now
+	e.status.Err = err
+	if err != nil {
+		e.status.Healthy = false
+		return
+	}
+
+	latest := page.Embedded.Records[0]
+	e.failed = nil
+	e.status.LatestLedger = latest.Sequence
+	e.status.LedgerAge = now.Sub(latest.ClosedAt)
+	e.status.Healthy = e.status.LedgerAge <= fc.maxLedgerAge()
+}
+
+// candidates returns the servers to send a request to, healthy ones first.
+func (fc *FailoverClient) candidates() []*endpoint {
+	fc.lock.Lock()
+	defer fc.lock.Unlock()
+
+	var healthy, unhealthy []*endpoint
+	for _, e := range fc.endpoints {
+		if e.status.Healthy {
+			healthy = append(healthy, e)
+		} else {
+			unhealthy = append(unhealthy, e)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// report records the outcome of a request sent to `e`.
+func (fc *FailoverClient) report(e *endpoint, err error) {
+	fc.lock.Lock()
+	defer fc.lock.Unlock()
+
+	if err != nil {
+		e.failed = err
+		e.status.Healthy = false
+		e.status.Err = err
+		return
+	}
+
+	if e.failed != nil {
+		e.failed = nil
+		e.status.Err = nil
+		e.status.Healthy = e.status.CheckedAt.IsZero() || e.status.LedgerAge <= fc.maxLedgerAge()
+	}
+}
+
+// maxLedgerAge returns the age beyond which a server is considered stale.
+func (fc *FailoverClient) maxLedgerAge() time.Duration {
+	if fc.MaxLedgerAge <= 0 {
+		return DefaultMaxLedgerAge
+	}
+	return fc.MaxLedgerAge
+}
+
+// failoverHTTP is the HTTP of a FailoverClient, which sends each request to
+// one of its servers.
+type failoverHTTP struct {
+	fc *FailoverClient
+}
+
+// Do implements HTTP.  Requests for urls of any of the client's servers,
+// including links found in responses, are sent to the server chosen for them.
+// Other requests are sent unchanged.
+func (h *failoverHTTP) Do(req *http.Request) (*http.Response, error) {
+	candidates := h.fc.candidates()
+
+	path, ok := h.path(req.URL.String())
+	if !ok {
+		return h.fc.http.Do(req)
+	}
+	if req.Method != "GET" {
+		candidates = candidates[:1]
+	}
+
+	var err error
+	for _, e := range candidates {
+		var resp *http.Response
+		resp, err = h.send(req, e, path)
+		if err == nil {
+			return resp, nil
+		}
+
+		if req.Context().Err() != nil {
+			return nil, err
+		}
+	}
+
+	return nil, err
+}
+
+// Get implements HTTP.
+func (h *failoverHTTP) Get(url string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return h.Do(req)
+}
+
+// PostForm implements HTTP.
+func (h *failoverHTTP) PostForm(url string, data url.Values) (*http.Response, error) {
+	req, err := http.NewRequest("POST", url, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return h.Do(req)
+}
+
+// path returns the part of `u` after the url of the server it names, and
+// false if it names none of the client's servers.
+func (h *failoverHTTP) path(u string) (string, bool) {
+	for _, e := range h.fc.endpoints {
+		if !strings.HasPrefix(u, e.base) {
+			continue
+		}
+
+		path := u[len(e.base):]
+		if path == "" || path[0] == '/' || path[0] == '?' {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// send sends a copy of `req` for `path` to the server `e`.
+func (h *failoverHTTP) send(req *http.Request, e *endpoint, path string) (*http.Response, error) {
+	u, err := url.Parse(e.base + path)
+	if err != nil {
+		return nil, err
+	}
+
+	out := new(http.Request)
+	*out = *req
+	out.URL = u
+	out.Host = ""
+	if req.GetBody != nil {
+		out.Body, err = req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := h.fc.http.Do(out)
+	if err != nil {
+		h.fc.report(e, err)
+		return nil, err
+	}
+	h.fc.report(e, nil)
+
+	resp.Body = &failoverBody{ReadCloser: resp.Body, fc: h.fc, e: e, ctx: req.Context()}
+	return resp, nil
+}
+
+// failoverBody is the body of a response from one of the servers of a
+// FailoverClient, which marks the server as failed if reading from it fails,
+// so that a stream that loses its connection reconnects elsewhere.
+type failoverBody struct {
+	io.ReadCloser
+	fc  *FailoverClient
+	e   *endpoint
+	ctx context.Context
+}
+
+func (b *failoverBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF && b.ctx.Err() == nil {
+		b.fc.report(b.e, err)
+	}
+	return n, err
+}
+
+// ensure that the failover client can be used in place of a client
+var _ build.SequenceProvider = &FailoverClient{}
+var _ ClientInterface = &FailoverClient{}
@@ -0,0 +1,157 @@
+package horizon
+
+import (
+	"fmt"
+	"net/http"
+	stdtest "net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+// ledgerServer is a stand-in for horizon that serves a single ledger, closed
+// at `closedAt`, and records the url of each request it receives.
+type ledgerServer struct {
+	*stdtest.Server
+
+	lock     sync.Mutex
+	requests []string
+}
+
+func newLedgerServer(sequence int32, closedAt time.Time) *ledgerServer {
+	s := &ledgerServer{}
+	s.Server = stdtest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.lock.Lock()
+		s.requests = append(s.requests, r.URL.String())
+		s.lock.Unlock()
+
+		ledger := fmt.Sprintf(`{"sequence": %d, "closed_at": "%s"}`, sequence, closedAt.UTC().Format(time.RFC3339))
+		if r.URL.Path == "/ledgers" {
+			fmt.Fprintf(w, `{"_embedded": {"records": [%s]}}`, ledger)
+			return
+		}
+		fmt.Fprint(w, ledger)
+	}))
+	return s
+}
+
+// Requests returns the urls requested so far.
+func (s *ledgerServer) Requests() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// deadURL returns the url of a server that is no longer running.
+func deadURL() string {
+	server := stdtest.NewServer(http.NotFoundHandler())
+	server.Close()
+	return server.URL
+}
+
+func TestFailoverClient_TransportError(t *testing.T) {
+	live := newLedgerServer(7, time.Now())
+	defer live.Close()
+	dead := deadURL()
+
+	client, err := NewFailoverClient([]string{dead, live.URL + "/"}, http.DefaultClient)
+	require.NoError(t, err)
+
+	ledger, err := client.LoadLedger(7)
+	require.NoError(t, err)
+	assert.Equal(t, int32(7), ledger.Sequence)
+
+	statuses := client.Endpoints()
+	assert.False(t, statuses[0].Healthy)
+	assert.Error(t, statuses[0].Err)
+	assert.True(t, statuses[1].Healthy)
+	assert.Equal(t, live.URL, statuses[1].URL)
+
+	// urls of the dead server, such as links from its responses, are routed
+	// to the live one
+	_, err = client.LoadLedgers(At(dead + "/ledgers?cursor=5"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/ledgers/7", "/ledgers?cursor=5"}, live.Requests())
+
+	// with no server reachable, requests fail
+	client, err = NewFailoverClient([]string{dead}, http.DefaultClient)
+	require.NoError(t, err)
+	_, err = client.LoadLedger(7)
+	assert.Error(t, err)
+
+	_, err = NewFailoverClient(nil, http.DefaultClient)
+	assert.Error(t, err)
+}
+
+func TestFailoverClient_CheckHealth(t *testing.T) {
+	stale := newLedgerServer(5, time.Now().Add(-10*time.Minute))
+	defer stale.Close()
+	fresh := newLedgerServer(7, time.Now())
+	defer fresh.Close()
+
+	client, err := NewFailoverClient([]string{stale.URL, fresh.URL}, http.DefaultClient)
+	require.NoError(t, err)
+
+	statuses := client.CheckHealth(context.Background())
+	require.Len(t, statuses, 2)
+	assert.False(t, statuses[0].Healthy)
+	assert.Equal(t, int32(5), statuses[0].LatestLedger)
+	assert.True(t, statuses[0].LedgerAge >= 10*time.Minute)
+	assert.True(t, statuses[1].Healthy)
+	assert.Equal(t, int32(7), statuses[1].LatestLedger)
+	assert.False(t, statuses[1].CheckedAt.IsZero())
+
+	_, err = client.LoadLedger(7)
+	require.NoError(t, err)
+	assert.Len(t, stale.Requests(), 1)
+	assert.Len(t, fresh.Requests(), 2)
+
+	// a more lenient client tolerates the stale server
+	client.MaxLedgerAge = time.Hour
+	statuses = client.CheckHealth(context.Background())
+	assert.True(t, statuses[0].Healthy)
+}
+
+func TestFailoverClient_Stream(t *testing.T) {
+	// the first server sends a ledger and then drops the connection
+	first := stdtest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		event := "retry: 10\n" + ledgerEvent("id-1", 1)
+		fmt.Fprint(buf, "HTTP/1.1 200 OK\r\nContent-Type: text/event-stream\r\nTransfer-Encoding: chunked\r\n\r\n")
+		fmt.Fprintf(buf, "%x\r\n%s\r\n", len(event), event)
+		buf.Flush()
+	}))
+	defer first.Close()
+
+	second := newSSEServer(stubResponse{Body: ledgerEvent("", 2)})
+	defer second.Close()
+
+	client, err := NewFailoverClient([]string{first.URL, second.URL}, http.DefaultClient)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var sequences []int32
+	err = client.StreamLedgers(ctx, nil, func(l Ledger) error {
+		sequences = append(sequences, l.Sequence)
+		if len(sequences) == 2 {
+			cancel()
+		}
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []int32{1, 2}, sequences)
+	assert.Equal(t, []string{"id-1"}, second.Cursors())
+	assert.False(t, client.Endpoints()[0].Healthy)
+}
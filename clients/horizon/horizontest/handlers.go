@@ -0,0 +1,348 @@
+package horizontest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/stellar/go/clients/horizon"
+)
+
+const (
+	// defaultLimit and maxLimit are horizon's default and largest page sizes.
+	defaultLimit = 10
+	maxLimit     = 200
+
+	problemPrefix = "https://stellar.org/horizon-errors/"
+)
+
+// page is the response for a page of a collection.
+type page struct {
+	Links struct {
+		Self horizon.Link `json:"self"`
+		Next horizon.Link `json:"next"`
+		Prev horizon.Link `json:"prev"`
+	} `json:"_links"`
+	Embedded struct {
+		Records []json.RawMessage `json:"records"`
+	} `json:"_embedded"`
+}
+
+// pageQuery is the position and size of a requested page.
+type pageQuery struct {
+	cursor int64
+	desc   bool
+	limit  int
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	parts := strings.Split(path, "/")
+
+	if r.Method == "POST" {
+		if path == "transactions" {
+			s.submit(w, r)
+			return
+		}
+		writeProblem(w, horizon.ProblemNotFound, http.StatusNotFound, "", nil)
+		return
+	}
+
+	switch {
+	case path == "":
+		s.root(w)
+	case path == "order_book":
+		s.orderBook(w, r)
+	case len(parts) == 1 && isCollection(parts[0]):
+		s.collection(w, r, parts[0], "")
+	case len(parts) == 2 && parts[0] == "accounts":
+		s.account(w, parts[1])
+	case len(parts) == 3 && parts[0] == "accounts" && parts[2] == "offers":
+		s.offers(w, r, parts[1])
+	case len(parts) == 3 && parts[0] == "accounts" && isCollection(parts[2]) && parts[2] != "ledgers":
+		s.collection(w, r, parts[2], parts[1])
+	case len(parts) == 2 && parts[0] == "ledgers":
+		s.ledger(w, parts[1])
+	case len(parts) == 2 && parts[0] == "transactions":
+		s.transaction(w, parts[1])
+	default:
+		writeProblem(w, horizon.ProblemNotFound, http.StatusNotFound, "", nil)
+	}
+}
+
+// isCollection returns whether `name` is one of the collections the server
+// keeps records for.
+func isCollection(name string) bool {
+	return name == "ledgers" || name == "transactions" || name == "payments"
+}
+
+func (s *Server) root(w http.ResponseWriter) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	root := horizon.Root{
+		HorizonVersion:       "horizontest",
+		StellarCoreVersion:   "horizontest",
+		HorizonSequence:      s.latestLedger(),
+		HistoryElderSequence: 1,
+		CoreSequence:         s.latestLedger(),
+		NetworkPassphrase:    s.sim.NetworkPassphrase,
+		ProtocolVersion:      9,
+	}
+	root.Links.Account.Href = s.URL + "/accounts/{account_id}"
+	root.Links.Account.Templated = true
+	root.Links.AccountTransactions.Href = s.URL + "/accounts/{account_id}/transactions{?cursor,limit,order}"
+	root.Links.AccountTransactions.Templated = true
+	root.Links.OrderBook.Href = s.URL + "/order_book{?selling_asset_type,selling_asset_code,selling_asset_issuer,buying_asset_type,buying_asset_code,buying_asset_issuer,limit}"
+	root.Links.OrderBook.Templated = true
+	root.Links.Self.Href = s.URL + "/"
+	root.Links.Transaction.Href = s.URL + "/transactions/{hash}"
+	root.Links.Transaction.Templated = true
+	root.Links.Transactions.Href = s.URL + "/transactions{?cursor,limit,order}"
+	root.Links.Transactions.Templated = true
+
+	writeJSON(w, http.StatusOK, root)
+}
+
+func (s *Server) ledger(w http.ResponseWriter, sequence string) {
+	seq, err := strconv.ParseInt(sequence, 10, 32)
+	if err != nil {
+		writeProblem(w, horizon.ProblemNotFound, http.StatusNotFound, "", nil)
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, r := range s.records["ledgers"] {
+		if r.pt == toid(int32(seq), 0, 0) {
+			writeRaw(w, http.StatusOK, r.data)
+			return
+		}
+	}
+	writeProblem(w, horizon.ProblemNotFound, http.StatusNotFound, "", nil)
+}
+
+func (s *Server) transaction(w http.ResponseWriter, hash string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	data, ok := s.byHash[hash]
+	if !ok {
+		writeProblem(w, horizon.ProblemNotFound, http.StatusNotFound, "", nil)
+		return
+	}
+	writeRaw(w, http.StatusOK, data)
+}
+
+// collection serves the records of the collection `name` involving
+// `account`, or all of them if it is empty, as a page or a stream.
+func (s *Server) collection(w http.ResponseWriter, r *http.Request, name, account string) {
+	q, err := s.pageQuery(r)
+	if err != nil {
+		writeProblem(w, horizon.ProblemBadRequest, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if r.Header.Get("Accept") == "text/event-stream" {
+		s.stream(w, r, name, account, q.cursor)
+		return
+	}
+
+	s.lock.Lock()
+	records := selectRecords(s.records[name], account, q)
+	s.lock.Unlock()
+
+	writePage(w, r, records, q)
+}
+
+// stream sends the records of the collection `name` involving `account` that
+// come after `cursor` as server-sent events, then sends new records as they
+// are created until the request or the server ends.
+func (s *Server) stream(w http.ResponseWriter, r *http.Request, name, account string, cursor int64) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeProblem(w, horizon.ProblemNotAcceptable, http.StatusNotAcceptable, "streaming unsupported", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 1000\nevent: open\ndata: \"hello\"\n\n")
+	flusher.Flush()
+
+	for {
+		s.lock.Lock()
+		records := selectRecords(s.records[name], account, pageQuery{cursor: cursor})
+		changed := s.changed
+		s.lock.Unlock()
+
+		for _, record := range records {
+			fmt.Fprintf(w, "id: %d\ndata: %s\n\n", record.pt, record.data)
+			cursor = record.pt
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			return
+		case <-changed:
+		}
+	}
+}
+
+// pageQuery parses the cursor, order and limit of `r`.  A stream resumes from
+// the Last-Event-ID header if no cursor is given.
+func (s *Server) pageQuery(r *http.Request) (pageQuery, error) {
+	query := r.URL.Query()
+	q := pageQuery{limit: defaultLimit}
+
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		q.desc = true
+		q.cursor = math.MaxInt64
+	default:
+		return q, fmt.Errorf("invalid order: %s", query.Get("order"))
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxLimit {
+			return q, fmt.Errorf("invalid limit: %s", limit)
+		}
+		q.limit = n
+	}
+
+	cursor := query.Get("cursor")
+	if cursor == "" {
+		cursor = r.Header.Get("Last-Event-ID")
+	}
+
+	switch cursor {
+	case "":
+	case "now":
+		s.lock.Lock()
+		q.cursor = toid(s.latestLedger()+1, 0, 0) - 1
+		s.lock.Unlock()
+	default:
+		pt, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || pt < 0 {
+			return q, fmt.Errorf("invalid cursor: %s", cursor)
+		}
+		q.cursor = pt
+	}
+
+	return q, nil
+}
+
+// selectRecords returns the records of `records`, which are in ascending
+// order, that involve `account`, if it is not empty, and come after the
+// cursor of `q` in its order.  At most the limit of `q` are returned, unless
+// it is 0.
+func selectRecords(records []record, account string, q pageQuery) []record {
+	var selected []record
+	for i := range records {
+		r := records[i]
+		if q.desc {
+			r = records[len(records)-1-i]
+		}
+
+		if (!q.desc && r.pt <= q.cursor) || (q.desc && r.pt >= q.cursor) {
+			continue
+		}
+		if account != "" && !involves(r, account) {
+			continue
+		}
+
+		selected = append(selected, r)
+		if q.limit > 0 && len(selected) == q.limit {
+			break
+		}
+	}
+	return selected
+}
+
+func involves(r record, account string) bool {
+	for _, a := range r.accounts {
+		if a == account {
+			return true
+		}
+	}
+	return false
+}
+
+// writePage writes `records`, selected by `q`, as a page of the collection
+// requested by `r`.
+func writePage(w http.ResponseWriter, r *http.Request, records []record, q pageQuery) {
+	var p page
+	p.Embedded.Records = []json.RawMessage{}
+	for _, record := range records {
+		p.Embedded.Records = append(p.Embedded.Records, record.data)
+	}
+
+	order, reverse := "asc", "desc"
+	if q.desc {
+		order, reverse = "desc", "asc"
+	}
+
+	next, prev := q.cursor, q.cursor
+	if len(records) > 0 {
+		prev = records[0].pt
+		next = records[len(records)-1].pt
+	}
+
+	p.Links.Self.Href = pageURL(r, q.cursor, order, q.limit)
+	p.Links.Next.Href = pageURL(r, next, order, q.limit)
+	p.Links.Prev.Href = pageURL(r, prev, reverse, q.limit)
+
+	writeJSON(w, http.StatusOK, p)
+}
+
+// pageURL returns the url of the page of the collection requested by `r`
+// after `cursor` in `order`.
+func pageURL(r *http.Request, cursor int64, order string, limit int) string {
+	query := url.Values{}
+	if cursor != 0 && cursor != math.MaxInt64 {
+		query.Set("cursor", strconv.FormatInt(cursor, 10))
+	}
+	query.Set("order", order)
+	query.Set("limit", strconv.Itoa(limit))
+
+	return fmt.Sprintf("http://%s%s?%s", r.Host, r.URL.Path, query.Encode())
+}
+
+// writeProblem writes a horizon problem of type `typ`.
+func writeProblem(w http.ResponseWriter, typ horizon.ProblemType, status int, detail string, extras map[string]interface{}) {
+	problem := horizon.Problem{
+		Type:   problemPrefix + string(typ),
+		Title:  strings.Title(strings.Replace(string(typ), "_", " ", -1)),
+		Status: status,
+		Detail: detail,
+	}
+
+	if len(extras) > 0 {
+		problem.Extras = map[string]json.RawMessage{}
+		for key, value := range extras {
+			problem.Extras[key] = mustMarshal(value)
+		}
+	}
+
+	writeJSON(w, status, problem)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	writeRaw(w, status, mustMarshal(v))
+}
+
+func writeRaw(w http.ResponseWriter, status int, data []byte) {
+	w.Header().Set("Content-Type", "application/hal+json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(data)
+}
@@ -0,0 +1,193 @@
+// Package horizontest provides a fake horizon server for integration tests.
+//
+// A Server is an in-process HTTP server that speaks enough of horizon's API for
+// code built on horizon.Client to be exercised end-to-end without a network.
+// Its state is held in memory by an exp/simulator Simulator: it serves
+// accounts, offers and order books from that state, and applies the
+// transactions submitted to it, each in a ledger of its own.  The ledgers,
+// transactions and payments it produces are served as pages or, to requests
+// that accept text/event-stream, as SSE streams that stay open and deliver
+// new records as they are created.
+//
+// Transaction processing is that of the simulator, so only the operations it
+// implements can be submitted, and offers are never matched.
+package horizontest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	stdtest "net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/clients/horizon"
+	"github.com/stellar/go/exp/simulator"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// Server is a fake horizon server.  It is safe for concurrent use.
+type Server struct {
+	*stdtest.Server
+
+	lock    sync.Mutex
+	sim     *simulator.Simulator
+	records map[string][]record
+	byHash  map[string][]byte
+	closed  bool
+	done    chan struct{}
+
+	// changed is closed, and replaced, whenever a ledger closes, waking the
+	// streams waiting for new records.
+	changed chan struct{}
+}
+
+// record is a ledger, transaction or payment produced by the server.
+type record struct {
+	// pt is the paging token of the record.
+	pt int64
+
+	// accounts are the accounts the record involves, by which it is found
+	// in the collections of an account.
+	accounts []string
+
+	// data is the JSON encoding of the record.
+	data []byte
+}
+
+// NewServer starts a fake horizon server for the network identified by
+// `passphrase`, whose ledger starts out holding `entries`.  Use
+// simulator.AccountEntry to create funded accounts.  The server must be
+// closed with Close when the test is done with it.
+func NewServer(passphrase string, entries ...xdr.LedgerEntry) (*Server, error) {
+	sim, err := simulator.New(passphrase, entries)
+	if err != nil {
+		return nil, errors.Wrap(err, "create simulator failed")
+	}
+
+	s := &Server{
+		sim:     sim,
+		records: map[string][]record{},
+		byHash:  map[string][]byte{},
+		done:    make(chan struct{}),
+		changed: make(chan struct{}),
+	}
+	s.Server = stdtest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	// the ledger holding the initial entries
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.closeLedger(time.Now(), nil)
+
+	return s, nil
+}
+
+// Client returns a horizon client that sends its requests to the server.
+func (s *Server) Client() *horizon.Client {
+	return &horizon.Client{URL: s.URL, HTTP: http.DefaultClient}
+}
+
+// CloseLedger closes a ledger with no transactions, as the network does when
+// none are submitted.
+func (s *Server) CloseLedger() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.closeLedger(time.Now(), nil)
+}
+
+// Close ends every open stream and shuts down the server.
+func (s *Server) Close() {
+	s.lock.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.done)
+	}
+	s.lock.Unlock()
+
+	s.Server.Close()
+}
+
+// latestLedger returns the sequence of the last ledger closed.
+func (s *Server) latestLedger() int32 {
+	ledgers := s.records["ledgers"]
+	if len(ledgers) == 0 {
+		return 0
+	}
+	return int32(ledgers[len(ledgers)-1].pt >> 32)
+}
+
+// closeLedger records a ledger closed at `closedAt` holding `tx`, if it is
+// not nil, and wakes the streams waiting for it.  The caller must hold the
+// lock.
+func (s *Server) closeLedger(closedAt time.Time, tx *transaction) {
+	sequence := s.latestLedger() + 1
+	closedAt = closedAt.UTC().Truncate(time.Second)
+
+	var prevHash string
+	if sequence > 1 {
+		prevHash = ledgerHash(sequence - 1)
+	}
+
+	ledger := horizon.Ledger{
+		ID:              ledgerHash(sequence),
+		PT:              strconv.FormatInt(toid(sequence, 0, 0), 10),
+		Hash:            ledgerHash(sequence),
+		PrevHash:        prevHash,
+		Sequence:        sequence,
+		ClosedAt:        closedAt,
+		TotalCoins:      "100000000000.0000000",
+		FeePool:         "0.0000000",
+		BaseFee:         int32(s.sim.BaseFee),
+		BaseReserve:     amount.String(s.sim.BaseReserve),
+		MaxTxSetSize:    50,
+		ProtocolVersion: 9,
+	}
+	self := fmt.Sprintf("%s/ledgers/%d", s.URL, sequence)
+	ledger.Links.Self.Href = self
+	ledger.Links.Transactions.Href = self + "/transactions"
+	ledger.Links.Operations.Href = self + "/operations"
+	ledger.Links.Payments.Href = self + "/payments"
+	ledger.Links.Effects.Href = self + "/effects"
+
+	if tx != nil {
+		ledger.TransactionCount = 1
+		ledger.OperationCount = int32(len(tx.env.Tx.Operations))
+		s.addTransaction(sequence, closedAt, tx)
+	}
+
+	s.add("ledgers", record{pt: toid(sequence, 0, 0), data: mustMarshal(ledger)})
+
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// add appends `r` to the collection `name`.  The caller must hold the lock.
+func (s *Server) add(name string, r record) {
+	s.records[name] = append(s.records[name], r)
+}
+
+// ledgerHash returns the made up hash of the ledger `sequence`.
+func ledgerHash(sequence int32) string {
+	hash := sha256.Sum256([]byte(strconv.Itoa(int(sequence))))
+	return hex.EncodeToString(hash[:])
+}
+
+// toid returns the id horizon gives to operation `op` of transaction `tx` in
+// ledger `sequence`, which it also uses as the paging token of ledgers and
+// transactions, with the indexes that do not apply left 0.
+func toid(sequence int32, tx, op int) int64 {
+	return int64(sequence)<<32 | int64(tx)<<12 | int64(op)
+}
+
+func mustMarshal(v interface{}) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
@@ -0,0 +1,313 @@
+package horizontest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/build"
+	"github.com/stellar/go/clients/horizon"
+	"github.com/stellar/go/exp/simulator"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+// newServer starts a server whose ledger holds a root account funded with
+// 1000 lumens and the `entries`.
+func newServer(t *testing.T, entries ...xdr.LedgerEntry) (*Server, *keypair.Full) {
+	root, err := keypair.Random()
+	require.NoError(t, err)
+
+	entry, err := simulator.AccountEntry(root.Address(), 10000000000)
+	require.NoError(t, err)
+
+	s, err := NewServer(network.TestNetworkPassphrase, append(entries, entry)...)
+	require.NoError(t, err)
+	return s, root
+}
+
+// submit submits a transaction from `source` made of `ops`, returning its
+// hash.
+func submit(t *testing.T, client *horizon.Client, source *keypair.Full, ops ...build.TransactionMutator) (string, error) {
+	muts := []build.TransactionMutator{
+		build.SourceAccount{AddressOrSeed: source.Address()},
+		build.AutoSequence{SequenceProvider: client},
+		build.TestNetwork,
+		build.MemoText{Value: "horizontest"},
+	}
+	tx := build.Transaction(append(muts, ops...)...)
+	require.NoError(t, tx.Err)
+
+	hash, err := tx.HashHex()
+	require.NoError(t, err)
+
+	txe := tx.Sign(source.Seed())
+	b64, err := txe.Base64()
+	require.NoError(t, err)
+
+	_, err = client.SubmitTransaction(b64)
+	return hash, err
+}
+
+func TestServer_Payments(t *testing.T) {
+	s, root := newServer(t)
+	defer s.Close()
+	client := s.Client()
+
+	dest, err := keypair.Random()
+	require.NoError(t, err)
+
+	account, err := client.LoadAccount(root.Address())
+	require.NoError(t, err)
+	assert.Equal(t, "1000.0000000", account.GetNativeBalance())
+	assert.Equal(t, "0", account.Sequence)
+
+	_, err = client.LoadAccount(dest.Address())
+	assert.True(t, horizon.IsNotFound(err), "expected not found, got %v", err)
+
+	// stream the payments of the new account as they are made
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	streamed := make(chan horizon.Operation, 2)
+	go client.StreamPayments(ctx, dest.Address(), nil, func(op horizon.Operation) error {
+		streamed <- op
+		return nil
+	})
+
+	hash, err := submit(t, client, root,
+		build.CreateAccount(
+			build.Destination{AddressOrSeed: dest.Address()},
+			build.NativeAmount{Amount: "10"},
+		),
+		build.Payment(
+			build.Destination{AddressOrSeed: dest.Address()},
+			build.NativeAmount{Amount: "5"},
+		),
+	)
+	require.NoError(t, err)
+
+	account, err = client.LoadAccount(dest.Address())
+	require.NoError(t, err)
+	assert.Equal(t, "15.0000000", account.GetNativeBalance())
+
+	account, err = client.LoadAccount(root.Address())
+	require.NoError(t, err)
+	assert.Equal(t, "984.9999800", account.GetNativeBalance())
+	assert.Equal(t, "1", account.Sequence)
+
+	tx, err := client.LoadTransaction(hash)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), tx.Ledger)
+	assert.Equal(t, root.Address(), tx.Account)
+	assert.Equal(t, "text", tx.MemoType)
+	assert.Equal(t, "horizontest", tx.Memo)
+
+	page, err := client.LoadPayments(horizon.ForAccount(dest.Address()))
+	require.NoError(t, err)
+	require.Len(t, page.Embedded.Records, 2)
+	created, ok := page.Embedded.Records[0].(*horizon.CreateAccount)
+	require.True(t, ok, "expected a create_account, got %T", page.Embedded.Records[0])
+	assert.Equal(t, "10.0000000", created.StartingBalance)
+	assert.Equal(t, root.Address(), created.Funder)
+
+	payment, ok := page.Embedded.Records[1].(*horizon.Payment)
+	require.True(t, ok, "expected a payment, got %T", page.Embedded.Records[1])
+	assert.Equal(t, "5.0000000", payment.Amount)
+	assert.Equal(t, "native", payment.AssetType)
+	require.NoError(t, client.LoadMemo(payment))
+	assert.Equal(t, "horizontest", payment.Memo.Value)
+
+	for _, kind := range []string{"create_account", "payment"} {
+		select {
+		case op := <-streamed:
+			assert.Equal(t, kind, op.Base().Type)
+			assert.Equal(t, hash, op.Base().TransactionHash)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s not streamed", kind)
+		}
+	}
+}
+
+func TestServer_SubmitFailure(t *testing.T) {
+	s, root := newServer(t)
+	defer s.Close()
+	client := s.Client()
+
+	missing, err := keypair.Random()
+	require.NoError(t, err)
+
+	_, err = submit(t, client, root, build.Payment(
+		build.Destination{AddressOrSeed: missing.Address()},
+		build.NativeAmount{Amount: "5"},
+	))
+	require.True(t, horizon.IsTransactionFailed(err), "expected a failed transaction, got %v", err)
+	codes, err := err.(*horizon.Error).ResultCodes()
+	require.NoError(t, err)
+	assert.Equal(t, "tx_failed", codes.TransactionCode)
+	assert.Equal(t, []string{"op_no_destination"}, codes.OperationCodes)
+
+	// the failed transaction still consumed its sequence number and fee
+	account, err := client.LoadAccount(root.Address())
+	require.NoError(t, err)
+	assert.Equal(t, "1", account.Sequence)
+	assert.Equal(t, "999.9999900", account.GetNativeBalance())
+
+	_, err = client.SubmitTransaction("AAAA")
+	assert.True(t, horizon.IsProblem(err, horizon.ProblemTransactionMalformed), "expected a malformed transaction, got %v", err)
+
+	// transactions the simulator cannot process leave the ledger unchanged
+	before, err := client.LoadLedgers(horizon.OrderDesc, horizon.Limit(1))
+	require.NoError(t, err)
+
+	_, err = submit(t, client, root, build.Payment(
+		build.Destination{AddressOrSeed: root.Address()},
+		build.NativeAmount{Amount: "5"},
+		build.PayWith(build.NativeAsset(), "5"),
+	))
+	assert.True(t, horizon.IsProblem(err, horizon.ProblemTransactionMalformed), "expected a malformed transaction, got %v", err)
+
+	account, err = client.LoadAccount(root.Address())
+	require.NoError(t, err)
+	assert.Equal(t, "1", account.Sequence)
+	assert.Equal(t, "999.9999900", account.GetNativeBalance())
+
+	after, err := client.LoadLedgers(horizon.OrderDesc, horizon.Limit(1))
+	require.NoError(t, err)
+	assert.Equal(t, before.Embedded.Records, after.Embedded.Records)
+
+	_, err = client.LoadTransaction("abc")
+	assert.True(t, horizon.IsNotFound(err), "expected not found, got %v", err)
+}
+
+func TestServer_StreamLedgers(t *testing.T) {
+	s, _ := newServer(t)
+	defer s.Close()
+	client := s.Client()
+	connected := make(chan struct{}, 1)
+	client.StreamObserver = func(ev horizon.StreamEvent) {
+		if ev.Kind == horizon.StreamConnected {
+			connected <- struct{}{}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	streamed := make(chan int32)
+	done := make(chan error)
+	cursor := horizon.Cursor("now")
+	go func() {
+		done <- client.StreamLedgers(ctx, &cursor, func(l horizon.Ledger) error {
+			streamed <- l.Sequence
+			return nil
+		})
+	}()
+
+	// the ledgers closed once the stream connects are new to it
+	select {
+	case <-connected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream not connected")
+	}
+
+	var sequences []int32
+	for len(sequences) < 2 {
+		s.CloseLedger()
+		select {
+		case seq := <-streamed:
+			sequences = append(sequences, seq)
+		case <-time.After(5 * time.Second):
+			t.Fatal("ledger not streamed")
+		}
+	}
+	assert.Equal(t, []int32{2, 3}, sequences)
+
+	page, err := client.LoadLedgers(horizon.Order(horizon.OrderDesc), horizon.Limit(1))
+	require.NoError(t, err)
+	require.Len(t, page.Embedded.Records, 1)
+	assert.Equal(t, int32(3), page.Embedded.Records[0].Sequence)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestServer_Offers(t *testing.T) {
+	seller, err := keypair.Random()
+	require.NoError(t, err)
+	var sellerID xdr.AccountId
+	require.NoError(t, sellerID.SetAddress(seller.Address()))
+
+	native, err := xdr.NewAsset(xdr.AssetTypeAssetTypeNative, nil)
+	require.NoError(t, err)
+	var usd xdr.Asset
+	require.NoError(t, usd.SetCredit("USD", sellerID))
+
+	offer := func(id xdr.Uint64, selling, buying xdr.Asset, amount xdr.Int64, n, d xdr.Int32) xdr.LedgerEntry {
+		return xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeOffer,
+			Offer: &xdr.OfferEntry{
+				SellerId: sellerID,
+				OfferId:  id,
+				Selling:  selling,
+				Buying:   buying,
+				Amount:   amount,
+				Price:    xdr.Price{N: n, D: d},
+			},
+		}}
+	}
+
+	s, _ := newServer(t,
+		offer(1, native, usd, 100000000, 2, 1),
+		offer(2, native, usd, 200000000, 1, 1),
+		offer(3, native, usd, 300000000, 1, 1),
+		offer(4, usd, native, 400000000, 1, 4),
+	)
+	defer s.Close()
+	client := s.Client()
+
+	usdAsset := horizon.Asset{Type: "credit_alphanum4", Code: "USD", Issuer: seller.Address()}
+	book, err := client.LoadOrderBook(horizon.Asset{Type: "native"}, usdAsset)
+	require.NoError(t, err)
+	assert.Equal(t, []horizon.PriceLevel{
+		{PriceR: horizon.Price{N: 1, D: 1}, Price: "1.0000000", Amount: "50.0000000"},
+		{PriceR: horizon.Price{N: 2, D: 1}, Price: "2.0000000", Amount: "10.0000000"},
+	}, book.Asks)
+	assert.Equal(t, []horizon.PriceLevel{
+		{PriceR: horizon.Price{N: 4, D: 1}, Price: "4.0000000", Amount: "40.0000000"},
+	}, book.Bids)
+	assert.Equal(t, usdAsset, book.Buying)
+
+	offers, err := client.LoadAccountOffers(seller.Address(), horizon.Limit(3))
+	require.NoError(t, err)
+	require.Len(t, offers.Embedded.Records, 3)
+	assert.Equal(t, int64(1), offers.Embedded.Records[0].ID)
+	assert.Equal(t, usdAsset, offers.Embedded.Records[0].Buying)
+
+	offers, err = client.LoadAccountOffers(seller.Address(), horizon.At(offers.Links.Next.Href))
+	require.NoError(t, err)
+	require.Len(t, offers.Embedded.Records, 1)
+	assert.Equal(t, int64(4), offers.Embedded.Records[0].ID)
+}
+
+func TestResultCode(t *testing.T) {
+	cases := []struct {
+		code     fmt.Stringer
+		prefix   string
+		expected string
+	}{
+		{xdr.TransactionResultCodeTxBadSeq, "tx_", "tx_bad_seq"},
+		{xdr.TransactionResultCodeTxNoAccount, "tx_", "tx_no_source_account"},
+		{xdr.OperationResultCodeOpBadAuth, "op_", "op_bad_auth"},
+		{xdr.PaymentResultCodePaymentUnderfunded, "op_", "op_underfunded"},
+		{xdr.CreateAccountResultCodeCreateAccountAlreadyExist, "op_", "op_already_exists"},
+		{xdr.AccountMergeResultCodeAccountMergeHasSubEntries, "op_", "op_has_sub_entries"},
+		{xdr.ManageOfferResultCodeManageOfferSellNoTrust, "op_", "op_sell_no_trust"},
+	}
+
+	for _, kase := range cases {
+		assert.Equal(t, kase.expected, resultCode(kase.code, kase.prefix))
+	}
+}
@@ -0,0 +1,293 @@
+package horizontest
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/clients/horizon"
+	"github.com/stellar/go/xdr"
+)
+
+func (s *Server) account(w http.ResponseWriter, address string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	entry, err := s.sim.Account(address)
+	if err != nil || entry == nil {
+		writeProblem(w, horizon.ProblemNotFound, http.StatusNotFound, "", nil)
+		return
+	}
+
+	entries, err := s.sim.Entries()
+	if err != nil {
+		writeProblem(w, horizon.ProblemServerError, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.accountResponse(*entry, entries))
+}
+
+// accountResponse returns the response for `entry`, whose trustlines and data
+// are found among `entries`.
+func (s *Server) accountResponse(entry xdr.AccountEntry, entries []xdr.LedgerEntry) horizon.Account {
+	address := entry.AccountId.Address()
+	self := s.URL + "/accounts/" + address
+
+	account := horizon.Account{
+		HistoryAccount: horizon.HistoryAccount{
+			ID:        address,
+			AccountID: address,
+		},
+		Sequence:      strconv.FormatInt(int64(entry.SeqNum), 10),
+		SubentryCount: int32(entry.NumSubEntries),
+		HomeDomain:    string(entry.HomeDomain),
+		Thresholds: horizon.AccountThresholds{
+			LowThreshold:  entry.Thresholds[1],
+			MedThreshold:  entry.Thresholds[2],
+			HighThreshold: entry.Thresholds[3],
+		},
+		Flags: horizon.AccountFlags{
+			AuthRequired:  entry.Flags&xdr.Uint32(xdr.AccountFlagsAuthRequiredFlag) != 0,
+			AuthRevocable: entry.Flags&xdr.Uint32(xdr.AccountFlagsAuthRevocableFlag) != 0,
+		},
+		Balances: []horizon.Balance{},
+		Signers:  []horizon.Signer{},
+		Data:     map[string]string{},
+	}
+	account.Links.Self.Href = self
+	account.Links.Transactions.Href = self + "/transactions"
+	account.Links.Operations.Href = self + "/operations"
+	account.Links.Payments.Href = self + "/payments"
+	account.Links.Effects.Href = self + "/effects"
+	account.Links.Offers.Href = self + "/offers"
+	if entry.InflationDest != nil {
+		account.InflationDestination = entry.InflationDest.Address()
+	}
+
+	for _, e := range entries {
+		switch e.Data.Type {
+		case xdr.LedgerEntryTypeTrustline:
+			line := e.Data.MustTrustLine()
+			if !line.AccountId.Equals(entry.AccountId) {
+				continue
+			}
+			account.Balances = append(account.Balances, horizon.Balance{
+				Balance: amount.String(line.Balance),
+				Limit:   amount.String(line.Limit),
+				Asset:   responseAsset(line.Asset),
+			})
+		case xdr.LedgerEntryTypeData:
+			data := e.Data.MustData()
+			if !data.AccountId.Equals(entry.AccountId) {
+				continue
+			}
+			account.Data[string(data.DataName)] = base64.StdEncoding.EncodeToString(data.DataValue)
+		}
+	}
+	account.Balances = append(account.Balances, horizon.Balance{
+		Balance: amount.String(entry.Balance),
+		Asset:   horizon.Asset{Type: "native"},
+	})
+
+	for _, signer := range entry.Signers {
+		key := signer.Key.Address()
+		account.Signers = append(account.Signers, horizon.Signer{
+			PublicKey: key,
+			Weight:    int32(signer.Weight),
+			Key:       key,
+			Type:      signerType(signer.Key.Type),
+		})
+	}
+	account.Signers = append(account.Signers, horizon.Signer{
+		PublicKey: address,
+		Weight:    int32(entry.Thresholds[0]),
+		Key:       address,
+		Type:      "ed25519_public_key",
+	})
+
+	return account
+}
+
+// signerType returns the name horizon gives signers of type `typ`.
+func signerType(typ xdr.SignerKeyType) string {
+	switch typ {
+	case xdr.SignerKeyTypeSignerKeyTypeHashX:
+		return "sha256_hash"
+	case xdr.SignerKeyTypeSignerKeyTypeHashTx:
+		return "preauth_tx"
+	}
+	return "ed25519_public_key"
+}
+
+func (s *Server) offers(w http.ResponseWriter, r *http.Request, address string) {
+	q, err := s.pageQuery(r)
+	if err != nil {
+		writeProblem(w, horizon.ProblemBadRequest, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	s.lock.Lock()
+	entries, err := s.sim.Entries()
+	s.lock.Unlock()
+	if err != nil {
+		writeProblem(w, horizon.ProblemServerError, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	var records []record
+	for _, offer := range offersOf(entries) {
+		if offer.SellerId.Address() != address {
+			continue
+		}
+
+		resp := horizon.Offer{
+			ID:      int64(offer.OfferId),
+			PT:      strconv.FormatUint(uint64(offer.OfferId), 10),
+			Seller:  address,
+			Selling: responseAsset(offer.Selling),
+			Buying:  responseAsset(offer.Buying),
+			Amount:  amount.String(offer.Amount),
+			PriceR:  horizon.Price{N: int32(offer.Price.N), D: int32(offer.Price.D)},
+			Price:   offer.Price.String(),
+		}
+		resp.Links.Self.Href = fmt.Sprintf("%s/offers/%d", s.URL, offer.OfferId)
+		resp.Links.OfferMaker.Href = s.URL + "/accounts/" + address
+
+		records = append(records, record{
+			pt:   int64(offer.OfferId),
+			data: mustMarshal(resp),
+		})
+	}
+
+	writePage(w, r, selectRecords(records, "", q), q)
+}
+
+// orderBook serves the summary of the offers between the selling and buying
+// assets of the request.  Asks are the offers selling the selling asset for
+// the buying asset, and bids those selling the buying asset for the selling
+// asset, priced and grouped as horizon does.
+func (s *Server) orderBook(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	selling, err := queryAsset(query, "selling_")
+	if err != nil {
+		writeProblem(w, horizon.ProblemBadRequest, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	buying, err := queryAsset(query, "buying_")
+	if err != nil {
+		writeProblem(w, horizon.ProblemBadRequest, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	s.lock.Lock()
+	entries, err := s.sim.Entries()
+	s.lock.Unlock()
+	if err != nil {
+		writeProblem(w, horizon.ProblemServerError, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	var asks, bids []xdr.OfferEntry
+	for _, offer := range offersOf(entries) {
+		switch {
+		case offer.Selling.Equals(selling) && offer.Buying.Equals(buying):
+			asks = append(asks, offer)
+		case offer.Selling.Equals(buying) && offer.Buying.Equals(selling):
+			offer.Price.Invert()
+			bids = append(bids, offer)
+		}
+	}
+
+	summary := horizon.OrderBookSummary{
+		Asks:    priceLevels(asks, false),
+		Bids:    priceLevels(bids, true),
+		Selling: responseAsset(selling),
+		Buying:  responseAsset(buying),
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
+
+// offersOf returns the offers among `entries`, ordered by id.
+func offersOf(entries []xdr.LedgerEntry) []xdr.OfferEntry {
+	var offers []xdr.OfferEntry
+	for _, e := range entries {
+		if offer, ok := e.Data.GetOffer(); ok {
+			offers = append(offers, offer)
+		}
+	}
+
+	sort.Slice(offers, func(i, j int) bool {
+		return offers[i].OfferId < offers[j].OfferId
+	})
+	return offers
+}
+
+// priceLevels groups `offers` by price, best price first: lowest first, or
+// highest first if `desc` is set.
+func priceLevels(offers []xdr.OfferEntry, desc bool) []horizon.PriceLevel {
+	sort.SliceStable(offers, func(i, j int) bool {
+		a, b := offers[i].Price, offers[j].Price
+		if desc {
+			a, b = b, a
+		}
+		return int64(a.N)*int64(b.D) < int64(b.N)*int64(a.D)
+	})
+
+	levels := []horizon.PriceLevel{}
+	var total xdr.Int64
+	for i, offer := range offers {
+		total += offer.Amount
+
+		last := i == len(offers)-1
+		if !last && offers[i+1].Price == offer.Price {
+			continue
+		}
+
+		levels = append(levels, horizon.PriceLevel{
+			PriceR: horizon.Price{N: int32(offer.Price.N), D: int32(offer.Price.D)},
+			Price:  offer.Price.String(),
+			Amount: amount.String(total),
+		})
+		total = 0
+	}
+	return levels
+}
+
+// responseAsset returns `asset` as horizon describes it.
+func responseAsset(asset xdr.Asset) horizon.Asset {
+	var resp horizon.Asset
+	asset.MustExtract(&resp.Type, &resp.Code, &resp.Issuer)
+	return resp
+}
+
+// queryAsset returns the asset described by the type, code and issuer in
+// `query`, with each key prefixed by `prefix`.
+func queryAsset(query url.Values, prefix string) (xdr.Asset, error) {
+	var asset xdr.Asset
+
+	typ := query.Get(prefix + "asset_type")
+	if typ == "native" {
+		err := asset.SetNative()
+		return asset, err
+	}
+	if typ != "credit_alphanum4" && typ != "credit_alphanum12" {
+		return asset, fmt.Errorf("invalid %sasset_type: %s", prefix, typ)
+	}
+
+	var issuer xdr.AccountId
+	err := issuer.SetAddress(query.Get(prefix + "asset_issuer"))
+	if err != nil {
+		return asset, fmt.Errorf("invalid %sasset_issuer: %s", prefix, query.Get(prefix+"asset_issuer"))
+	}
+
+	err = asset.SetCredit(query.Get(prefix+"asset_code"), issuer)
+	if err != nil {
+		return asset, fmt.Errorf("invalid %sasset_code: %s", prefix, query.Get(prefix+"asset_code"))
+	}
+	return asset, nil
+}
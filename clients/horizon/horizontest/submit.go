@@ -0,0 +1,335 @@
+package horizontest
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/clients/horizon"
+	"github.com/stellar/go/exp/simulator"
+	"github.com/stellar/go/xdr"
+)
+
+// transaction is a transaction the server applied successfully.
+type transaction struct {
+	env    xdr.TransactionEnvelope
+	result *simulator.Result
+}
+
+// submit applies the transaction posted in the `tx` form value, closing a
+// ledger if it was applied.
+func (s *Server) submit(w http.ResponseWriter, r *http.Request) {
+	envelope := r.PostFormValue("tx")
+
+	var env xdr.TransactionEnvelope
+	err := xdr.SafeUnmarshalBase64(envelope, &env)
+	if err != nil {
+		writeProblem(w, horizon.ProblemTransactionMalformed, http.StatusBadRequest, err.Error(), map[string]interface{}{
+			"envelope_xdr": envelope,
+		})
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	s.sim.Sequence = uint32(s.latestLedger() + 1)
+	s.sim.CloseTime = now
+
+	// the simulator leaves the ledger unchanged when it cannot process a
+	// transaction, such as one holding an operation it does not implement
+	result, err := s.sim.SubmitEnvelope(env)
+	if err != nil {
+		writeProblem(w, horizon.ProblemTransactionMalformed, http.StatusBadRequest, err.Error(), map[string]interface{}{
+			"envelope_xdr": envelope,
+		})
+		return
+	}
+
+	resultXDR, err := xdr.MarshalBase64(result.Result)
+	if err != nil {
+		writeProblem(w, horizon.ProblemServerError, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	if !result.Successful() {
+		// a failed transaction that was applied still consumed its fee and
+		// sequence number
+		if result.Bundle != nil {
+			s.closeLedger(now, nil)
+		}
+
+		writeProblem(w, horizon.ProblemTransactionFailed, http.StatusBadRequest, "", map[string]interface{}{
+			"envelope_xdr": envelope,
+			"result_xdr":   resultXDR,
+			"result_codes": resultCodes(result.Result),
+		})
+		return
+	}
+
+	tx := &transaction{env: env, result: result}
+	s.closeLedger(now, tx)
+
+	var found horizon.Transaction
+	err = json.Unmarshal(s.byHash[hex.EncodeToString(result.Hash[:])], &found)
+	if err != nil {
+		writeProblem(w, horizon.ProblemServerError, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	success := horizon.TransactionSuccess{
+		Hash:   found.Hash,
+		Ledger: found.Ledger,
+		Env:    found.EnvelopeXdr,
+		Result: found.ResultXdr,
+		Meta:   found.ResultMetaXdr,
+	}
+	success.Links.Transaction.Href = found.Links.Self.Href
+	writeJSON(w, http.StatusOK, success)
+}
+
+// addTransaction records `tx`, applied in ledger `sequence`, and the
+// payments it made.  The caller must hold the lock.
+func (s *Server) addTransaction(sequence int32, closedAt time.Time, tx *transaction) {
+	hash := hex.EncodeToString(tx.result.Hash[:])
+	source := tx.env.Tx.SourceAccount.Address()
+	self := s.URL + "/transactions/" + hash
+
+	resp := horizon.Transaction{
+		ID:              hash,
+		PagingToken:     strconv.FormatInt(toid(sequence, 1, 0), 10),
+		Hash:            hash,
+		Ledger:          sequence,
+		LedgerCloseTime: closedAt,
+		Account:         source,
+		AccountSequence: strconv.FormatInt(int64(tx.env.Tx.SeqNum), 10),
+		FeePaid:         int32(tx.result.Result.FeeCharged),
+		OperationCount:  int32(len(tx.env.Tx.Operations)),
+		EnvelopeXdr:     mustMarshalBase64(tx.env),
+		ResultXdr:       mustMarshalBase64(tx.result.Result),
+		ResultMetaXdr:   mustMarshalBase64(tx.result.Bundle.TransactionMeta),
+		FeeMetaXdr:      mustMarshalBase64(tx.result.Bundle.FeeMeta),
+		Signatures:      []string{},
+	}
+	resp.Links.Self.Href = self
+	resp.Links.Account.Href = s.URL + "/accounts/" + source
+	resp.Links.Ledger.Href = fmt.Sprintf("%s/ledgers/%d", s.URL, sequence)
+	resp.Links.Operations.Href = self + "/operations"
+	resp.Links.Effects.Href = self + "/effects"
+	resp.MemoType, resp.Memo = memo(tx.env.Tx.Memo)
+
+	for _, sig := range tx.env.Signatures {
+		resp.Signatures = append(resp.Signatures, base64.StdEncoding.EncodeToString(sig.Signature))
+	}
+
+	if tb := tx.env.Tx.TimeBounds; tb != nil {
+		resp.ValidAfter = time.Unix(int64(tb.MinTime), 0).UTC().Format(time.RFC3339)
+		if tb.MaxTime != 0 {
+			resp.ValidBefore = time.Unix(int64(tb.MaxTime), 0).UTC().Format(time.RFC3339)
+		}
+	}
+
+	participants := []string{source}
+	for i, op := range tx.env.Tx.Operations {
+		opSource := source
+		if op.SourceAccount != nil {
+			opSource = op.SourceAccount.Address()
+		}
+		participants = append(participants, opSource)
+
+		base := horizon.BaseOperation{
+			ID:              strconv.FormatInt(toid(sequence, 1, i+1), 10),
+			PT:              strconv.FormatInt(toid(sequence, 1, i+1), 10),
+			SourceAccount:   opSource,
+			TypeI:           int32(op.Body.Type),
+			CreatedAt:       closedAt,
+			TransactionHash: hash,
+		}
+		base.Links.Self.Href = s.URL + "/operations/" + base.ID
+		base.Links.Transaction.Href = self
+		base.Links.Effects.Href = base.Links.Self.Href + "/effects"
+
+		payment, to := paymentOf(base, opSource, op)
+		if payment == nil {
+			continue
+		}
+		participants = append(participants, to)
+
+		s.add("payments", record{
+			pt:       toid(sequence, 1, i+1),
+			accounts: []string{opSource, to},
+			data:     mustMarshal(payment),
+		})
+	}
+
+	data := mustMarshal(resp)
+	s.byHash[hash] = data
+	s.add("transactions", record{
+		pt:       toid(sequence, 1, 0),
+		accounts: participants,
+		data:     data,
+	})
+}
+
+// paymentOf returns the response for `op`, made by `source`, and the account
+// it paid, if it is a payment.
+func paymentOf(base horizon.BaseOperation, source string, op xdr.Operation) (interface{}, string) {
+	switch op.Body.Type {
+	case xdr.OperationTypeCreateAccount:
+		body := op.Body.MustCreateAccountOp()
+		base.Type = "create_account"
+		return &horizon.CreateAccount{
+			BaseOperation:   base,
+			StartingBalance: amount.String(body.StartingBalance),
+			Funder:          source,
+			Account:         body.Destination.Address(),
+		}, body.Destination.Address()
+	case xdr.OperationTypePayment:
+		body := op.Body.MustPaymentOp()
+		base.Type = "payment"
+		payment := &horizon.Payment{
+			BaseOperation: base,
+			From:          source,
+			To:            body.Destination.Address(),
+			Amount:        amount.String(body.Amount),
+		}
+		asset := responseAsset(body.Asset)
+		payment.AssetType, payment.AssetCode, payment.AssetIssuer = asset.Type, asset.Code, asset.Issuer
+		return payment, body.Destination.Address()
+	case xdr.OperationTypeAccountMerge:
+		into := op.Body.MustDestination()
+		base.Type = "account_merge"
+		return &horizon.AccountMerge{
+			BaseOperation: base,
+			Account:       source,
+			Into:          into.Address(),
+		}, into.Address()
+	}
+	return nil, ""
+}
+
+// memo returns the memo_type and memo horizon reports for `m`.
+func memo(m xdr.Memo) (string, string) {
+	switch m.Type {
+	case xdr.MemoTypeMemoText:
+		return "text", m.MustText()
+	case xdr.MemoTypeMemoId:
+		return "id", strconv.FormatUint(uint64(m.MustId()), 10)
+	case xdr.MemoTypeMemoHash:
+		hash := m.MustHash()
+		return "hash", base64.StdEncoding.EncodeToString(hash[:])
+	case xdr.MemoTypeMemoReturn:
+		hash := m.MustRetHash()
+		return "return", base64.StdEncoding.EncodeToString(hash[:])
+	}
+	return "none", ""
+}
+
+// resultCodeNames are the result codes horizon names differently from the
+// names of their xdr values.
+var resultCodeNames = map[string]string{
+	"TransactionResultCodeTxNoAccount":                 "tx_no_source_account",
+	"OperationResultCodeOpNoAccount":                   "op_no_source_account",
+	"CreateAccountResultCodeCreateAccountAlreadyExist": "op_already_exists",
+	"AllowTrustResultCodeAllowTrustNoTrustLine":        "op_no_trustline",
+	"ManageOfferResultCodeManageOfferNotFound":         "op_offer_not_found",
+}
+
+// resultCodes returns the result codes horizon reports for `result`.
+func resultCodes(result xdr.TransactionResult) horizon.TransactionResultCodes {
+	codes := horizon.TransactionResultCodes{
+		TransactionCode: resultCode(result.Result.Code, "tx_"),
+	}
+
+	opResults, ok := result.Result.GetResults()
+	if !ok {
+		return codes
+	}
+
+	for _, opResult := range opResults {
+		if opResult.Code != xdr.OperationResultCodeOpInner {
+			codes.OperationCodes = append(codes.OperationCodes, resultCode(opResult.Code, "op_"))
+			continue
+		}
+		codes.OperationCodes = append(codes.OperationCodes, resultCode(innerCode(opResult.MustTr()), "op_"))
+	}
+	return codes
+}
+
+// innerCode returns the code of the result of an operation that ran.
+func innerCode(tr xdr.OperationResultTr) fmt.Stringer {
+	switch tr.Type {
+	case xdr.OperationTypeCreateAccount:
+		return tr.MustCreateAccountResult().Code
+	case xdr.OperationTypePayment:
+		return tr.MustPaymentResult().Code
+	case xdr.OperationTypePathPayment:
+		return tr.MustPathPaymentResult().Code
+	case xdr.OperationTypeManageOffer:
+		return tr.MustManageOfferResult().Code
+	case xdr.OperationTypeCreatePassiveOffer:
+		return tr.MustCreatePassiveOfferResult().Code
+	case xdr.OperationTypeSetOptions:
+		return tr.MustSetOptionsResult().Code
+	case xdr.OperationTypeChangeTrust:
+		return tr.MustChangeTrustResult().Code
+	case xdr.OperationTypeAllowTrust:
+		return tr.MustAllowTrustResult().Code
+	case xdr.OperationTypeAccountMerge:
+		return tr.MustAccountMergeResult().Code
+	case xdr.OperationTypeInflation:
+		return tr.MustInflationResult().Code
+	case xdr.OperationTypeManageData:
+		return tr.MustManageDataResult().Code
+	}
+	return xdr.OperationResultCodeOpInner
+}
+
+// resultCode returns the name horizon gives the result code `code`, such as
+// "tx_bad_seq" for TransactionResultCodeTxBadSeq or "op_underfunded" for
+// PaymentResultCodePaymentUnderfunded.  It is derived from the name of the
+// xdr value by dropping the name of its type and of the operation, and
+// starting the rest with `prefix`.
+func resultCode(code fmt.Stringer, prefix string) string {
+	name := code.String()
+	if horizonName, ok := resultCodeNames[name]; ok {
+		return horizonName
+	}
+
+	i := strings.Index(name, "ResultCode")
+	if i < 0 {
+		return name
+	}
+	op, rest := name[:i], name[i+len("ResultCode"):]
+	rest = strings.TrimPrefix(rest, op)
+	rest = strings.TrimPrefix(rest, "Tx")
+	rest = strings.TrimPrefix(rest, "Op")
+
+	var snake []rune
+	for i, c := range rest {
+		if unicode.IsUpper(c) {
+			if i > 0 {
+				snake = append(snake, '_')
+			}
+			c = unicode.ToLower(c)
+		}
+		snake = append(snake, c)
+	}
+	return prefix + string(snake)
+}
+
+func mustMarshalBase64(v interface{}) string {
+	data, err := xdr.MarshalBase64(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
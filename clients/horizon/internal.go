@@ -1,18 +1,14 @@
 package horizon
 
 import (
-	"bytes"
 	"encoding/json"
-	"fmt"
 	"net/http"
-	"regexp"
+	"net/url"
+	"time"
 
-	"github.com/manucorporat/sse"
 	"github.com/stellar/go/support/errors"
 )
 
-var endEvent = regexp.MustCompile("(\r\n|\r|\n){2}")
-
 func decodeResponse(resp *http.Response, object interface{}) (err error) {
 	defer resp.Body.Close()
 	decoder := json.NewDecoder(resp.Body)
@@ -35,39 +31,16 @@ func decodeResponse(resp *http.Response, object interface{}) (err error) {
 	return
 }
 
-func loadMemo(p *Payment) error {
-	res, err := http.Get(p.Links.Transaction.Href)
-	if err != nil {
-		return err
-	}
-	defer res.Body.Close()
-	return json.NewDecoder(res.Body).Decode(&p.Memo)
+// addAsset adds the type, code and issuer of `asset` to `query`, with each
+// key prefixed by `prefix`, e.g. "selling_".
+func addAsset(query url.Values, prefix string, asset Asset) {
+	query.Add(prefix+"asset_type", asset.Type)
+	query.Add(prefix+"asset_code", asset.Code)
+	query.Add(prefix+"asset_issuer", asset.Issuer)
 }
 
-func parseEvent(data []byte) (result sse.Event, err error) {
-	r := bytes.NewReader(data)
-	events, err := sse.Decode(r)
-	if err != nil {
-		return
-	}
-
-	if len(events) != 1 {
-		err = fmt.Errorf("only expected 1 event, got: %d", len(events))
-		return
-	}
-
-	result = events[0]
-	return
-}
-
-func splitSSE(data []byte, atEOF bool) (advance int, token []byte, err error) {
-	if atEOF {
-		return 0, nil, nil
-	}
-
-	if loc := endEvent.FindIndex(data); loc != nil {
-		return loc[1], data[0:loc[1]], nil
-	}
-
-	return 0, nil, nil
+// millis returns `t` as the number of milliseconds since the unix epoch, the
+// form in which horizon accepts times.
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
 }
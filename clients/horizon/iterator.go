@@ -0,0 +1,284 @@
+package horizon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/stellar/go/support/errors"
+	"golang.org/x/net/context"
+)
+
+// Iterator walks the records of a horizon collection, loading each page of
+// records lazily by following the collection's "next" links.  Its use mirrors
+// that of sql.Rows:
+//
+//	it := client.AccountOffers(ctx, address, Limit(200))
+//	for it.Next() {
+//		var offer Offer
+//		if err := it.Scan(&offer); err != nil {
+//			return err
+//		}
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+//
+// Iteration stops at the first empty page.
+type Iterator struct {
+	ctx     context.Context
+	client  *Client
+	next    string
+	records []json.RawMessage
+	current json.RawMessage
+	err     error
+}
+
+// Page is a single page of a HAL collection response, with its records left
+// undecoded.
+type Page struct {
+	Links struct {
+		Self Link `json:"self"`
+		Next Link `json:"next"`
+		Prev Link `json:"prev"`
+	} `json:"_links"`
+	Embedded struct {
+		Records []json.RawMessage `json:"records"`
+	} `json:"_embedded"`
+}
+
+// Iterate returns an iterator over the records of the collection at `path`,
+// which is relative to the client's URL, e.g. "/ledgers".  The `At`, `Cursor`,
+// `Limit` and `Order` params are honored, as are scope params such as
+// ForAccount; `Limit` sets the size of each page that is loaded rather than
+// the total number of records returned.
+func (c *Client) Iterate(ctx context.Context, path string, params ...interface{}) *Iterator {
+	return c.iterate(ctx, path, nil, params)
+}
+
+// AccountOffers returns an iterator over all the offers of `accountID`.
+// Records should be scanned into an Offer.
+func (c *Client) AccountOffers(ctx context.Context, accountID string, params ...interface{}) *Iterator {
+	return c.Iterate(ctx, fmt.Sprintf("/accounts/%s/offers", accountID), params...)
+}
+
+// Transactions returns an iterator over all the transactions.  Pass ForAccount
+// or ForLedger to only iterate over the transactions of one account or ledger.
+// Records should be scanned into a Transaction.
+func (c *Client) Transactions(ctx context.Context, params ...interface{}) *Iterator {
+	return c.Iterate(ctx, "/transactions", params...)
+}
+
+// Operations returns an iterator over all the operations.  Pass ForAccount,
+// ForLedger or ForTransaction to only iterate over the operations of one
+// account, ledger or transaction.  Records should be decoded with
+// Iterator.Operation.
+func (c *Client) Operations(ctx context.Context, params ...interface{}) *Iterator {
+	return c.Iterate(ctx, "/operations", params...)
+}
+
+// Effects returns an iterator over all the effects.  Pass ForAccount,
+// ForLedger or ForTransaction to only iterate over the effects of one account,
+// ledger or transaction.  Records should be decoded with Iterator.Effect.
+func (c *Client) Effects(ctx context.Context, params ...interface{}) *Iterator {
+	return c.Iterate(ctx, "/effects", params...)
+}
+
+// Payments returns an iterator over all the payments.  Pass ForAccount,
+// ForLedger or ForTransaction to only iterate over the payments of one
+// account, ledger or transaction.  Payments are create_account, payment,
+// path_payment and account_merge operations, so records should be decoded
+// with Iterator.Operation.
+func (c *Client) Payments(ctx context.Context, params ...interface{}) *Iterator {
+	return c.Iterate(ctx, "/payments", params...)
+}
+
+// Ledgers returns an iterator over all the ledgers.  Records should be scanned
+// into a Ledger.
+func (c *Client) Ledgers(ctx context.Context, params ...interface{}) *Iterator {
+	return c.Iterate(ctx, "/ledgers", params...)
+}
+
+// Assets returns an iterator over the statistics of all the assets.  Pass
+// AssetCode or AssetIssuer to filter the assets.  Records should be scanned
+// into an AssetStat.
+func (c *Client) Assets(ctx context.Context, params ...interface{}) *Iterator {
+	return c.Iterate(ctx, "/assets", params...)
+}
+
+// Trades returns an iterator over all the trades between the provided base and
+// counter assets.  Records should be scanned into a Trade.
+func (c *Client) Trades(ctx context.Context, base, counter Asset, params ...interface{}) *Iterator {
+	return c.iterate(ctx, "/trades", tradesQuery(base, counter), params)
+}
+
+// TradeAggregations returns an iterator over all the trades between the
+// provided base and counter assets from `start` until `end`, aggregated into
+// buckets of `resolution`.  Records should be scanned into a TradeAggregation.
+func (c *Client) TradeAggregations(
+	ctx context.Context,
+	base, counter Asset,
+	start, end time.Time,
+	resolution time.Duration,
+	params ...interface{},
+) *Iterator {
+	query := tradeAggregationsQuery(base, counter, start, end, resolution)
+	return c.iterate(ctx, "/trade_aggregations", query, params)
+}
+
+// iterate returns an iterator over the records of the collection at `path`
+// after applying the provided query and params.
+func (c *Client) iterate(ctx context.Context, path string, query url.Values, params []interface{}) *Iterator {
+	endpoint, err := c.collectionURL(path, query, params)
+	return &Iterator{
+		ctx:    ctx,
+		client: c,
+		next:   endpoint,
+		err:    err,
+	}
+}
+
+// Next advances the iterator to the next record, loading the next page of the
+// collection if needed.  It returns false when the collection is exhausted or
+// an error occurs, after which Err should be checked.
+func (it *Iterator) Next() bool {
+	it.current = nil
+	if it.err != nil {
+		return false
+	}
+
+	for len(it.records) == 0 {
+		if it.next == "" {
+			return false
+		}
+
+		var page Page
+		err := it.client.loadPage(it.ctx, it.next, &page)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.records = page.Embedded.Records
+
+		// stop on an empty page, or if horizon links a page to itself
+		if len(it.records) == 0 || page.Links.Next.Href == it.next {
+			it.next = ""
+		} else {
+			it.next = page.Links.Next.Href
+		}
+	}
+
+	it.current, it.records = it.records[0], it.records[1:]
+	return true
+}
+
+// Scan decodes the current record into `dest`.
+func (it *Iterator) Scan(dest interface{}) error {
+	if it.current == nil {
+		return errors.New("Scan called without a successful call to Next")
+	}
+
+	err := json.Unmarshal(it.current, dest)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode record")
+	}
+
+	return nil
+}
+
+// Operation decodes the current record, which must be an operation, into the
+// struct for its type.
+func (it *Iterator) Operation() (Operation, error) {
+	if it.current == nil {
+		return nil, errors.New("Operation called without a successful call to Next")
+	}
+
+	return UnmarshalOperation(it.current)
+}
+
+// Effect decodes the current record, which must be an effect, into the struct
+// for its type.
+func (it *Iterator) Effect() (Effect, error) {
+	if it.current == nil {
+		return nil, errors.New("Effect called without a successful call to Next")
+	}
+
+	return UnmarshalEffect(it.current)
+}
+
+// Err returns the error, if any, that stopped the iteration.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// collectionURL returns the url of the first page of the collection at `path`
+// after applying the provided query and params.  Scope params, such as
+// ForAccount, prefix `path` with the url of the resource they name.
+func (c *Client) collectionURL(path string, query url.Values, params []interface{}) (string, error) {
+	endpoint := ""
+	scope := ""
+	if query == nil {
+		query = url.Values{}
+	}
+
+	for _, param := range params {
+		switch param := param.(type) {
+		case At:
+			endpoint = string(param)
+		case Limit:
+			query.Add("limit", strconv.Itoa(int(param)))
+		case Order:
+			query.Add("order", string(param))
+		case Cursor:
+			query.Add("cursor", string(param))
+		case AssetCode:
+			query.Add("asset_code", string(param))
+		case AssetIssuer:
+			query.Add("asset_issuer", string(param))
+		case ForAccount:
+			scope = "/accounts/" + string(param)
+		case ForLedger:
+			scope = fmt.Sprintf("/ledgers/%d", param)
+		case ForTransaction:
+			scope = "/transactions/" + string(param)
+		default:
+			return "", fmt.Errorf("Undefined parameter: %+v", param)
+		}
+	}
+
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("%s%s%s?%s", c.URL, scope, path, query.Encode())
+	}
+
+	// ensure our endpoint is a real url
+	_, err := url.Parse(endpoint)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse endpoint")
+	}
+
+	return endpoint, nil
+}
+
+// loadCollection loads the first page of the collection at `path` into
+// `page`.
+func (c *Client) loadCollection(ctx context.Context, path string, query url.Values, params []interface{}, page interface{}) error {
+	endpoint, err := c.collectionURL(path, query, params)
+	if err != nil {
+		return err
+	}
+
+	return c.loadPage(ctx, endpoint, page)
+}
+
+// loadPage loads the collection page at `endpoint` into `page`.
+func (c *Client) loadPage(ctx context.Context, endpoint string, page interface{}) error {
+	resp, err := c.get(ctx, endpoint)
+	if err != nil {
+		return errors.Wrap(err, "failed to load endpoint")
+	}
+
+	return decodeResponse(resp, page)
+}
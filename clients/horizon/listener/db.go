@@ -0,0 +1,290 @@
+package listener
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stellar/go/clients/horizon"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+	"golang.org/x/net/context"
+)
+
+type cursorRow struct {
+	Account     string `db:"account"`
+	PagingToken string `db:"paging_token"`
+}
+
+type paymentRow struct {
+	ID            string `db:"id"`
+	Account       string `db:"account"`
+	PagingToken   int64  `db:"paging_token"`
+	Payment       string `db:"payment"`
+	Status        string `db:"status"`
+	Attempts      int    `db:"attempts"`
+	LastError     string `db:"last_error"`
+	NextAttemptAt int64  `db:"next_attempt_at"`
+}
+
+// Dead returns the payments the listener gave up on, in the order they were
+// made.
+func (l *Listener) Dead() ([]Delivery, error) {
+	var rows []paymentRow
+	err := l.Session.Clone().GetTable("listener_payments").
+		Select(&rows, sq.Eq{"account": l.Account, "status": string(StatusDead)}).
+		OrderBy("paging_token ASC").
+		Exec()
+	if err != nil {
+		return nil, errors.Wrap(err, "select dead payments failed")
+	}
+
+	deliveries := make([]Delivery, len(rows))
+	for i, row := range rows {
+		deliveries[i], err = row.delivery()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return deliveries, nil
+}
+
+// Requeue marks the dead payment with id `id` pending again, so that a
+// running listener tries to deliver it another MaxAttempts times.
+func (l *Listener) Requeue(id string) error {
+	result, err := l.Session.Clone().GetTable("listener_payments").
+		Update(nil, sq.Eq{"id": id, "status": string(StatusDead)}).
+		SetMap(map[string]interface{}{
+			"status":          string(StatusPending),
+			"attempts":        0,
+			"next_attempt_at": 0,
+		}).
+		Exec()
+	if err != nil {
+		return errors.Wrap(err, "update payment failed")
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected failed")
+	}
+	if n == 0 {
+		return errors.Errorf("no dead payment %s", id)
+	}
+	return nil
+}
+
+// loadCursor returns the position to stream the account's payments from.
+func (l *Listener) loadCursor() (*horizon.Cursor, error) {
+	var row cursorRow
+	err := l.Session.Clone().GetTable("listener_cursors").
+		Get(&row, sq.Eq{"account": l.Account}).
+		Exec()
+
+	if l.Session.NoRows(errors.Cause(err)) {
+		if l.Cursor == "" {
+			return nil, nil
+		}
+		cursor := l.Cursor
+		return &cursor, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "get cursor failed")
+	}
+
+	cursor := horizon.Cursor(row.PagingToken)
+	return &cursor, nil
+}
+
+// record records `op` as pending if it is an incoming payment, and moves the
+// cursor past it, in one transaction.
+func (l *Listener) record(ctx context.Context, op horizon.Operation) error {
+	base := op.Base()
+	payment, ok := incoming(op, l.Account)
+
+	var row paymentRow
+	if ok {
+		err := l.Client.LoadMemoContext(ctx, &payment)
+		if err != nil {
+			return errors.Wrap(err, "load memo failed")
+		}
+
+		raw, err := json.Marshal(payment)
+		if err != nil {
+			return errors.Wrap(err, "marshal payment failed")
+		}
+
+		pt, err := strconv.ParseInt(base.PT, 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "parse paging token failed")
+		}
+
+		row = paymentRow{
+			ID:          base.ID,
+			Account:     l.Account,
+			PagingToken: pt,
+			Payment:     string(raw),
+			Status:      string(StatusPending),
+		}
+	}
+
+	s := l.Session.Clone()
+	err := s.Begin()
+	if err != nil {
+		return errors.Wrap(err, "begin failed")
+	}
+	defer s.Rollback()
+
+	if ok {
+		var count int
+		err = s.GetRaw(&count, "SELECT COUNT(*) FROM listener_payments WHERE id = ?", row.ID)
+		if err != nil {
+			return errors.Wrap(err, "count payments failed")
+		}
+
+		if count == 0 {
+			_, err = s.GetTable("listener_payments").Insert(row).Exec()
+			if err != nil {
+				return errors.Wrap(err, "insert payment failed")
+			}
+		}
+	}
+
+	err = saveCursor(s, l.Account, base.PT)
+	if err != nil {
+		return err
+	}
+
+	return s.Commit()
+}
+
+// saveCursor sets the cursor of `account` to `pt`.
+func saveCursor(s *db.Session, account, pt string) error {
+	result, err := s.GetTable("listener_cursors").
+		Update(nil, sq.Eq{"account": account}).
+		Set("paging_token", pt).
+		Exec()
+	if err != nil {
+		return errors.Wrap(err, "update cursor failed")
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected failed")
+	}
+	if n > 0 {
+		return nil
+	}
+
+	_, err = s.GetTable("listener_cursors").
+		Insert(cursorRow{Account: account, PagingToken: pt}).
+		Exec()
+	return errors.Wrap(err, "insert cursor failed")
+}
+
+// deliverPending delivers each pending payment whose next attempt is due, in
+// the order they were made.
+func (l *Listener) deliverPending(ctx context.Context) error {
+	var rows []paymentRow
+	err := l.Session.Clone().GetTable("listener_payments").
+		Select(&rows, sq.And{
+			sq.Eq{"account": l.Account, "status": string(StatusPending)},
+			sq.LtOrEq{"next_attempt_at": time.Now().UnixNano()},
+		}).
+		OrderBy("paging_token ASC").
+		Exec()
+	if err != nil {
+		return errors.Wrap(err, "select pending payments failed")
+	}
+
+	for _, row := range rows {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		err = l.deliver(ctx, row)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliver calls the handler with the payment of `row`, marking it delivered
+// in the transaction given to the handler if it succeeds, or recording the
+// failed attempt if it does not.  A handler that fails once ctx is done is
+// assumed to have failed because of it, so the attempt is not recorded.  Only
+// errors from the database are returned.
+func (l *Listener) deliver(ctx context.Context, row paymentRow) error {
+	d, err := row.delivery()
+	if err != nil {
+		return err
+	}
+
+	s := l.Session.Clone()
+	err = s.Begin()
+	if err != nil {
+		return errors.Wrap(err, "begin failed")
+	}
+	defer s.Rollback()
+
+	attempts := row.Attempts + 1
+	herr := l.Handler(ctx, s, d.Payment)
+	if herr == nil {
+		_, err = s.GetTable("listener_payments").
+			Update(nil, sq.Eq{"id": row.ID}).
+			SetMap(map[string]interface{}{
+				"status":     string(StatusDelivered),
+				"attempts":   attempts,
+				"last_error": "",
+			}).
+			Exec()
+		if err != nil {
+			return errors.Wrap(err, "update payment failed")
+		}
+		return s.Commit()
+	}
+
+	// discard whatever the handler did before failing
+	err = s.Rollback()
+	if err != nil {
+		return errors.Wrap(err, "rollback failed")
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	status := StatusPending
+	if attempts >= l.maxAttempts() {
+		status = StatusDead
+	}
+
+	_, err = s.GetTable("listener_payments").
+		Update(nil, sq.Eq{"id": row.ID}).
+		SetMap(map[string]interface{}{
+			"status":          string(status),
+			"attempts":        attempts,
+			"last_error":      herr.Error(),
+			"next_attempt_at": time.Now().Add(l.backoff(attempts)).UnixNano(),
+		}).
+		Exec()
+	return errors.Wrap(err, "update payment failed")
+}
+
+func (row paymentRow) delivery() (Delivery, error) {
+	d := Delivery{
+		Status:    Status(row.Status),
+		Attempts:  row.Attempts,
+		LastError: row.LastError,
+	}
+	err := json.Unmarshal([]byte(row.Payment), &d.Payment)
+	if err != nil {
+		return d, errors.Wrap(err, "unmarshal payment failed")
+	}
+	return d, nil
+}
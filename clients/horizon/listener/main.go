@@ -0,0 +1,234 @@
+// Package listener watches an account for incoming payments and hands each of
+// them to a Handler, such as one that credits a customer, exactly once.
+//
+// A Listener persists its progress in the tables described by DBSchema: the
+// cursor of its payment stream, and every incoming payment it has seen along
+// with the state of its delivery.  A payment is recorded before the cursor
+// moves past it, so that a listener restarted after a crash resumes from
+// where it stopped without skipping or repeating a payment.
+//
+// A Handler is called within the database transaction that marks its payment
+// delivered, so changes it makes through that transaction are committed if
+// and only if the payment is marked delivered.  A Handler with effects outside
+// the database, such as the one returned by Webhook, may see a payment again
+// if the listener crashes after the handler returns but before the
+// transaction commits, and should treat the payment ID as an idempotency key.
+package listener
+
+import (
+	"time"
+
+	"github.com/stellar/go/clients/horizon"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+	"golang.org/x/net/context"
+)
+
+const (
+	// DefaultMaxAttempts is the number of times a listener tries to deliver
+	// a payment before giving up on it.
+	DefaultMaxAttempts = 10
+
+	// DefaultRetryDelay is how long a listener waits before the first retry
+	// of a failed delivery.  The delay doubles with each further attempt.
+	DefaultRetryDelay = time.Second
+
+	// MaxRetryDelay is the longest a listener waits between attempts to
+	// deliver a payment.
+	MaxRetryDelay = time.Hour
+)
+
+// DBSchema is the sql needed to create the tables used by Listener.
+const DBSchema = `
+CREATE TABLE listener_cursors (
+  account text NOT NULL PRIMARY KEY,
+  paging_token text NOT NULL
+);
+
+CREATE TABLE listener_payments (
+  id text NOT NULL PRIMARY KEY,
+  account text NOT NULL,
+  paging_token bigint NOT NULL,
+  payment text NOT NULL,
+  status text NOT NULL,
+  attempts integer NOT NULL,
+  last_error text NOT NULL,
+  next_attempt_at bigint NOT NULL
+);
+
+CREATE INDEX listener_payments_by_status ON listener_payments (account, status, paging_token);
+`
+
+// Status is the state of the delivery of a payment.
+type Status string
+
+const (
+	// StatusPending payments are waiting to be delivered, either for the
+	// first time or after a failed attempt.
+	StatusPending Status = "pending"
+
+	// StatusDelivered payments were handled successfully.
+	StatusDelivered Status = "delivered"
+
+	// StatusDead payments failed to be delivered MaxAttempts times, and are
+	// not tried again unless they are requeued.
+	StatusDead Status = "dead"
+)
+
+// Handler is a function that is called with each incoming payment.  `tx` is
+// the database transaction that marks the payment delivered, which is
+// committed if and only if the handler returns nil.  Returning an error
+// schedules another attempt to deliver the payment.
+type Handler func(ctx context.Context, tx *db.Session, payment horizon.Payment) error
+
+// Listener streams the payments of an account and delivers each incoming
+// one to its Handler.  Only one listener should run for an account at a time.
+type Listener struct {
+	// Client is used to stream payments and load their memos.
+	Client horizon.ClientInterface
+
+	// Session is the database holding the tables described by DBSchema.
+	Session *db.Session
+
+	// Account is the account whose incoming payments are delivered.
+	Account string
+
+	// Cursor is the position in the account's payments from which the
+	// listener starts the first time it runs, such as "now".  Later runs
+	// resume from the last payment recorded.  Defaults to the account's
+	// first payment.
+	Cursor horizon.Cursor
+
+	// Handler is called with each incoming payment.
+	Handler Handler
+
+	// MaxAttempts is the number of times the listener tries to deliver a
+	// payment before marking it dead.  Defaults to DefaultMaxAttempts.
+	MaxAttempts int
+
+	// RetryDelay is how long the listener waits before retrying a failed
+	// delivery for the first time.  Defaults to DefaultRetryDelay.
+	RetryDelay time.Duration
+}
+
+// Delivery is an incoming payment and the state of its delivery.
+type Delivery struct {
+	Payment   horizon.Payment
+	Status    Status
+	Attempts  int
+	LastError string
+}
+
+// Run streams the account's payments, recording each incoming payment and
+// delivering it to the handler, until `ctx` is done or an error other than
+// a handler error occurs.  Payments left pending by an earlier run are
+// delivered first.
+func (l *Listener) Run(ctx context.Context) error {
+	cursor, err := l.loadCursor()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	wake := make(chan struct{}, 1)
+	delivered := make(chan error, 1)
+	go func() {
+		err := l.deliverLoop(ctx, wake)
+		cancel()
+		delivered <- err
+	}()
+
+	err = l.Client.StreamPayments(ctx, l.Account, cursor, func(op horizon.Operation) error {
+		err := l.record(ctx, op)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	cancel()
+
+	if derr := <-delivered; derr != nil {
+		return derr
+	}
+	return errors.Wrap(err, "stream payments failed")
+}
+
+// deliverLoop delivers pending payments each time it is woken, and as their
+// retries fall due, until `ctx` is done.
+func (l *Listener) deliverLoop(ctx context.Context, wake <-chan struct{}) error {
+	for {
+		err := l.deliverPending(ctx)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-wake:
+		case <-time.After(l.retryDelay()):
+		}
+	}
+}
+
+// incoming returns the payment `op` made to `account`, if it is one.  Path
+// payments and account creations are returned as payments of the amount
+// received.
+func incoming(op horizon.Operation, account string) (horizon.Payment, bool) {
+	var payment horizon.Payment
+	switch op := op.(type) {
+	case *horizon.Payment:
+		payment = *op
+	case *horizon.PathPayment:
+		payment.BaseOperation = op.BaseOperation
+		payment.AssetType = op.AssetType
+		payment.AssetCode = op.AssetCode
+		payment.AssetIssuer = op.AssetIssuer
+		payment.From = op.From
+		payment.To = op.To
+		payment.Amount = op.Amount
+	case *horizon.CreateAccount:
+		payment.BaseOperation = op.BaseOperation
+		payment.AssetType = "native"
+		payment.From = op.Funder
+		payment.To = op.Account
+		payment.Amount = op.StartingBalance
+	default:
+		return payment, false
+	}
+	return payment, payment.To == account
+}
+
+func (l *Listener) maxAttempts() int {
+	if l.MaxAttempts <= 0 {
+		return DefaultMaxAttempts
+	}
+	return l.MaxAttempts
+}
+
+func (l *Listener) retryDelay() time.Duration {
+	if l.RetryDelay <= 0 {
+		return DefaultRetryDelay
+	}
+	return l.RetryDelay
+}
+
+// backoff returns the delay before the next attempt to deliver a payment
+// that failed `attempts` times.
+func (l *Listener) backoff(attempts int) time.Duration {
+	delay := l.retryDelay()
+	for i := 1; i < attempts && delay < MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > MaxRetryDelay {
+		delay = MaxRetryDelay
+	}
+	return delay
+}
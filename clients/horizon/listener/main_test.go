@@ -0,0 +1,368 @@
+package listener
+
+import (
+	"net/http"
+	stdtest "net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/build"
+	"github.com/stellar/go/clients/horizon"
+	"github.com/stellar/go/clients/horizon/horizontest"
+	"github.com/stellar/go/exp/simulator"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/db/dbtest"
+	"github.com/stellar/go/support/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+const creditsSchema = `
+CREATE TABLE credits (
+  id text NOT NULL PRIMARY KEY,
+  amount text NOT NULL,
+  memo text NOT NULL
+);
+`
+
+type creditRow struct {
+	ID     string `db:"id"`
+	Amount string `db:"amount"`
+	Memo   string `db:"memo"`
+}
+
+// testEnv is a fake horizon holding a funded root account, and a database
+// holding the listener's tables and a table of customer credits.
+type testEnv struct {
+	t       *testing.T
+	server  *horizontest.Server
+	client  *horizon.Client
+	root    *keypair.Full
+	dest    *keypair.Full
+	session *db.Session
+	close   func()
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	root, err := keypair.Random()
+	require.NoError(t, err)
+	dest, err := keypair.Random()
+	require.NoError(t, err)
+
+	entry, err := simulator.AccountEntry(root.Address(), 10000000000)
+	require.NoError(t, err)
+	server, err := horizontest.NewServer(network.TestNetworkPassphrase, entry)
+	require.NoError(t, err)
+
+	tdb := dbtest.Sqlite(t).Load(DBSchema).Load(creditsSchema)
+	session := &db.Session{DB: tdb.Open()}
+	// sqlite allows one writer at a time
+	session.DB.SetMaxOpenConns(1)
+
+	return &testEnv{
+		t:       t,
+		server:  server,
+		client:  server.Client(),
+		root:    root,
+		dest:    dest,
+		session: session,
+		close: func() {
+			server.Close()
+			session.DB.Close()
+			tdb.Close()
+		},
+	}
+}
+
+// submit submits a transaction from `source` with memo `memo`, made of `ops`.
+func (env *testEnv) submit(source *keypair.Full, memo string, ops ...build.TransactionMutator) {
+	muts := []build.TransactionMutator{
+		build.SourceAccount{AddressOrSeed: source.Address()},
+		build.AutoSequence{SequenceProvider: env.client},
+		build.TestNetwork,
+		build.MemoText{Value: memo},
+	}
+	tx := build.Transaction(append(muts, ops...)...)
+	require.NoError(env.t, tx.Err)
+
+	txe := tx.Sign(source.Seed())
+	b64, err := txe.Base64()
+	require.NoError(env.t, err)
+	_, err = env.client.SubmitTransaction(b64)
+	require.NoError(env.t, err)
+}
+
+// fund creates the destination account with 10 lumens.
+func (env *testEnv) fund(memo string) {
+	env.submit(env.root, memo, build.CreateAccount(
+		build.Destination{AddressOrSeed: env.dest.Address()},
+		build.NativeAmount{Amount: "10"},
+	))
+}
+
+// payDest pays `amount` lumens to the destination account.
+func (env *testEnv) payDest(memo, amount string) {
+	env.submit(env.root, memo, build.Payment(
+		build.Destination{AddressOrSeed: env.dest.Address()},
+		build.NativeAmount{Amount: amount},
+	))
+}
+
+func (env *testEnv) credits() []creditRow {
+	var credits []creditRow
+	err := env.session.SelectRaw(&credits, "SELECT id, amount, memo FROM credits ORDER BY length(id), id")
+	require.NoError(env.t, err)
+	return credits
+}
+
+// start runs `l` until the returned function is called, which fails the test
+// if Run returned an error.
+func (env *testEnv) start(l *Listener) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- l.Run(ctx)
+	}()
+
+	return func() {
+		cancel()
+		select {
+		case err := <-done:
+			require.NoError(env.t, err)
+		case <-time.After(5 * time.Second):
+			env.t.Fatal("listener did not stop")
+		}
+	}
+}
+
+// waitFor waits for `cond` to hold, failing the test if it does not within a
+// few seconds.
+func waitFor(t *testing.T, cond func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// credit is a Handler that credits each payment within the listener's
+// transaction, failing the first attempt of each payment in `flaky`.
+func credit(flaky map[string]bool) Handler {
+	var lock sync.Mutex
+	failed := map[string]bool{}
+
+	return func(ctx context.Context, tx *db.Session, payment horizon.Payment) error {
+		_, err := tx.ExecRaw(
+			"INSERT INTO credits (id, amount, memo) VALUES (?, ?, ?)",
+			payment.ID, payment.Amount, payment.Memo.Value,
+		)
+		if err != nil {
+			return err
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+		if flaky[payment.Memo.Value] && !failed[payment.ID] {
+			failed[payment.ID] = true
+			return errors.New("flaky")
+		}
+		return nil
+	}
+}
+
+func TestListener(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.close()
+
+	newListener := func() *Listener {
+		return &Listener{
+			Client:     env.client,
+			Session:    env.session,
+			Account:    env.dest.Address(),
+			Handler:    credit(map[string]bool{"flaky": true}),
+			RetryDelay: 10 * time.Millisecond,
+		}
+	}
+
+	stop := env.start(newListener())
+	env.fund("welcome")
+	// the listener ignores payments it did not receive
+	env.submit(env.dest, "outgoing", build.Payment(
+		build.Destination{AddressOrSeed: env.root.Address()},
+		build.NativeAmount{Amount: "1"},
+	))
+	env.payDest("flaky", "5")
+	waitFor(t, func() bool { return len(env.credits()) == 2 })
+	stop()
+
+	credits := env.credits()
+	assert.Equal(t, "10.0000000", credits[0].Amount)
+	assert.Equal(t, "welcome", credits[0].Memo)
+	assert.Equal(t, "5.0000000", credits[1].Amount)
+	assert.Equal(t, "flaky", credits[1].Memo)
+
+	var attempts int
+	err := env.session.GetRaw(&attempts, "SELECT attempts FROM listener_payments WHERE id = ?", credits[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+
+	// a restarted listener resumes after the payments it has seen
+	env.payDest("while stopped", "3")
+	stop = env.start(newListener())
+	waitFor(t, func() bool { return len(env.credits()) == 3 })
+	env.payDest("again", "2")
+	waitFor(t, func() bool { return len(env.credits()) == 4 })
+	stop()
+
+	counts := map[string]int{}
+	for _, c := range env.credits() {
+		counts[c.Memo]++
+	}
+	assert.Equal(t, map[string]int{"welcome": 1, "flaky": 1, "while stopped": 1, "again": 1}, counts)
+}
+
+func TestListener_Recovery(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.close()
+	env.fund("welcome")
+
+	payments, err := env.client.LoadPayments(horizon.ForAccount(env.dest.Address()))
+	require.NoError(t, err)
+	require.Len(t, payments.Embedded.Records, 1)
+
+	// a listener that crashed after recording a payment, before delivering it
+	l := &Listener{
+		Client:  env.client,
+		Session: env.session,
+		Account: env.dest.Address(),
+		Handler: credit(nil),
+	}
+	err = l.record(context.Background(), payments.Embedded.Records[0])
+	require.NoError(t, err)
+	assert.Empty(t, env.credits())
+
+	stop := env.start(l)
+	waitFor(t, func() bool { return len(env.credits()) == 1 })
+	stop()
+	assert.Equal(t, "welcome", env.credits()[0].Memo)
+}
+
+func TestListener_Dead(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.close()
+
+	var lock sync.Mutex
+	var keys []string
+	healthy := false
+	hook := stdtest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer hook.Close()
+
+	l := &Listener{
+		Client:      env.client,
+		Session:     env.session,
+		Account:     env.dest.Address(),
+		Handler:     Webhook(hook.URL, nil),
+		MaxAttempts: 3,
+		RetryDelay:  time.Millisecond,
+	}
+	stop := env.start(l)
+	env.fund("welcome")
+
+	var dead []Delivery
+	waitFor(t, func() bool {
+		var err error
+		dead, err = l.Dead()
+		require.NoError(t, err)
+		return len(dead) == 1
+	})
+	assert.Equal(t, 3, dead[0].Attempts)
+	assert.Equal(t, StatusDead, dead[0].Status)
+	assert.Equal(t, "webhook responded with status 503", dead[0].LastError)
+	assert.Equal(t, "welcome", dead[0].Payment.Memo.Value)
+
+	lock.Lock()
+	assert.Equal(t, []string{dead[0].Payment.ID, dead[0].Payment.ID, dead[0].Payment.ID}, keys)
+	healthy = true
+	lock.Unlock()
+
+	id := dead[0].Payment.ID
+	err := l.Requeue(id)
+	require.NoError(t, err)
+	waitFor(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(keys) == 4
+	})
+	stop()
+
+	dead, err = l.Dead()
+	require.NoError(t, err)
+	assert.Empty(t, dead)
+
+	// only dead payments can be requeued
+	err = l.Requeue(id)
+	assert.Error(t, err)
+}
+
+func TestListener_Shutdown(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.close()
+
+	running := make(chan struct{}, 1)
+	l := &Listener{
+		Client:      env.client,
+		Session:     env.session,
+		Account:     env.dest.Address(),
+		MaxAttempts: 1,
+		Handler: func(ctx context.Context, tx *db.Session, payment horizon.Payment) error {
+			running <- struct{}{}
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	stop := env.start(l)
+	env.fund("welcome")
+
+	select {
+	case <-running:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler was not called")
+	}
+	stop()
+
+	// the delivery interrupted by the shutdown is not counted as an attempt
+	var row struct {
+		Status   string `db:"status"`
+		Attempts int    `db:"attempts"`
+	}
+	err := env.session.GetRaw(&row, "SELECT status, attempts FROM listener_payments")
+	require.NoError(t, err)
+	assert.Equal(t, string(StatusPending), row.Status)
+	assert.Equal(t, 0, row.Attempts)
+
+	l.Handler = credit(nil)
+	stop = env.start(l)
+	waitFor(t, func() bool { return len(env.credits()) == 1 })
+	stop()
+}
+
+func TestListener_backoff(t *testing.T) {
+	l := &Listener{RetryDelay: time.Second}
+	assert.Equal(t, time.Second, l.backoff(1))
+	assert.Equal(t, 4*time.Second, l.backoff(3))
+	assert.Equal(t, MaxRetryDelay, l.backoff(100))
+	assert.Equal(t, DefaultRetryDelay, (&Listener{}).backoff(1))
+}
@@ -0,0 +1,51 @@
+package listener
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/stellar/go/clients/horizon"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+	"golang.org/x/net/context"
+)
+
+// Webhook returns a Handler that POSTs each payment as JSON to `url` with
+// `client`, or http.DefaultClient if `client` is nil.  The payment ID is sent
+// in the Idempotency-Key header, so that the receiver can ignore a payment it
+// has already processed.  Any response other than a 2xx is a failed
+// delivery.
+func Webhook(url string, client *http.Client) Handler {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return func(ctx context.Context, tx *db.Session, payment horizon.Payment) error {
+		body, err := json.Marshal(payment)
+		if err != nil {
+			return errors.Wrap(err, "marshal payment failed")
+		}
+
+		req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+		if err != nil {
+			return errors.Wrap(err, "new request failed")
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", payment.ID)
+
+		resp, err := client.Do(req.WithContext(ctx))
+		if err != nil {
+			return errors.Wrap(err, "post payment failed")
+		}
+		defer resp.Body.Close()
+		io.Copy(ioutil.Discard, resp.Body)
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return errors.Errorf("webhook responded with status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
@@ -9,9 +9,12 @@ package horizon
 import (
 	"net/http"
 	"net/url"
+	"sync"
+	"time"
 
 	"github.com/stellar/go/build"
 	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
 	"golang.org/x/net/context"
 )
 
@@ -45,6 +48,24 @@ const (
 	OrderDesc Order = "desc"
 )
 
+// AssetCode represents `asset_code` param in queries
+type AssetCode string
+
+// AssetIssuer represents `asset_issuer` param in queries
+type AssetIssuer string
+
+// ForAccount is a param that restricts a collection, such as transactions or
+// effects, to the records of one account.
+type ForAccount string
+
+// ForLedger is a param that restricts a collection, such as transactions or
+// operations, to the records of one ledger.
+type ForLedger int32
+
+// ForTransaction is a param that restricts a collection, such as operations
+// or payments, to the records of one transaction.
+type ForTransaction string
+
 var (
 	// ErrTransactionNotFailed is the error returned from a call to ResultCodes()
 	// against a `Problem` value that is not of type "transaction_failed".
@@ -68,17 +89,84 @@ type Client struct {
 
 	// HTTP client to make requests with
 	HTTP HTTP
+
+	// Middleware wraps the sending of every request the client makes, in
+	// order, so that the first is the outermost.  See Cache, Metrics,
+	// RequestID and UserAgent.
+	Middleware []Middleware
+
+	// StreamObserver, if set, is notified when streams connect, reconnect or
+	// receive heartbeats
+	StreamObserver StreamObserver
+
+	// WaitForRateLimit, if set, makes the client wait for horizon's rate limit
+	// to reset, rather than fail, when it has no requests remaining
+	WaitForRateLimit bool
+
+	rateLimitLock sync.Mutex
+	rateLimit     RateLimit
 }
 
 type ClientInterface interface {
+	HomeDomainForAccount(aid string) (string, error)
 	LoadAccount(accountID string) (Account, error)
 	LoadAccountOffers(accountID string, params ...interface{}) (offers OffersPage, err error)
 	LoadMemo(p *Payment) error
 	LoadOrderBook(selling Asset, buying Asset) (orderBook OrderBookSummary, err error)
+	LoadPaths(sourceAccount, destinationAccount string, destinationAsset Asset, destinationAmount string) (PathsPage, error)
 	StreamLedgers(ctx context.Context, cursor *Cursor, handler LedgerHandler) error
 	StreamPayments(ctx context.Context, accountID string, cursor *Cursor, handler PaymentHandler) error
 	StreamTransactions(ctx context.Context, accountID string, cursor *Cursor, handler TransactionHandler) error
+	StreamOperations(ctx context.Context, cursor *Cursor, handler OperationHandler, params ...interface{}) error
+	StreamEffects(ctx context.Context, cursor *Cursor, handler EffectHandler, params ...interface{}) error
+	StreamTrades(ctx context.Context, base, counter Asset, cursor *Cursor, handler TradeHandler) error
+	StreamOffers(ctx context.Context, accountID string, cursor *Cursor, handler OfferHandler) error
+	StreamOrderBook(ctx context.Context, selling, buying Asset, handler OrderBookHandler) error
+	SequenceForAccount(accountID string) (xdr.SequenceNumber, error)
 	SubmitTransaction(txeBase64 string) (TransactionSuccess, error)
+
+	HomeDomainForAccountContext(ctx context.Context, aid string) (string, error)
+	LoadAccountContext(ctx context.Context, accountID string) (Account, error)
+	LoadAccountOffersContext(ctx context.Context, accountID string, params ...interface{}) (offers OffersPage, err error)
+	LoadMemoContext(ctx context.Context, p *Payment) error
+	LoadOrderBookContext(ctx context.Context, selling Asset, buying Asset) (orderBook OrderBookSummary, err error)
+	LoadPathsContext(ctx context.Context, sourceAccount, destinationAccount string, destinationAsset Asset, destinationAmount string) (PathsPage, error)
+	SequenceForAccountContext(ctx context.Context, accountID string) (xdr.SequenceNumber, error)
+	SubmitTransactionContext(ctx context.Context, txeBase64 string) (TransactionSuccess, error)
+	SubmitTransactionWithRetry(ctx context.Context, txeBase64 string, opts SubmitOptions) (SubmitResult, error)
+
+	Root() (Root, error)
+	LoadTransaction(hash string) (Transaction, error)
+	LoadTransactions(params ...interface{}) (TransactionsPage, error)
+	LoadOperations(params ...interface{}) (OperationsPage, error)
+	LoadEffects(params ...interface{}) (EffectsPage, error)
+	LoadPayments(params ...interface{}) (PaymentsPage, error)
+	LoadLedger(sequence int32) (Ledger, error)
+	LoadLedgers(params ...interface{}) (LedgersPage, error)
+	LoadAssets(params ...interface{}) (AssetsPage, error)
+	LoadTrades(base, counter Asset, params ...interface{}) (TradesPage, error)
+	LoadTradeAggregations(base, counter Asset, start, end time.Time, resolution time.Duration, params ...interface{}) (TradeAggregationsPage, error)
+	RootContext(ctx context.Context) (Root, error)
+	LoadTransactionContext(ctx context.Context, hash string) (Transaction, error)
+	LoadTransactionsContext(ctx context.Context, params ...interface{}) (TransactionsPage, error)
+	LoadOperationsContext(ctx context.Context, params ...interface{}) (OperationsPage, error)
+	LoadEffectsContext(ctx context.Context, params ...interface{}) (EffectsPage, error)
+	LoadPaymentsContext(ctx context.Context, params ...interface{}) (PaymentsPage, error)
+	LoadLedgerContext(ctx context.Context, sequence int32) (Ledger, error)
+	LoadLedgersContext(ctx context.Context, params ...interface{}) (LedgersPage, error)
+	LoadAssetsContext(ctx context.Context, params ...interface{}) (AssetsPage, error)
+	LoadTradesContext(ctx context.Context, base, counter Asset, params ...interface{}) (TradesPage, error)
+	LoadTradeAggregationsContext(ctx context.Context, base, counter Asset, start, end time.Time, resolution time.Duration, params ...interface{}) (TradeAggregationsPage, error)
+
+	AccountOffers(ctx context.Context, accountID string, params ...interface{}) *Iterator
+	Transactions(ctx context.Context, params ...interface{}) *Iterator
+	Operations(ctx context.Context, params ...interface{}) *Iterator
+	Effects(ctx context.Context, params ...interface{}) *Iterator
+	Payments(ctx context.Context, params ...interface{}) *Iterator
+	Ledgers(ctx context.Context, params ...interface{}) *Iterator
+	Assets(ctx context.Context, params ...interface{}) *Iterator
+	Trades(ctx context.Context, base, counter Asset, params ...interface{}) *Iterator
+	TradeAggregations(ctx context.Context, base, counter Asset, start, end time.Time, resolution time.Duration, params ...interface{}) *Iterator
 }
 
 // Error struct contains the problem returned by Horizon
@@ -94,14 +182,37 @@ type HTTP interface {
 	PostForm(url string, data url.Values) (resp *http.Response, err error)
 }
 
-// LedgerHandler is a function that is called when a new ledger is received
-type LedgerHandler func(Ledger)
+// LedgerHandler is a function that is called when a new ledger is received.
+// Returning an error stops the stream.
+type LedgerHandler func(Ledger) error
+
+// PaymentHandler is a function that is called when a new payment is received.
+// Returning an error stops the stream.
+type PaymentHandler func(Operation) error
+
+// TransactionHandler is a function that is called when a new transaction is
+// received. Returning an error stops the stream.
+type TransactionHandler func(Transaction) error
+
+// OperationHandler is a function that is called when a new operation is
+// received. Returning an error stops the stream.
+type OperationHandler func(Operation) error
+
+// EffectHandler is a function that is called when a new effect is received.
+// Returning an error stops the stream.
+type EffectHandler func(Effect) error
+
+// TradeHandler is a function that is called when a new trade is received.
+// Returning an error stops the stream.
+type TradeHandler func(Trade) error
 
-// PaymentHandler is a function that is called when a new payment is received
-type PaymentHandler func(Payment)
+// OfferHandler is a function that is called when an offer is created or
+// updated. Returning an error stops the stream.
+type OfferHandler func(Offer) error
 
-// TransactionHandler is a function that is called when a new transaction is received
-type TransactionHandler func(Transaction)
+// OrderBookHandler is a function that is called when the order book changes.
+// Returning an error stops the stream.
+type OrderBookHandler func(OrderBookSummary) error
 
 // ensure that the horizon client can be used as a SequenceProvider
 var _ build.SequenceProvider = &Client{}
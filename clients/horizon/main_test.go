@@ -2,6 +2,9 @@ package horizon
 
 import (
 	"fmt"
+	"net/http"
+	stdtest "net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -24,8 +27,9 @@ func ExampleClient_StreamLedgers() {
 		cancel()
 	}()
 
-	err := client.StreamLedgers(ctx, &cursor, func(l Ledger) {
+	err := client.StreamLedgers(ctx, &cursor, func(l Ledger) error {
 		fmt.Println(l.Sequence)
+		return nil
 	})
 
 	if err != nil {
@@ -185,6 +189,167 @@ var _ = Describe("Horizon", func() {
 		})
 	})
 
+	Describe("AccountOffers", func() {
+		It("follows next links until an empty page", func() {
+			hmock.On(
+				"GET",
+				"https://localhost/accounts/GC2BQYBXFOVPRDH35D5HT2AFVCDGXJM5YVTAF5THFSAISYOWAJQKRESK/offers?cursor=a&limit=2&order=desc",
+			).ReturnString(200, offersPage("https://localhost/page2", 161, 162))
+			hmock.On("GET", "https://localhost/page2").
+				ReturnString(200, offersPage("https://localhost/page3", 163))
+			hmock.On("GET", "https://localhost/page3").
+				ReturnString(200, offersPage("https://localhost/page4"))
+
+			it := client.AccountOffers(
+				context.Background(),
+				"GC2BQYBXFOVPRDH35D5HT2AFVCDGXJM5YVTAF5THFSAISYOWAJQKRESK",
+				Cursor("a"), Limit(2), OrderDesc,
+			)
+
+			var ids []int64
+			for it.Next() {
+				var offer Offer
+				Expect(it.Scan(&offer)).To(Succeed())
+				ids = append(ids, offer.ID)
+			}
+
+			Expect(it.Err()).To(BeNil())
+			Expect(ids).To(Equal([]int64{161, 162, 163}))
+			Expect(it.Next()).To(BeFalse())
+		})
+
+		It("stops when a page links to itself", func() {
+			hmock.On("GET", "https://localhost/page1").
+				ReturnString(200, offersPage("https://localhost/page1", 161))
+
+			it := client.AccountOffers(context.Background(), "GC2BQYBXFOVPRDH35D5HT2AFVCDGXJM5YVTAF5THFSAISYOWAJQKRESK", At("https://localhost/page1"))
+			Expect(it.Next()).To(BeTrue())
+			Expect(it.Next()).To(BeFalse())
+			Expect(it.Err()).To(BeNil())
+		})
+
+		It("stops on errors", func() {
+			hmock.On(
+				"GET",
+				"https://localhost/accounts/GC2BQYBXFOVPRDH35D5HT2AFVCDGXJM5YVTAF5THFSAISYOWAJQKRESK/offers",
+			).ReturnString(200, offersPage("https://localhost/page2", 161))
+			hmock.On("GET", "https://localhost/page2").
+				ReturnString(404, notFoundResponse)
+
+			it := client.AccountOffers(context.Background(), "GC2BQYBXFOVPRDH35D5HT2AFVCDGXJM5YVTAF5THFSAISYOWAJQKRESK")
+			Expect(it.Next()).To(BeTrue())
+			Expect(it.Next()).To(BeFalse())
+
+			horizonError, ok := it.Err().(*Error)
+			Expect(ok).To(BeTrue())
+			Expect(horizonError.Problem.Title).To(Equal("Resource Missing"))
+
+			var offer Offer
+			Expect(it.Scan(&offer)).NotTo(Succeed())
+		})
+
+		It("rejects unknown params", func() {
+			it := client.AccountOffers(context.Background(), "GC2BQYBXFOVPRDH35D5HT2AFVCDGXJM5YVTAF5THFSAISYOWAJQKRESK", 12)
+			Expect(it.Next()).To(BeFalse())
+			Expect(it.Err()).NotTo(BeNil())
+		})
+	})
+
+	Describe("Payments", func() {
+		It("follows next links until an empty page", func() {
+			hmock.On(
+				"GET",
+				"https://localhost/accounts/GC2BQYBXFOVPRDH35D5HT2AFVCDGXJM5YVTAF5THFSAISYOWAJQKRESK/payments?limit=2",
+			).ReturnString(200, recordsPage(
+				"https://localhost/page2",
+				`{"id": "1", "paging_token": "1", "type": "create_account", "starting_balance": "10.0000000"}`,
+				`{"id": "2", "paging_token": "2", "type": "payment", "amount": "5.0000000"}`,
+			))
+			hmock.On("GET", "https://localhost/page2").ReturnString(200, recordsPage(
+				"https://localhost/page3",
+				`{"id": "3", "paging_token": "3", "type": "payment", "amount": "2.0000000"}`,
+			))
+			hmock.On("GET", "https://localhost/page3").
+				ReturnString(200, recordsPage("https://localhost/page4"))
+
+			it := client.Payments(
+				context.Background(),
+				ForAccount("GC2BQYBXFOVPRDH35D5HT2AFVCDGXJM5YVTAF5THFSAISYOWAJQKRESK"),
+				Limit(2),
+			)
+
+			var ids, types []string
+			for it.Next() {
+				op, err := it.Operation()
+				Expect(err).To(BeNil())
+				ids = append(ids, op.Base().ID)
+				types = append(types, op.Base().Type)
+			}
+
+			Expect(it.Err()).To(BeNil())
+			Expect(ids).To(Equal([]string{"1", "2", "3"}))
+			Expect(types).To(Equal([]string{"create_account", "payment", "payment"}))
+		})
+	})
+
+	Describe("Trades", func() {
+		It("follows next links until an empty page", func() {
+			hmock.On("GET", "https://localhost/trades?base_asset_code=&base_asset_issuer=&base_asset_type=native&counter_asset_code=USD&counter_asset_issuer=GDI73WJ4SX7LOG3XZDJC3KCK6ED6E5NBYK2JUBQSPBCNNWEG3ZN7T75U&counter_asset_type=credit_alphanum4&limit=1").
+				ReturnString(200, recordsPage("https://localhost/page2", `{"id": "a", "base_amount": "100.0000000"}`))
+			hmock.On("GET", "https://localhost/page2").
+				ReturnString(200, recordsPage("https://localhost/page3", `{"id": "b", "base_amount": "50.0000000"}`))
+			hmock.On("GET", "https://localhost/page3").
+				ReturnString(200, recordsPage("https://localhost/page4"))
+
+			it := client.Trades(
+				context.Background(),
+				Asset{Type: "native"},
+				Asset{Type: "credit_alphanum4", Code: "USD", Issuer: "GDI73WJ4SX7LOG3XZDJC3KCK6ED6E5NBYK2JUBQSPBCNNWEG3ZN7T75U"},
+				Limit(1),
+			)
+
+			var amounts []string
+			for it.Next() {
+				var trade Trade
+				Expect(it.Scan(&trade)).To(Succeed())
+				amounts = append(amounts, trade.BaseAmount)
+			}
+
+			Expect(it.Err()).To(BeNil())
+			Expect(amounts).To(Equal([]string{"100.0000000", "50.0000000"}))
+		})
+	})
+
+	Describe("TradeAggregations", func() {
+		It("follows next links until an empty page", func() {
+			hmock.On("GET", "https://localhost/trade_aggregations?base_asset_code=&base_asset_issuer=&base_asset_type=native&counter_asset_code=USD&counter_asset_issuer=GDI73WJ4SX7LOG3XZDJC3KCK6ED6E5NBYK2JUBQSPBCNNWEG3ZN7T75U&counter_asset_type=credit_alphanum4&end_time=1512086400000&resolution=3600000&start_time=1512000000000").
+				ReturnString(200, recordsPage("https://localhost/page2", `{"timestamp": 1512000000000, "trade_count": 3}`))
+			hmock.On("GET", "https://localhost/page2").
+				ReturnString(200, recordsPage("https://localhost/page3", `{"timestamp": 1512003600000, "trade_count": 1}`))
+			hmock.On("GET", "https://localhost/page3").
+				ReturnString(200, recordsPage("https://localhost/page4"))
+
+			it := client.TradeAggregations(
+				context.Background(),
+				Asset{Type: "native"},
+				Asset{Type: "credit_alphanum4", Code: "USD", Issuer: "GDI73WJ4SX7LOG3XZDJC3KCK6ED6E5NBYK2JUBQSPBCNNWEG3ZN7T75U"},
+				time.Unix(1512000000, 0),
+				time.Unix(1512086400, 0),
+				time.Hour,
+			)
+
+			var counts []int64
+			for it.Next() {
+				var bucket TradeAggregation
+				Expect(it.Scan(&bucket)).To(Succeed())
+				counts = append(counts, bucket.TradeCount)
+			}
+
+			Expect(it.Err()).To(BeNil())
+			Expect(counts).To(Equal([]int64{3, 1}))
+		})
+	})
+
 	Describe("LoadOrderBook", func() {
 		It("success response", func() {
 			hmock.On(
@@ -240,6 +405,251 @@ var _ = Describe("Horizon", func() {
 		})
 	})
 
+	Describe("LoadPaths", func() {
+		It("success response", func() {
+			hmock.On("GET", "https://localhost/paths?destination_account=GAEDTJ4PPEFVW5XV2S7LUXBEHNQMX5Q2GM562RJGOQG7GVCE5H3HIB4V&destination_amount=20&destination_asset_code=EUR&destination_asset_issuer=GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN&destination_asset_type=credit_alphanum4&source_account=GARSFJNXJIHO6ULUBK3DBYKVSIZE7SC72S5DYBCHU7DKL22UXKVD7MXP").
+				ReturnString(200, pathsResponse)
+
+			page, err := client.LoadPaths(
+				"GARSFJNXJIHO6ULUBK3DBYKVSIZE7SC72S5DYBCHU7DKL22UXKVD7MXP",
+				"GAEDTJ4PPEFVW5XV2S7LUXBEHNQMX5Q2GM562RJGOQG7GVCE5H3HIB4V",
+				Asset{Type: "credit_alphanum4", Code: "EUR", Issuer: "GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN"},
+				"20",
+			)
+			Expect(err).To(BeNil())
+			Expect(page.Embedded.Records).To(HaveLen(2))
+
+			path := page.Embedded.Records[0]
+			Expect(path.SourceAsset()).To(Equal(Asset{Type: "native"}))
+			Expect(path.SourceAmount).To(Equal("30.0000000"))
+			Expect(path.DestinationAmount).To(Equal("20.0000000"))
+			Expect(path.DestinationAssetCode).To(Equal("EUR"))
+			Expect(path.Path).To(HaveLen(1))
+			Expect(path.Path[0].Code).To(Equal("USD"))
+		})
+	})
+
+	Describe("Root", func() {
+		It("success response", func() {
+			hmock.On("GET", "https://localhost").ReturnString(200, rootResponse)
+
+			root, err := client.Root()
+			Expect(err).To(BeNil())
+			Expect(root.HorizonVersion).To(Equal("snapshot-c5fb7d6"))
+			Expect(root.StellarCoreVersion).To(Equal("v9.1.0"))
+			Expect(root.HorizonSequence).To(Equal(int32(5837547)))
+			Expect(root.HistoryElderSequence).To(Equal(int32(1)))
+			Expect(root.CoreSequence).To(Equal(int32(5837548)))
+			Expect(root.NetworkPassphrase).To(Equal("Test SDF Network ; September 2015"))
+			Expect(root.ProtocolVersion).To(Equal(int32(9)))
+			Expect(root.Links.Friendbot.Href).To(Equal("https://horizon-testnet.stellar.org/friendbot{?addr}"))
+		})
+	})
+
+	Describe("LoadTransaction", func() {
+		var hash = "5131aed266a639a6eb4802a92fba310454e711ded830ed899745b9e777d7110c"
+
+		It("success response", func() {
+			hmock.On("GET", "https://localhost/transactions/"+hash).
+				ReturnString(200, transactionResponse)
+
+			tx, err := client.LoadTransaction(hash)
+			Expect(err).To(BeNil())
+			Expect(tx.Hash).To(Equal(hash))
+			Expect(tx.Ledger).To(Equal(int32(3553)))
+			Expect(tx.Account).To(Equal("GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"))
+			Expect(tx.OperationCount).To(Equal(int32(1)))
+			Expect(tx.Links.Operations.Href).To(Equal("https://horizon-testnet.stellar.org/transactions/" + hash + "/operations{?cursor,limit,order}"))
+		})
+
+		It("failure response", func() {
+			hmock.On("GET", "https://localhost/transactions/"+hash).
+				ReturnString(404, notFoundResponse)
+
+			_, err := client.LoadTransaction(hash)
+			Expect(err).NotTo(BeNil())
+			horizonError, ok := err.(*Error)
+			Expect(ok).To(BeTrue())
+			Expect(horizonError.Problem.Title).To(Equal("Resource Missing"))
+		})
+	})
+
+	Describe("LoadTransactions", func() {
+		It("loads all transactions", func() {
+			hmock.On("GET", "https://localhost/transactions?limit=1").
+				ReturnString(200, transactionsResponse)
+
+			page, err := client.LoadTransactions(Limit(1))
+			Expect(err).To(BeNil())
+			Expect(page.Embedded.Records).To(HaveLen(1))
+			Expect(page.Embedded.Records[0].Hash).To(Equal("5131aed266a639a6eb4802a92fba310454e711ded830ed899745b9e777d7110c"))
+			Expect(page.Links.Next.Href).To(Equal("https://horizon-testnet.stellar.org/transactions?order=asc&limit=1&cursor=15259984932098048"))
+		})
+
+		It("loads the transactions of an account", func() {
+			hmock.On("GET", "https://localhost/accounts/GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H/transactions?order=desc").
+				ReturnString(200, transactionsResponse)
+
+			page, err := client.LoadTransactions(ForAccount("GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"), OrderDesc)
+			Expect(err).To(BeNil())
+			Expect(page.Embedded.Records).To(HaveLen(1))
+		})
+
+		It("loads the transactions of a ledger", func() {
+			hmock.On("GET", "https://localhost/ledgers/3553/transactions").
+				ReturnString(200, transactionsResponse)
+
+			page, err := client.LoadTransactions(ForLedger(3553))
+			Expect(err).To(BeNil())
+			Expect(page.Embedded.Records).To(HaveLen(1))
+		})
+	})
+
+	Describe("LoadOperations", func() {
+		It("loads the operations of a transaction", func() {
+			hmock.On("GET", "https://localhost/transactions/5131aed266a639a6eb4802a92fba310454e711ded830ed899745b9e777d7110c/operations").
+				ReturnString(200, operationsResponse)
+
+			page, err := client.LoadOperations(ForTransaction("5131aed266a639a6eb4802a92fba310454e711ded830ed899745b9e777d7110c"))
+			Expect(err).To(BeNil())
+			Expect(page.Embedded.Records).To(HaveLen(1))
+
+			op, ok := page.Embedded.Records[0].(*CreateAccount)
+			Expect(ok).To(BeTrue())
+			Expect(op.ID).To(Equal("15259984932098049"))
+			Expect(op.Type).To(Equal("create_account"))
+			Expect(op.TypeI).To(Equal(int32(0)))
+			Expect(op.SourceAccount).To(Equal("GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"))
+			Expect(op.TransactionHash).To(Equal("5131aed266a639a6eb4802a92fba310454e711ded830ed899745b9e777d7110c"))
+			Expect(op.StartingBalance).To(Equal("10000.0000000"))
+			Expect(op.Account).To(Equal("GC2BQYBXFOVPRDH35D5HT2AFVCDGXJM5YVTAF5THFSAISYOWAJQKRESK"))
+		})
+	})
+
+	Describe("LoadEffects", func() {
+		It("loads the effects of an account", func() {
+			hmock.On("GET", "https://localhost/accounts/GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H/effects?cursor=now").
+				ReturnString(200, effectsResponse)
+
+			page, err := client.LoadEffects(ForAccount("GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"), Cursor("now"))
+			Expect(err).To(BeNil())
+			Expect(page.Embedded.Records).To(HaveLen(1))
+
+			effect, ok := page.Embedded.Records[0].(*AccountCreated)
+			Expect(ok).To(BeTrue())
+			Expect(effect.StartingBalance).To(Equal("10000.0000000"))
+			Expect(effect.ID).To(Equal("0015259984932098049-0000000001"))
+			Expect(effect.Type).To(Equal("account_created"))
+			Expect(effect.Account).To(Equal("GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"))
+		})
+	})
+
+	Describe("LoadPayments", func() {
+		It("loads the payments of a ledger", func() {
+			hmock.On("GET", "https://localhost/ledgers/3553/payments").
+				ReturnString(200, paymentsResponse)
+
+			page, err := client.LoadPayments(ForLedger(3553))
+			Expect(err).To(BeNil())
+			Expect(page.Embedded.Records).To(HaveLen(1))
+
+			payment, ok := page.Embedded.Records[0].(*Payment)
+			Expect(ok).To(BeTrue())
+			Expect(payment.Type).To(Equal("payment"))
+			Expect(payment.From).To(Equal("GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"))
+			Expect(payment.Amount).To(Equal("10.0000000"))
+			Expect(payment.AssetType).To(Equal("native"))
+		})
+	})
+
+	Describe("LoadLedger", func() {
+		It("success response", func() {
+			hmock.On("GET", "https://localhost/ledgers/3553").
+				ReturnString(200, ledgerResponse)
+
+			ledger, err := client.LoadLedger(3553)
+			Expect(err).To(BeNil())
+			Expect(ledger.Sequence).To(Equal(int32(3553)))
+			Expect(ledger.TransactionCount).To(Equal(int32(1)))
+			Expect(ledger.BaseReserve).To(Equal("10.0000000"))
+		})
+	})
+
+	Describe("LoadLedgers", func() {
+		It("success response", func() {
+			hmock.On("GET", "https://localhost/ledgers?cursor=15259984932093952&limit=1&order=asc").
+				ReturnString(200, ledgersResponse)
+
+			page, err := client.LoadLedgers(Cursor("15259984932093952"), Limit(1), OrderAsc)
+			Expect(err).To(BeNil())
+			Expect(page.Embedded.Records).To(HaveLen(1))
+			Expect(page.Embedded.Records[0].Sequence).To(Equal(int32(3553)))
+		})
+	})
+
+	Describe("LoadAssets", func() {
+		It("success response", func() {
+			hmock.On("GET", "https://localhost/assets?asset_code=USD&asset_issuer=GDI73WJ4SX7LOG3XZDJC3KCK6ED6E5NBYK2JUBQSPBCNNWEG3ZN7T75U").
+				ReturnString(200, assetsResponse)
+
+			page, err := client.LoadAssets(AssetCode("USD"), AssetIssuer("GDI73WJ4SX7LOG3XZDJC3KCK6ED6E5NBYK2JUBQSPBCNNWEG3ZN7T75U"))
+			Expect(err).To(BeNil())
+			Expect(page.Embedded.Records).To(HaveLen(1))
+
+			asset := page.Embedded.Records[0]
+			Expect(asset.Code).To(Equal("USD"))
+			Expect(asset.Issuer).To(Equal("GDI73WJ4SX7LOG3XZDJC3KCK6ED6E5NBYK2JUBQSPBCNNWEG3ZN7T75U"))
+			Expect(asset.Amount).To(Equal("1000.0000000"))
+			Expect(asset.NumAccounts).To(Equal(int32(12)))
+			Expect(asset.Flags.AuthRequired).To(BeTrue())
+		})
+	})
+
+	Describe("LoadTrades", func() {
+		It("success response", func() {
+			hmock.On("GET", "https://localhost/trades?base_asset_code=&base_asset_issuer=&base_asset_type=native&counter_asset_code=USD&counter_asset_issuer=GDI73WJ4SX7LOG3XZDJC3KCK6ED6E5NBYK2JUBQSPBCNNWEG3ZN7T75U&counter_asset_type=credit_alphanum4&limit=2").
+				ReturnString(200, tradesResponse)
+
+			page, err := client.LoadTrades(
+				Asset{Type: "native"},
+				Asset{Type: "credit_alphanum4", Code: "USD", Issuer: "GDI73WJ4SX7LOG3XZDJC3KCK6ED6E5NBYK2JUBQSPBCNNWEG3ZN7T75U"},
+				Limit(2),
+			)
+			Expect(err).To(BeNil())
+			Expect(page.Embedded.Records).To(HaveLen(1))
+
+			trade := page.Embedded.Records[0]
+			Expect(trade.BaseAmount).To(Equal("100.0000000"))
+			Expect(trade.CounterAmount).To(Equal("25.0000000"))
+			Expect(trade.CounterAssetCode).To(Equal("USD"))
+			Expect(trade.BaseIsSeller).To(BeTrue())
+			Expect(trade.Price).To(Equal(Price{N: 1, D: 4}))
+		})
+	})
+
+	Describe("LoadTradeAggregations", func() {
+		It("success response", func() {
+			hmock.On("GET", "https://localhost/trade_aggregations?base_asset_code=&base_asset_issuer=&base_asset_type=native&counter_asset_code=USD&counter_asset_issuer=GDI73WJ4SX7LOG3XZDJC3KCK6ED6E5NBYK2JUBQSPBCNNWEG3ZN7T75U&counter_asset_type=credit_alphanum4&end_time=1512086400000&resolution=3600000&start_time=1512000000000").
+				ReturnString(200, tradeAggregationsResponse)
+
+			page, err := client.LoadTradeAggregations(
+				Asset{Type: "native"},
+				Asset{Type: "credit_alphanum4", Code: "USD", Issuer: "GDI73WJ4SX7LOG3XZDJC3KCK6ED6E5NBYK2JUBQSPBCNNWEG3ZN7T75U"},
+				time.Unix(1512000000, 0),
+				time.Unix(1512086400, 0),
+				time.Hour,
+			)
+			Expect(err).To(BeNil())
+			Expect(page.Embedded.Records).To(HaveLen(1))
+
+			bucket := page.Embedded.Records[0]
+			Expect(bucket.Timestamp).To(Equal(int64(1512000000000)))
+			Expect(bucket.TradeCount).To(Equal(int64(3)))
+			Expect(bucket.BaseVolume).To(Equal("300.0000000"))
+			Expect(bucket.Average).To(Equal("0.2500000"))
+		})
+	})
+
 	Describe("SubmitTransaction", func() {
 		var tx = "AAAAADSMMRmQGDH6EJzkgi/7PoKhphMHyNGQgDp2tlS/dhGXAAAAZAAT3TUAAAAwAAAAAAAAAAAAAAABAAAAAAAAAAMAAAABSU5SAAAAAAA0jDEZkBgx+hCc5IIv+z6CoaYTB8jRkIA6drZUv3YRlwAAAAFVU0QAAAAAADSMMRmQGDH6EJzkgi/7PoKhphMHyNGQgDp2tlS/dhGXAAAAAAX14QAAAAAKAAAAAQAAAAAAAAAAAAAAAAAAAAG/dhGXAAAAQLuStfImg0OeeGAQmvLkJSZ1MPSkCzCYNbGqX5oYNuuOqZ5SmWhEsC7uOD9ha4V7KengiwNlc0oMNqBVo22S7gk="
 
@@ -278,8 +688,103 @@ var _ = Describe("Horizon", func() {
 			Expect(ok).To(BeFalse())
 		})
 	})
+
+	Describe("Context", func() {
+		var (
+			server   *stdtest.Server
+			released chan struct{}
+		)
+
+		BeforeEach(func() {
+			released = make(chan struct{})
+			server = stdtest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// block until the client gives up on the request
+				select {
+				case <-r.Context().Done():
+				case <-released:
+				}
+			}))
+			client = &Client{
+				URL:  server.URL,
+				HTTP: http.DefaultClient,
+			}
+		})
+
+		AfterEach(func() {
+			close(released)
+			server.Close()
+		})
+
+		It("uses the context of successful requests", func() {
+			client.URL = "https://localhost"
+			client.HTTP = hmock
+			hmock.On(
+				"GET",
+				"https://localhost/accounts/GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+			).ReturnString(200, accountResponse)
+
+			seq, err := client.SequenceForAccountContext(context.Background(), "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H")
+			Expect(err).To(BeNil())
+			Expect(seq).NotTo(BeZero())
+		})
+
+		It("propagates deadlines", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			_, err := client.LoadAccountContext(ctx, "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H")
+			Expect(err).NotTo(BeNil())
+			Expect(err.Error()).To(ContainSubstring("context deadline exceeded"))
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		})
+
+		It("cancels in-flight requests", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				cancel()
+			}()
+
+			_, err := client.SubmitTransactionContext(ctx, "AAAA")
+			Expect(err).NotTo(BeNil())
+			Expect(err.Error()).To(ContainSubstring("context canceled"))
+			_, ok := errors.Cause(err).(*Error)
+			Expect(ok).To(BeFalse())
+		})
+
+		It("does not make requests with a done context", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := client.LoadOrderBookContext(ctx, Asset{Type: "native"}, Asset{Type: "native"})
+			Expect(err).NotTo(BeNil())
+
+			_, err = client.LoadAccountOffersContext(ctx, "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H")
+			Expect(err).NotTo(BeNil())
+		})
+	})
 })
 
+// offersPage returns a page of offers with the provided ids that links to
+// `next`.
+func offersPage(next string, ids ...int64) string {
+	records := make([]string, len(ids))
+	for i, id := range ids {
+		records[i] = fmt.Sprintf(`{"id": %d, "paging_token": "%d"}`, id, id)
+	}
+
+	return recordsPage(next, records...)
+}
+
+func recordsPage(next string, records ...string) string {
+	return fmt.Sprintf(
+		`{"_links": {"next": {"href": %q}}, "_embedded": {"records": [%s]}}`,
+		next,
+		strings.Join(records, ","),
+	)
+}
+
 var accountResponse = `{
   "_links": {
     "self": {
@@ -752,6 +1257,44 @@ var orderBookResponse = `{
   }
 }`
 
+var pathsResponse = `{
+  "_embedded": {
+    "records": [
+      {
+        "destination_amount": "20.0000000",
+        "destination_asset_code": "EUR",
+        "destination_asset_issuer": "GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN",
+        "destination_asset_type": "credit_alphanum4",
+        "path": [
+          {
+            "asset_code": "USD",
+            "asset_issuer": "GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN",
+            "asset_type": "credit_alphanum4"
+          }
+        ],
+        "source_amount": "30.0000000",
+        "source_asset_type": "native"
+      },
+      {
+        "destination_amount": "20.0000000",
+        "destination_asset_code": "EUR",
+        "destination_asset_issuer": "GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN",
+        "destination_asset_type": "credit_alphanum4",
+        "path": [],
+        "source_amount": "22.0000000",
+        "source_asset_code": "USD",
+        "source_asset_issuer": "GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN",
+        "source_asset_type": "credit_alphanum4"
+      }
+    ]
+  },
+  "_links": {
+    "self": {
+      "href": ""
+    }
+  }
+}`
+
 var notFoundResponse = `{
   "type": "https://stellar.org/horizon-errors/not_found",
   "title": "Resource Missing",
@@ -787,3 +1330,279 @@ var transactionFailure = `{
     "result_xdr": "AAAAAAAAAAD////4AAAAAA=="
   }
 }`
+
+var rootResponse = `{
+  "_links": {
+    "account": {
+      "href": "https://horizon-testnet.stellar.org/accounts/{account_id}",
+      "templated": true
+    },
+    "friendbot": {
+      "href": "https://horizon-testnet.stellar.org/friendbot{?addr}",
+      "templated": true
+    },
+    "self": {
+      "href": "https://horizon-testnet.stellar.org/"
+    },
+    "transactions": {
+      "href": "https://horizon-testnet.stellar.org/transactions{?cursor,limit,order}",
+      "templated": true
+    }
+  },
+  "horizon_version": "snapshot-c5fb7d6",
+  "core_version": "v9.1.0",
+  "history_latest_ledger": 5837547,
+  "history_elder_ledger": 1,
+  "core_latest_ledger": 5837548,
+  "network_passphrase": "Test SDF Network ; September 2015",
+  "protocol_version": 9
+}`
+
+var transactionResponse = `{
+  "_links": {
+    "self": {
+      "href": "https://horizon-testnet.stellar.org/transactions/5131aed266a639a6eb4802a92fba310454e711ded830ed899745b9e777d7110c"
+    },
+    "operations": {
+      "href": "https://horizon-testnet.stellar.org/transactions/5131aed266a639a6eb4802a92fba310454e711ded830ed899745b9e777d7110c/operations{?cursor,limit,order}",
+      "templated": true
+    }
+  },
+  "id": "5131aed266a639a6eb4802a92fba310454e711ded830ed899745b9e777d7110c",
+  "paging_token": "15259984932098048",
+  "hash": "5131aed266a639a6eb4802a92fba310454e711ded830ed899745b9e777d7110c",
+  "ledger": 3553,
+  "created_at": "2017-10-17T22:27:12Z",
+  "source_account": "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+  "source_account_sequence": "3",
+  "fee_paid": 100,
+  "operation_count": 1,
+  "memo_type": "none",
+  "signatures": [
+    "ORVkwfpLqSl+Z8ONlOtxq9/NGrXyoqs5R+NvCAdB4+Rz6kjPQSBYSGKC/FuHqFGE0hHnmTSNDN+VLN9F6VeFAA=="
+  ]
+}`
+
+var transactionsResponse = `{
+  "_links": {
+    "self": {
+      "href": "https://horizon-testnet.stellar.org/transactions?order=asc&limit=1&cursor="
+    },
+    "next": {
+      "href": "https://horizon-testnet.stellar.org/transactions?order=asc&limit=1&cursor=15259984932098048"
+    },
+    "prev": {
+      "href": "https://horizon-testnet.stellar.org/transactions?order=desc&limit=1&cursor=15259984932098048"
+    }
+  },
+  "_embedded": {
+    "records": [
+      ` + transactionResponse + `
+    ]
+  }
+}`
+
+var operationsResponse = `{
+  "_links": {
+    "next": {
+      "href": "https://horizon-testnet.stellar.org/transactions/5131aed266a639a6eb4802a92fba310454e711ded830ed899745b9e777d7110c/operations?order=asc&limit=10&cursor=15259984932098049"
+    }
+  },
+  "_embedded": {
+    "records": [
+      {
+        "_links": {
+          "transaction": {
+            "href": "https://horizon-testnet.stellar.org/transactions/5131aed266a639a6eb4802a92fba310454e711ded830ed899745b9e777d7110c"
+          }
+        },
+        "id": "15259984932098049",
+        "paging_token": "15259984932098049",
+        "source_account": "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+        "type": "create_account",
+        "type_i": 0,
+        "created_at": "2017-10-17T22:27:12Z",
+        "transaction_hash": "5131aed266a639a6eb4802a92fba310454e711ded830ed899745b9e777d7110c",
+        "starting_balance": "10000.0000000",
+        "funder": "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+        "account": "GC2BQYBXFOVPRDH35D5HT2AFVCDGXJM5YVTAF5THFSAISYOWAJQKRESK"
+      }
+    ]
+  }
+}`
+
+var effectsResponse = `{
+  "_links": {
+    "next": {
+      "href": "https://horizon-testnet.stellar.org/accounts/GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H/effects?order=asc&limit=10&cursor=15259984932098049-1"
+    }
+  },
+  "_embedded": {
+    "records": [
+      {
+        "_links": {
+          "operation": {
+            "href": "https://horizon-testnet.stellar.org/operations/15259984932098049"
+          }
+        },
+        "id": "0015259984932098049-0000000001",
+        "paging_token": "15259984932098049-1",
+        "account": "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+        "type": "account_created",
+        "type_i": 0,
+        "starting_balance": "10000.0000000"
+      }
+    ]
+  }
+}`
+
+var paymentsResponse = `{
+  "_links": {
+    "next": {
+      "href": "https://horizon-testnet.stellar.org/ledgers/3553/payments?order=asc&limit=10&cursor=15259984932098049"
+    }
+  },
+  "_embedded": {
+    "records": [
+      {
+        "_links": {
+          "transaction": {
+            "href": "https://horizon-testnet.stellar.org/transactions/5131aed266a639a6eb4802a92fba310454e711ded830ed899745b9e777d7110c"
+          }
+        },
+        "id": "15259984932098049",
+        "paging_token": "15259984932098049",
+        "source_account": "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+        "type": "payment",
+        "type_i": 1,
+        "asset_type": "native",
+        "from": "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+        "to": "GC2BQYBXFOVPRDH35D5HT2AFVCDGXJM5YVTAF5THFSAISYOWAJQKRESK",
+        "amount": "10.0000000"
+      }
+    ]
+  }
+}`
+
+var ledgerResponse = `{
+  "_links": {
+    "self": {
+      "href": "https://horizon-testnet.stellar.org/ledgers/3553"
+    }
+  },
+  "id": "d6a1fda7ad6c6a0d1c2ed8b1b5b68e3a3d9d9ab8b8d1dba4b9a6e2d4b1a8c7e3",
+  "paging_token": "15259984932093952",
+  "hash": "d6a1fda7ad6c6a0d1c2ed8b1b5b68e3a3d9d9ab8b8d1dba4b9a6e2d4b1a8c7e3",
+  "prev_hash": "2d8c05b9c5f1cba4ac2f8e3c8b8ed8cd1c1b5b5fe2c9d4b8d4b8a6e2d4b1a8c7",
+  "sequence": 3553,
+  "transaction_count": 1,
+  "operation_count": 1,
+  "closed_at": "2017-10-17T22:27:12Z",
+  "total_coins": "100000000000.0000000",
+  "fee_pool": "0.0000400",
+  "base_fee": 100,
+  "base_reserve": "10.0000000",
+  "max_tx_set_size": 50,
+  "protocol_version": 9
+}`
+
+var ledgersResponse = `{
+  "_links": {
+    "next": {
+      "href": "https://horizon-testnet.stellar.org/ledgers?order=asc&limit=1&cursor=15259984932093952"
+    }
+  },
+  "_embedded": {
+    "records": [
+      ` + ledgerResponse + `
+    ]
+  }
+}`
+
+var assetsResponse = `{
+  "_links": {
+    "next": {
+      "href": "https://horizon-testnet.stellar.org/assets?order=asc&limit=10&cursor=USD_GDI73WJ4SX7LOG3XZDJC3KCK6ED6E5NBYK2JUBQSPBCNNWEG3ZN7T75U_credit_alphanum4"
+    }
+  },
+  "_embedded": {
+    "records": [
+      {
+        "_links": {
+          "toml": {
+            "href": "https://example.com/.well-known/stellar.toml"
+          }
+        },
+        "asset_type": "credit_alphanum4",
+        "asset_code": "USD",
+        "asset_issuer": "GDI73WJ4SX7LOG3XZDJC3KCK6ED6E5NBYK2JUBQSPBCNNWEG3ZN7T75U",
+        "paging_token": "USD_GDI73WJ4SX7LOG3XZDJC3KCK6ED6E5NBYK2JUBQSPBCNNWEG3ZN7T75U_credit_alphanum4",
+        "amount": "1000.0000000",
+        "num_accounts": 12,
+        "flags": {
+          "auth_required": true,
+          "auth_revocable": false
+        }
+      }
+    ]
+  }
+}`
+
+var tradesResponse = `{
+  "_links": {
+    "next": {
+      "href": "https://horizon-testnet.stellar.org/trades?order=asc&limit=2&cursor=15259984932098049-0"
+    }
+  },
+  "_embedded": {
+    "records": [
+      {
+        "_links": {
+          "operation": {
+            "href": "https://horizon-testnet.stellar.org/operations/15259984932098049"
+          }
+        },
+        "id": "15259984932098049-0",
+        "paging_token": "15259984932098049-0",
+        "ledger_close_time": "2017-10-17T22:27:12Z",
+        "offer_id": "161",
+        "base_account": "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+        "base_amount": "100.0000000",
+        "base_asset_type": "native",
+        "counter_account": "GC2BQYBXFOVPRDH35D5HT2AFVCDGXJM5YVTAF5THFSAISYOWAJQKRESK",
+        "counter_amount": "25.0000000",
+        "counter_asset_type": "credit_alphanum4",
+        "counter_asset_code": "USD",
+        "counter_asset_issuer": "GDI73WJ4SX7LOG3XZDJC3KCK6ED6E5NBYK2JUBQSPBCNNWEG3ZN7T75U",
+        "base_is_seller": true,
+        "price": {
+          "n": 1,
+          "d": 4
+        }
+      }
+    ]
+  }
+}`
+
+var tradeAggregationsResponse = `{
+  "_links": {
+    "next": {
+      "href": "https://horizon-testnet.stellar.org/trade_aggregations?start_time=1512003600000&end_time=1512086400000&resolution=3600000"
+    }
+  },
+  "_embedded": {
+    "records": [
+      {
+        "timestamp": 1512000000000,
+        "trade_count": 3,
+        "base_volume": "300.0000000",
+        "counter_volume": "75.0000000",
+        "avg": "0.2500000",
+        "high": "0.2600000",
+        "low": "0.2400000",
+        "open": "0.2400000",
+        "close": "0.2600000"
+      }
+    ]
+  }
+}`
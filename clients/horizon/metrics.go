@@ -0,0 +1,100 @@
+package horizon
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// resourceID matches the ids horizon uses in its paths: ledger sequences and
+// other numeric ids, transaction hashes and account ids.
+var resourceID = regexp.MustCompile(`^([0-9]+|[0-9a-f]{64}|G[A-Z2-7]{55})$`)
+
+// EndpointMetrics are the metrics a Metrics records for one endpoint.
+type EndpointMetrics struct {
+	// Requests is the number of requests sent to the endpoint.
+	Requests int
+
+	// Errors is the number of those requests that failed, either without a
+	// response or with a 5xx response.
+	Errors int
+
+	// TotalLatency is the time spent waiting for the responses to all of the
+	// requests, and MaxLatency the longest wait for one of them.  The wait
+	// ends when the headers of the response are received.
+	TotalLatency time.Duration
+	MaxLatency   time.Duration
+}
+
+// MeanLatency returns the mean time spent waiting for a response.
+func (m EndpointMetrics) MeanLatency() time.Duration {
+	if m.Requests == 0 {
+		return 0
+	}
+	return m.TotalLatency / time.Duration(m.Requests)
+}
+
+// Metrics records the latency of a client's requests to each horizon
+// endpoint.  Use its Middleware as one of the client's middleware.  Endpoints
+// are named by the method and path of their requests, with the ids of
+// resources replaced by "{id}", such as "GET /accounts/{id}/payments".  The
+// zero value is ready to use.
+type Metrics struct {
+	lock      sync.Mutex
+	endpoints map[string]EndpointMetrics
+}
+
+// Middleware records the latency of each request.
+func (m *Metrics) Middleware(next Doer) Doer {
+	return DoerFunc(func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		resp, err := next.Do(req)
+		m.record(endpointName(req), time.Since(start), err != nil || resp.StatusCode >= 500)
+		return resp, err
+	})
+}
+
+// Endpoints returns the metrics of each endpoint requested so far, by name.
+func (m *Metrics) Endpoints() map[string]EndpointMetrics {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	endpoints := make(map[string]EndpointMetrics, len(m.endpoints))
+	for name, metrics := range m.endpoints {
+		endpoints[name] = metrics
+	}
+	return endpoints
+}
+
+func (m *Metrics) record(endpoint string, latency time.Duration, failed bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.endpoints == nil {
+		m.endpoints = map[string]EndpointMetrics{}
+	}
+
+	metrics := m.endpoints[endpoint]
+	metrics.Requests++
+	if failed {
+		metrics.Errors++
+	}
+	metrics.TotalLatency += latency
+	if latency > metrics.MaxLatency {
+		metrics.MaxLatency = latency
+	}
+	m.endpoints[endpoint] = metrics
+}
+
+// endpointName returns the name of the endpoint `req` is sent to.
+func endpointName(req *http.Request) string {
+	segments := strings.Split(req.URL.Path, "/")
+	for i, segment := range segments {
+		if resourceID.MatchString(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	return req.Method + " " + strings.Join(segments, "/")
+}
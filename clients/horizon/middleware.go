@@ -0,0 +1,87 @@
+package horizon
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// Doer sends HTTP requests.  HTTP, and so *http.Client, is a Doer.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc is a function that is a Doer.
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+// Do implements Doer by calling `f`.
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps the Doer that sends a client's requests to horizon,
+// returning a Doer that may modify each request, observe or replace its
+// response, or answer it without sending it at all.
+type Middleware func(next Doer) Doer
+
+// send sends `req` through the client's middleware to its HTTP.  Every request
+// the client makes, including those of streams, is sent this way.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	var doer Doer = c.HTTP
+	for i := len(c.Middleware) - 1; i >= 0; i-- {
+		doer = c.Middleware[i](doer)
+	}
+	return doer.Do(req)
+}
+
+// RequestID is a Middleware that gives each request a random id in the
+// X-Request-ID header, unless it already has one, so that it can be traced
+// through horizon's logs.
+func RequestID(next Doer) Doer {
+	return DoerFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("X-Request-ID") != "" {
+			return next.Do(req)
+		}
+
+		id := make([]byte, 16)
+		_, err := rand.Read(id)
+		if err != nil {
+			return nil, err
+		}
+
+		req = withHeader(req, "X-Request-ID", hex.EncodeToString(id))
+		return next.Do(req)
+	})
+}
+
+// UserAgent returns a Middleware that identifies requests as coming from
+// version `version` of the client application `name`, in the User-Agent,
+// X-Client-Name and X-Client-Version headers.
+func UserAgent(name, version string) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			req = withHeader(req, "User-Agent", name+"/"+version)
+			req = withHeader(req, "X-Client-Name", name)
+			req = withHeader(req, "X-Client-Version", version)
+			return next.Do(req)
+		})
+	}
+}
+
+// withHeader returns a copy of `req` with the header `key` set to `value`,
+// leaving `req` unchanged so that it can be resent as it was.
+func withHeader(req *http.Request, key, value string) *http.Request {
+	out := new(http.Request)
+	*out = *req
+	out.Header = cloneHeader(req.Header)
+	out.Header.Set(key, value)
+	return out
+}
+
+func cloneHeader(header http.Header) http.Header {
+	clone := make(http.Header, len(header))
+	for key, values := range header {
+		clone[key] = append([]string(nil), values...)
+	}
+	return clone
+}
@@ -0,0 +1,271 @@
+package horizon
+
+import (
+	"fmt"
+	"net/http"
+	stdtest "net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testHash = "5f3f6b9b5b1ce3d3b8c0bcb1d7a0b69e0ce0a5ad12ab4ae4a1bf0c58fb8c0e0e"
+
+// headerServer is a stand-in for horizon that serves a ledger or transaction
+// for any path, with an ETag that changes when `version` does, and records
+// the headers of each request it receives.
+type headerServer struct {
+	*stdtest.Server
+
+	lock     sync.Mutex
+	version  int
+	requests []*http.Request
+}
+
+func newHeaderServer() *headerServer {
+	s := &headerServer{}
+	s.Server = stdtest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.lock.Lock()
+		s.requests = append(s.requests, r)
+		etag := fmt.Sprintf(`"v%d"`, s.version)
+		version := s.version
+		s.lock.Unlock()
+
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"type": "server_error", "status": 500}`)
+			return
+		}
+		fmt.Fprintf(w, `{"sequence": %d, "hash": "%s", "source_account_sequence": "%d"}`, version, testHash, version)
+	}))
+	return s
+}
+
+// Requests returns the requests received so far.
+func (s *headerServer) Requests() []*http.Request {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]*http.Request(nil), s.requests...)
+}
+
+func (s *headerServer) SetVersion(version int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.version = version
+}
+
+func TestClient_Middleware(t *testing.T) {
+	server := newHeaderServer()
+	defer server.Close()
+
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next Doer) Doer {
+			return DoerFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.Do(req)
+			})
+		}
+	}
+
+	client := &Client{
+		URL:        server.URL,
+		HTTP:       http.DefaultClient,
+		Middleware: []Middleware{trace("outer"), trace("inner"), RequestID, UserAgent("wallet", "1.2.3")},
+	}
+
+	_, err := client.LoadLedger(7)
+	require.NoError(t, err)
+	_, err = client.SubmitTransaction("AAAA")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"outer", "inner", "outer", "inner"}, order)
+
+	requests := server.Requests()
+	require.Len(t, requests, 2)
+	for _, req := range requests {
+		assert.Equal(t, "wallet/1.2.3", req.Header.Get("User-Agent"))
+		assert.Equal(t, "wallet", req.Header.Get("X-Client-Name"))
+		assert.Equal(t, "1.2.3", req.Header.Get("X-Client-Version"))
+		assert.Len(t, req.Header.Get("X-Request-ID"), 32)
+	}
+	assert.NotEqual(t, requests[0].Header.Get("X-Request-ID"), requests[1].Header.Get("X-Request-ID"))
+}
+
+func TestCache(t *testing.T) {
+	server := newHeaderServer()
+	defer server.Close()
+
+	cache := &Cache{}
+	client := &Client{URL: server.URL, HTTP: http.DefaultClient, Middleware: []Middleware{cache.Middleware}}
+
+	// immutable resources are served from the cache without a request
+	ledger, err := client.LoadLedger(7)
+	require.NoError(t, err)
+	server.SetVersion(1)
+	cached, err := client.LoadLedger(7)
+	require.NoError(t, err)
+	assert.Equal(t, ledger, cached)
+
+	tx, err := client.LoadTransaction(testHash)
+	require.NoError(t, err)
+	_, err = client.LoadTransaction(testHash)
+	require.NoError(t, err)
+	assert.Equal(t, "1", tx.AccountSequence)
+	assert.Len(t, server.Requests(), 2)
+
+	// other resources are revalidated with their ETag
+	_, err = client.LoadLedgers()
+	require.NoError(t, err)
+	_, err = client.LoadLedgers()
+	require.NoError(t, err)
+	requests := server.Requests()
+	require.Len(t, requests, 4)
+	assert.Equal(t, "", requests[2].Header.Get("If-None-Match"))
+	assert.Equal(t, `"v1"`, requests[3].Header.Get("If-None-Match"))
+
+	server.SetVersion(2)
+	_, err = client.LoadLedgers()
+	require.NoError(t, err)
+	assert.Len(t, server.Requests(), 5)
+	assert.Equal(t, 3, cache.Len())
+
+	// submissions and failures are not cached
+	_, err = client.SubmitTransaction("AAAA")
+	require.NoError(t, err)
+	_, err = client.LoadLedgers(At(server.URL + "/broken"))
+	assert.Error(t, err)
+	assert.Equal(t, 3, cache.Len())
+
+	// immutable resources are requested again once their TTL has passed
+	cache.TTL = time.Nanosecond
+	_, err = client.LoadLedger(7)
+	require.NoError(t, err)
+	assert.Len(t, server.Requests(), 8)
+
+	// the oldest response is evicted from a full cache
+	small := &Cache{Size: 2}
+	client.Middleware = []Middleware{small.Middleware}
+	for _, seq := range []int32{1, 2, 3} {
+		_, err = client.LoadLedger(seq)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, small.Len())
+	assert.Nil(t, small.get(server.URL+"/ledgers/1"))
+}
+
+func TestCache_RateLimit(t *testing.T) {
+	// a stand-in for horizon that allows two requests in the current period
+	var lock sync.Mutex
+	remaining := 2
+	server := stdtest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if remaining > 0 {
+			remaining--
+		}
+		w.Header().Set("X-RateLimit-Limit", "3")
+		w.Header().Set("X-RateLimit-Remaining", fmt.Sprint(remaining))
+		w.Header().Set("X-RateLimit-Reset", "60")
+		w.Header().Set("ETag", `"v0"`)
+		if r.Header.Get("If-None-Match") == `"v0"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprint(w, `{"sequence": 7}`)
+	}))
+	defer server.Close()
+
+	cache := &Cache{}
+	client := &Client{
+		URL:              server.URL,
+		HTTP:             http.DefaultClient,
+		Middleware:       []Middleware{cache.Middleware},
+		WaitForRateLimit: true,
+	}
+
+	// the last request of the period is cached
+	_, err := client.LoadLedgers()
+	require.NoError(t, err)
+	_, err = client.LoadLedger(7)
+	require.NoError(t, err)
+	limit, _ := client.RateLimit()
+	assert.Equal(t, 0, limit.Remaining)
+
+	// once the period resets, cached responses do not report it exhausted
+	client.rateLimit = RateLimit{}
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.LoadLedger(7)
+		if err == nil {
+			_, err = client.LoadLedger(7)
+		}
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("cached responses waited for the rate limit")
+	}
+	_, ok := client.RateLimit()
+	assert.False(t, ok)
+
+	// revalidated responses report the rate limit horizon revalidated them with
+	lock.Lock()
+	remaining = 3
+	lock.Unlock()
+	_, err = client.LoadLedgers()
+	require.NoError(t, err)
+	limit, ok = client.RateLimit()
+	if assert.True(t, ok) {
+		assert.Equal(t, 2, limit.Remaining)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	server := newHeaderServer()
+	defer server.Close()
+
+	metrics := &Metrics{}
+	client := &Client{URL: server.URL, HTTP: http.DefaultClient, Middleware: []Middleware{metrics.Middleware}}
+
+	for _, seq := range []int32{1, 2} {
+		_, err := client.LoadLedger(seq)
+		require.NoError(t, err)
+	}
+	_, err := client.LoadPayments(ForAccount("GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"))
+	require.NoError(t, err)
+	_, err = client.LoadTransaction(testHash)
+	require.NoError(t, err)
+	_, err = client.LoadLedgers(At(server.URL + "/broken"))
+	assert.Error(t, err)
+
+	endpoints := metrics.Endpoints()
+	assert.Len(t, endpoints, 4)
+
+	ledgers := endpoints["GET /ledgers/{id}"]
+	assert.Equal(t, 2, ledgers.Requests)
+	assert.Equal(t, 0, ledgers.Errors)
+	assert.True(t, ledgers.MaxLatency > 0)
+	assert.True(t, ledgers.MeanLatency() <= ledgers.MaxLatency)
+
+	assert.Equal(t, 1, endpoints["GET /accounts/{id}/payments"].Requests)
+	assert.Equal(t, 1, endpoints["GET /transactions/{id}"].Requests)
+	assert.Equal(t, 1, endpoints["GET /broken"].Errors)
+
+	// transport errors count as errors
+	client.URL = deadURL()
+	_, err = client.LoadLedger(1)
+	assert.Error(t, err)
+	assert.Equal(t, 1, metrics.Endpoints()["GET /ledgers/{id}"].Errors)
+}
@@ -1,6 +1,9 @@
 package horizon
 
 import (
+	"time"
+
+	"github.com/stellar/go/xdr"
 	"github.com/stretchr/testify/mock"
 	"golang.org/x/net/context"
 )
@@ -10,6 +13,12 @@ type MockClient struct {
 	mock.Mock
 }
 
+// HomeDomainForAccount is a mocking a method
+func (m *MockClient) HomeDomainForAccount(aid string) (string, error) {
+	a := m.Called(aid)
+	return a.String(0), a.Error(1)
+}
+
 // LoadAccount is a mocking a method
 func (m *MockClient) LoadAccount(accountID string) (Account, error) {
 	a := m.Called(accountID)
@@ -43,6 +52,12 @@ func (m *MockClient) LoadOrderBook(selling Asset, buying Asset) (orderBook Order
 	return a.Get(0).(OrderBookSummary), a.Error(1)
 }
 
+// LoadPaths is a mocking a method
+func (m *MockClient) LoadPaths(sourceAccount, destinationAccount string, destinationAsset Asset, destinationAmount string) (PathsPage, error) {
+	a := m.Called(sourceAccount, destinationAccount, destinationAsset, destinationAmount)
+	return a.Get(0).(PathsPage), a.Error(1)
+}
+
 // StreamLedgers is a mocking a method
 func (m *MockClient) StreamLedgers(ctx context.Context, cursor *Cursor, handler LedgerHandler) error {
 	a := m.Called(ctx, cursor, handler)
@@ -61,11 +76,399 @@ func (m *MockClient) StreamTransactions(ctx context.Context, accountID string, c
 	return a.Error(0)
 }
 
+// StreamOperations is a mocking a method
+func (m *MockClient) StreamOperations(ctx context.Context, cursor *Cursor, handler OperationHandler, params ...interface{}) error {
+	args := []interface{}{ctx, cursor, handler}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Error(0)
+}
+
+// StreamEffects is a mocking a method
+func (m *MockClient) StreamEffects(ctx context.Context, cursor *Cursor, handler EffectHandler, params ...interface{}) error {
+	args := []interface{}{ctx, cursor, handler}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Error(0)
+}
+
+// StreamTrades is a mocking a method
+func (m *MockClient) StreamTrades(ctx context.Context, base, counter Asset, cursor *Cursor, handler TradeHandler) error {
+	a := m.Called(ctx, base, counter, cursor, handler)
+	return a.Error(0)
+}
+
+// StreamOffers is a mocking a method
+func (m *MockClient) StreamOffers(ctx context.Context, accountID string, cursor *Cursor, handler OfferHandler) error {
+	a := m.Called(ctx, accountID, cursor, handler)
+	return a.Error(0)
+}
+
+// StreamOrderBook is a mocking a method
+func (m *MockClient) StreamOrderBook(ctx context.Context, selling, buying Asset, handler OrderBookHandler) error {
+	a := m.Called(ctx, selling, buying, handler)
+	return a.Error(0)
+}
+
+// SequenceForAccount is a mocking a method
+func (m *MockClient) SequenceForAccount(accountID string) (xdr.SequenceNumber, error) {
+	a := m.Called(accountID)
+	return a.Get(0).(xdr.SequenceNumber), a.Error(1)
+}
+
 // SubmitTransaction is a mocking a method
 func (m *MockClient) SubmitTransaction(txeBase64 string) (TransactionSuccess, error) {
 	a := m.Called(txeBase64)
 	return a.Get(0).(TransactionSuccess), a.Error(1)
 }
 
+// HomeDomainForAccountContext is a mocking a method
+func (m *MockClient) HomeDomainForAccountContext(ctx context.Context, aid string) (string, error) {
+	a := m.Called(ctx, aid)
+	return a.String(0), a.Error(1)
+}
+
+// LoadAccountContext is a mocking a method
+func (m *MockClient) LoadAccountContext(ctx context.Context, accountID string) (Account, error) {
+	a := m.Called(ctx, accountID)
+	return a.Get(0).(Account), a.Error(1)
+}
+
+// LoadAccountOffersContext is a mocking a method
+func (m *MockClient) LoadAccountOffersContext(ctx context.Context, accountID string, params ...interface{}) (offers OffersPage, err error) {
+	args := []interface{}{ctx, accountID}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(OffersPage), a.Error(1)
+}
+
+// LoadMemoContext is a mocking a method
+func (m *MockClient) LoadMemoContext(ctx context.Context, p *Payment) error {
+	a := m.Called(ctx, p)
+	return a.Error(0)
+}
+
+// LoadOrderBookContext is a mocking a method
+func (m *MockClient) LoadOrderBookContext(ctx context.Context, selling Asset, buying Asset) (orderBook OrderBookSummary, err error) {
+	a := m.Called(ctx, selling, buying)
+	return a.Get(0).(OrderBookSummary), a.Error(1)
+}
+
+// LoadPathsContext is a mocking a method
+func (m *MockClient) LoadPathsContext(ctx context.Context, sourceAccount, destinationAccount string, destinationAsset Asset, destinationAmount string) (PathsPage, error) {
+	a := m.Called(ctx, sourceAccount, destinationAccount, destinationAsset, destinationAmount)
+	return a.Get(0).(PathsPage), a.Error(1)
+}
+
+// SequenceForAccountContext is a mocking a method
+func (m *MockClient) SequenceForAccountContext(ctx context.Context, accountID string) (xdr.SequenceNumber, error) {
+	a := m.Called(ctx, accountID)
+	return a.Get(0).(xdr.SequenceNumber), a.Error(1)
+}
+
+// SubmitTransactionContext is a mocking a method
+func (m *MockClient) SubmitTransactionContext(ctx context.Context, txeBase64 string) (TransactionSuccess, error) {
+	a := m.Called(ctx, txeBase64)
+	return a.Get(0).(TransactionSuccess), a.Error(1)
+}
+
+// SubmitTransactionWithRetry is a mocking a method
+func (m *MockClient) SubmitTransactionWithRetry(ctx context.Context, txeBase64 string, opts SubmitOptions) (SubmitResult, error) {
+	a := m.Called(ctx, txeBase64, opts)
+	return a.Get(0).(SubmitResult), a.Error(1)
+}
+
+// Root is a mocking a method
+func (m *MockClient) Root() (Root, error) {
+	a := m.Called()
+	return a.Get(0).(Root), a.Error(1)
+}
+
+// RootContext is a mocking a method
+func (m *MockClient) RootContext(ctx context.Context) (Root, error) {
+	a := m.Called(ctx)
+	return a.Get(0).(Root), a.Error(1)
+}
+
+// LoadTransaction is a mocking a method
+func (m *MockClient) LoadTransaction(hash string) (Transaction, error) {
+	a := m.Called(hash)
+	return a.Get(0).(Transaction), a.Error(1)
+}
+
+// LoadTransactionContext is a mocking a method
+func (m *MockClient) LoadTransactionContext(ctx context.Context, hash string) (Transaction, error) {
+	a := m.Called(ctx, hash)
+	return a.Get(0).(Transaction), a.Error(1)
+}
+
+// LoadTransactions is a mocking a method
+func (m *MockClient) LoadTransactions(params ...interface{}) (TransactionsPage, error) {
+	args := []interface{}{}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(TransactionsPage), a.Error(1)
+}
+
+// LoadTransactionsContext is a mocking a method
+func (m *MockClient) LoadTransactionsContext(ctx context.Context, params ...interface{}) (TransactionsPage, error) {
+	args := []interface{}{ctx}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(TransactionsPage), a.Error(1)
+}
+
+// LoadOperations is a mocking a method
+func (m *MockClient) LoadOperations(params ...interface{}) (OperationsPage, error) {
+	args := []interface{}{}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(OperationsPage), a.Error(1)
+}
+
+// LoadOperationsContext is a mocking a method
+func (m *MockClient) LoadOperationsContext(ctx context.Context, params ...interface{}) (OperationsPage, error) {
+	args := []interface{}{ctx}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(OperationsPage), a.Error(1)
+}
+
+// LoadEffects is a mocking a method
+func (m *MockClient) LoadEffects(params ...interface{}) (EffectsPage, error) {
+	args := []interface{}{}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(EffectsPage), a.Error(1)
+}
+
+// LoadEffectsContext is a mocking a method
+func (m *MockClient) LoadEffectsContext(ctx context.Context, params ...interface{}) (EffectsPage, error) {
+	args := []interface{}{ctx}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(EffectsPage), a.Error(1)
+}
+
+// LoadPayments is a mocking a method
+func (m *MockClient) LoadPayments(params ...interface{}) (PaymentsPage, error) {
+	args := []interface{}{}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(PaymentsPage), a.Error(1)
+}
+
+// LoadPaymentsContext is a mocking a method
+func (m *MockClient) LoadPaymentsContext(ctx context.Context, params ...interface{}) (PaymentsPage, error) {
+	args := []interface{}{ctx}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(PaymentsPage), a.Error(1)
+}
+
+// LoadLedger is a mocking a method
+func (m *MockClient) LoadLedger(sequence int32) (Ledger, error) {
+	a := m.Called(sequence)
+	return a.Get(0).(Ledger), a.Error(1)
+}
+
+// LoadLedgerContext is a mocking a method
+func (m *MockClient) LoadLedgerContext(ctx context.Context, sequence int32) (Ledger, error) {
+	a := m.Called(ctx, sequence)
+	return a.Get(0).(Ledger), a.Error(1)
+}
+
+// LoadLedgers is a mocking a method
+func (m *MockClient) LoadLedgers(params ...interface{}) (LedgersPage, error) {
+	args := []interface{}{}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(LedgersPage), a.Error(1)
+}
+
+// LoadLedgersContext is a mocking a method
+func (m *MockClient) LoadLedgersContext(ctx context.Context, params ...interface{}) (LedgersPage, error) {
+	args := []interface{}{ctx}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(LedgersPage), a.Error(1)
+}
+
+// LoadAssets is a mocking a method
+func (m *MockClient) LoadAssets(params ...interface{}) (AssetsPage, error) {
+	args := []interface{}{}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(AssetsPage), a.Error(1)
+}
+
+// LoadAssetsContext is a mocking a method
+func (m *MockClient) LoadAssetsContext(ctx context.Context, params ...interface{}) (AssetsPage, error) {
+	args := []interface{}{ctx}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(AssetsPage), a.Error(1)
+}
+
+// LoadTrades is a mocking a method
+func (m *MockClient) LoadTrades(base, counter Asset, params ...interface{}) (TradesPage, error) {
+	args := []interface{}{base, counter}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(TradesPage), a.Error(1)
+}
+
+// LoadTradesContext is a mocking a method
+func (m *MockClient) LoadTradesContext(ctx context.Context, base, counter Asset, params ...interface{}) (TradesPage, error) {
+	args := []interface{}{ctx, base, counter}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(TradesPage), a.Error(1)
+}
+
+// LoadTradeAggregations is a mocking a method
+func (m *MockClient) LoadTradeAggregations(base, counter Asset, start, end time.Time, resolution time.Duration, params ...interface{}) (TradeAggregationsPage, error) {
+	args := []interface{}{base, counter, start, end, resolution}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(TradeAggregationsPage), a.Error(1)
+}
+
+// LoadTradeAggregationsContext is a mocking a method
+func (m *MockClient) LoadTradeAggregationsContext(ctx context.Context, base, counter Asset, start, end time.Time, resolution time.Duration, params ...interface{}) (TradeAggregationsPage, error) {
+	args := []interface{}{ctx, base, counter, start, end, resolution}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(TradeAggregationsPage), a.Error(1)
+}
+
+// AccountOffers is a mocking a method
+func (m *MockClient) AccountOffers(ctx context.Context, accountID string, params ...interface{}) *Iterator {
+	args := []interface{}{ctx, accountID}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(*Iterator)
+}
+
+// Transactions is a mocking a method
+func (m *MockClient) Transactions(ctx context.Context, params ...interface{}) *Iterator {
+	args := []interface{}{ctx}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(*Iterator)
+}
+
+// Operations is a mocking a method
+func (m *MockClient) Operations(ctx context.Context, params ...interface{}) *Iterator {
+	args := []interface{}{ctx}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(*Iterator)
+}
+
+// Effects is a mocking a method
+func (m *MockClient) Effects(ctx context.Context, params ...interface{}) *Iterator {
+	args := []interface{}{ctx}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(*Iterator)
+}
+
+// Payments is a mocking a method
+func (m *MockClient) Payments(ctx context.Context, params ...interface{}) *Iterator {
+	args := []interface{}{ctx}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(*Iterator)
+}
+
+// Ledgers is a mocking a method
+func (m *MockClient) Ledgers(ctx context.Context, params ...interface{}) *Iterator {
+	args := []interface{}{ctx}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(*Iterator)
+}
+
+// Assets is a mocking a method
+func (m *MockClient) Assets(ctx context.Context, params ...interface{}) *Iterator {
+	args := []interface{}{ctx}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(*Iterator)
+}
+
+// Trades is a mocking a method
+func (m *MockClient) Trades(ctx context.Context, base, counter Asset, params ...interface{}) *Iterator {
+	args := []interface{}{ctx, base, counter}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(*Iterator)
+}
+
+// TradeAggregations is a mocking a method
+func (m *MockClient) TradeAggregations(ctx context.Context, base, counter Asset, start, end time.Time, resolution time.Duration, params ...interface{}) *Iterator {
+	args := []interface{}{ctx, base, counter, start, end, resolution}
+	for _, param := range params {
+		args = append(args, param)
+	}
+	a := m.Called(args...)
+	return a.Get(0).(*Iterator)
+}
+
 // ensure that the MockClient implements ClientInterface
 var _ ClientInterface = &MockClient{}
@@ -0,0 +1,253 @@
+package horizon
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/stellar/go/support/errors"
+)
+
+// Operation is the response for one of horizon's operation types.  Values
+// are pointers to the struct of their type, such as *CreateAccount or
+// *Payment, and can be told apart with a type switch.  Operations of types
+// this package does not know about are returned as a *BaseOperation.
+type Operation interface {
+	// Base returns the fields common to operations of every type.
+	Base() BaseOperation
+}
+
+// BaseOperation holds the fields common to operations of every type.
+type BaseOperation struct {
+	Links struct {
+		Self        Link `json:"self"`
+		Transaction Link `json:"transaction"`
+		Effects     Link `json:"effects"`
+		Succeeds    Link `json:"succeeds"`
+		Precedes    Link `json:"precedes"`
+	} `json:"_links"`
+
+	ID              string    `json:"id"`
+	PT              string    `json:"paging_token"`
+	SourceAccount   string    `json:"source_account"`
+	Type            string    `json:"type"`
+	TypeI           int32     `json:"type_i"`
+	CreatedAt       time.Time `json:"created_at"`
+	TransactionHash string    `json:"transaction_hash"`
+}
+
+// Base implements Operation.
+func (op BaseOperation) Base() BaseOperation {
+	return op
+}
+
+type CreateAccount struct {
+	BaseOperation
+	StartingBalance string `json:"starting_balance"`
+	Funder          string `json:"funder"`
+	Account         string `json:"account"`
+}
+
+type Payment struct {
+	BaseOperation
+	AssetType   string `json:"asset_type"`
+	AssetCode   string `json:"asset_code,omitempty"`
+	AssetIssuer string `json:"asset_issuer,omitempty"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Amount      string `json:"amount"`
+
+	// transaction fields, populated by LoadMemo
+	Memo struct {
+		Type  string `json:"memo_type"`
+		Value string `json:"memo"`
+	}
+}
+
+type PathPayment struct {
+	BaseOperation
+	AssetType         string  `json:"asset_type"`
+	AssetCode         string  `json:"asset_code,omitempty"`
+	AssetIssuer       string  `json:"asset_issuer,omitempty"`
+	From              string  `json:"from"`
+	To                string  `json:"to"`
+	Amount            string  `json:"amount"`
+	Path              []Asset `json:"path"`
+	SourceMax         string  `json:"source_max"`
+	SourceAmount      string  `json:"source_amount"`
+	SourceAssetType   string  `json:"source_asset_type"`
+	SourceAssetCode   string  `json:"source_asset_code,omitempty"`
+	SourceAssetIssuer string  `json:"source_asset_issuer,omitempty"`
+}
+
+type ManageOffer struct {
+	BaseOperation
+	OfferID            int64  `json:"offer_id"`
+	Amount             string `json:"amount"`
+	Price              string `json:"price"`
+	PriceR             Price  `json:"price_r"`
+	BuyingAssetType    string `json:"buying_asset_type"`
+	BuyingAssetCode    string `json:"buying_asset_code,omitempty"`
+	BuyingAssetIssuer  string `json:"buying_asset_issuer,omitempty"`
+	SellingAssetType   string `json:"selling_asset_type"`
+	SellingAssetCode   string `json:"selling_asset_code,omitempty"`
+	SellingAssetIssuer string `json:"selling_asset_issuer,omitempty"`
+}
+
+type CreatePassiveOffer struct {
+	BaseOperation
+	Amount             string `json:"amount"`
+	Price              string `json:"price"`
+	PriceR             Price  `json:"price_r"`
+	BuyingAssetType    string `json:"buying_asset_type"`
+	BuyingAssetCode    string `json:"buying_asset_code,omitempty"`
+	BuyingAssetIssuer  string `json:"buying_asset_issuer,omitempty"`
+	SellingAssetType   string `json:"selling_asset_type"`
+	SellingAssetCode   string `json:"selling_asset_code,omitempty"`
+	SellingAssetIssuer string `json:"selling_asset_issuer,omitempty"`
+}
+
+// SetOptions is the response for a set_options operation.  Fields that the
+// operation did not set are left empty, or nil for numeric fields.
+type SetOptions struct {
+	BaseOperation
+	HomeDomain      string   `json:"home_domain,omitempty"`
+	InflationDest   string   `json:"inflation_dest,omitempty"`
+	MasterKeyWeight *int     `json:"master_key_weight,omitempty"`
+	SignerKey       string   `json:"signer_key,omitempty"`
+	SignerWeight    *int     `json:"signer_weight,omitempty"`
+	SetFlags        []int    `json:"set_flags,omitempty"`
+	SetFlagsS       []string `json:"set_flags_s,omitempty"`
+	ClearFlags      []int    `json:"clear_flags,omitempty"`
+	ClearFlagsS     []string `json:"clear_flags_s,omitempty"`
+	LowThreshold    *int     `json:"low_threshold,omitempty"`
+	MedThreshold    *int     `json:"med_threshold,omitempty"`
+	HighThreshold   *int     `json:"high_threshold,omitempty"`
+}
+
+type ChangeTrust struct {
+	BaseOperation
+	AssetType   string `json:"asset_type"`
+	AssetCode   string `json:"asset_code,omitempty"`
+	AssetIssuer string `json:"asset_issuer,omitempty"`
+	Limit       string `json:"limit"`
+	Trustee     string `json:"trustee"`
+	Trustor     string `json:"trustor"`
+}
+
+type AllowTrust struct {
+	BaseOperation
+	AssetType   string `json:"asset_type"`
+	AssetCode   string `json:"asset_code,omitempty"`
+	AssetIssuer string `json:"asset_issuer,omitempty"`
+	Trustee     string `json:"trustee"`
+	Trustor     string `json:"trustor"`
+	Authorize   bool   `json:"authorize"`
+}
+
+type AccountMerge struct {
+	BaseOperation
+	Account string `json:"account"`
+	Into    string `json:"into"`
+}
+
+type Inflation struct {
+	BaseOperation
+}
+
+type ManageData struct {
+	BaseOperation
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// UnmarshalOperation decodes the JSON form of an operation into the struct
+// for its type.
+func UnmarshalOperation(data []byte) (Operation, error) {
+	var base BaseOperation
+	err := json.Unmarshal(data, &base)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode operation")
+	}
+
+	var op Operation
+	switch base.Type {
+	case "create_account":
+		op = &CreateAccount{}
+	case "payment":
+		op = &Payment{}
+	case "path_payment":
+		op = &PathPayment{}
+	case "manage_offer":
+		op = &ManageOffer{}
+	case "create_passive_offer":
+		op = &CreatePassiveOffer{}
+	case "set_options":
+		op = &SetOptions{}
+	case "change_trust":
+		op = &ChangeTrust{}
+	case "allow_trust":
+		op = &AllowTrust{}
+	case "account_merge":
+		op = &AccountMerge{}
+	case "inflation":
+		op = &Inflation{}
+	case "manage_data":
+		op = &ManageData{}
+	default:
+		return &base, nil
+	}
+
+	err = json.Unmarshal(data, op)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode %s operation", base.Type)
+	}
+
+	return op, nil
+}
+
+type OperationsPage struct {
+	Links struct {
+		Self Link `json:"self"`
+		Next Link `json:"next"`
+		Prev Link `json:"prev"`
+	} `json:"_links"`
+	Embedded struct {
+		Records []Operation `json:"records"`
+	} `json:"_embedded"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, decoding each record into the
+// struct for its type.
+func (page *OperationsPage) UnmarshalJSON(data []byte) error {
+	var raw Page
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+
+	page.Links = raw.Links
+	page.Embedded.Records, err = unmarshalOperations(raw.Embedded.Records)
+	return err
+}
+
+// PaymentsPage is a page of the payments collection, whose records are
+// *CreateAccount, *Payment, *PathPayment or *AccountMerge operations.
+type PaymentsPage OperationsPage
+
+// UnmarshalJSON implements json.Unmarshaler, decoding each record into the
+// struct for its type.
+func (page *PaymentsPage) UnmarshalJSON(data []byte) error {
+	return (*OperationsPage)(page).UnmarshalJSON(data)
+}
+
+func unmarshalOperations(records []json.RawMessage) ([]Operation, error) {
+	ops := make([]Operation, len(records))
+	for i, record := range records {
+		op, err := UnmarshalOperation(record)
+		if err != nil {
+			return nil, err
+		}
+		ops[i] = op
+	}
+	return ops, nil
+}
@@ -0,0 +1,110 @@
+package horizon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalOperation(t *testing.T) {
+	op, err := UnmarshalOperation([]byte(`{
+    "id": "1",
+    "paging_token": "1",
+    "type": "path_payment",
+    "type_i": 2,
+    "asset_type": "credit_alphanum4",
+    "asset_code": "USD",
+    "asset_issuer": "GDI73WJ4SX7LOG3XZDJC3KCK6ED6E5NBYK2JUBQSPBCNNWEG3ZN7T75U",
+    "from": "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+    "to": "GC2BQYBXFOVPRDH35D5HT2AFVCDGXJM5YVTAF5THFSAISYOWAJQKRESK",
+    "amount": "10.0000000",
+    "path": [{"asset_type": "native"}],
+    "source_max": "50.0000000",
+    "source_amount": "40.0000000",
+    "source_asset_type": "native"
+  }`))
+	require.NoError(t, err)
+	if pp, ok := op.(*PathPayment); assert.True(t, ok) {
+		assert.Equal(t, "path_payment", pp.Type)
+		assert.Equal(t, "USD", pp.AssetCode)
+		assert.Equal(t, "40.0000000", pp.SourceAmount)
+		assert.Equal(t, []Asset{{Type: "native"}}, pp.Path)
+		assert.Equal(t, "1", op.Base().PT)
+	}
+
+	op, err = UnmarshalOperation([]byte(`{
+    "id": "2",
+    "type": "set_options",
+    "type_i": 5,
+    "home_domain": "example.com",
+    "low_threshold": 0,
+    "set_flags_s": ["auth_required_flag"]
+  }`))
+	require.NoError(t, err)
+	if so, ok := op.(*SetOptions); assert.True(t, ok) {
+		assert.Equal(t, "example.com", so.HomeDomain)
+		if assert.NotNil(t, so.LowThreshold) {
+			assert.Equal(t, 0, *so.LowThreshold)
+		}
+		assert.Nil(t, so.HighThreshold)
+		assert.Equal(t, []string{"auth_required_flag"}, so.SetFlagsS)
+	}
+
+	op, err = UnmarshalOperation([]byte(`{"id": "3", "type": "manage_offer", "offer_id": 161, "price_r": {"n": 1, "d": 2}}`))
+	require.NoError(t, err)
+	if mo, ok := op.(*ManageOffer); assert.True(t, ok) {
+		assert.Equal(t, int64(161), mo.OfferID)
+		assert.Equal(t, Price{N: 1, D: 2}, mo.PriceR)
+	}
+
+	// unknown types decode into the base
+	op, err = UnmarshalOperation([]byte(`{"id": "4", "type": "bump_sequence", "type_i": 11}`))
+	require.NoError(t, err)
+	if base, ok := op.(*BaseOperation); assert.True(t, ok) {
+		assert.Equal(t, "bump_sequence", base.Type)
+	}
+
+	_, err = UnmarshalOperation([]byte(`{"id": "5", "type": "payment", "amount": 10}`))
+	assert.Error(t, err)
+}
+
+func TestUnmarshalEffect(t *testing.T) {
+	effect, err := UnmarshalEffect([]byte(`{
+    "id": "1",
+    "account": "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+    "type": "trade",
+    "type_i": 33,
+    "seller": "GC2BQYBXFOVPRDH35D5HT2AFVCDGXJM5YVTAF5THFSAISYOWAJQKRESK",
+    "offer_id": 161,
+    "sold_amount": "10.0000000",
+    "sold_asset_type": "native",
+    "bought_amount": "2.5000000",
+    "bought_asset_type": "credit_alphanum4",
+    "bought_asset_code": "USD",
+    "bought_asset_issuer": "GDI73WJ4SX7LOG3XZDJC3KCK6ED6E5NBYK2JUBQSPBCNNWEG3ZN7T75U"
+  }`))
+	require.NoError(t, err)
+	if trade, ok := effect.(*TradeEffect); assert.True(t, ok) {
+		assert.Equal(t, int64(161), trade.OfferID)
+		assert.Equal(t, "USD", trade.BoughtAssetCode)
+		assert.Equal(t, "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H", effect.Base().Account)
+	}
+
+	effect, err = UnmarshalEffect([]byte(`{"id": "2", "type": "account_flags_updated", "auth_required_flag": true}`))
+	require.NoError(t, err)
+	if flags, ok := effect.(*AccountFlagsUpdated); assert.True(t, ok) {
+		if assert.NotNil(t, flags.AuthRequired) {
+			assert.True(t, *flags.AuthRequired)
+		}
+		assert.Nil(t, flags.AuthRevocable)
+	}
+
+	effect, err = UnmarshalEffect([]byte(`{"id": "3", "type": "signer_created", "weight": 1, "public_key": "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"}`))
+	require.NoError(t, err)
+	assert.IsType(t, &SignerCreated{}, effect)
+
+	effect, err = UnmarshalEffect([]byte(`{"id": "4", "type": "offer_created", "type_i": 30}`))
+	require.NoError(t, err)
+	assert.IsType(t, &BaseEffect{}, effect)
+}
@@ -0,0 +1,327 @@
+// Package orderbook keeps a live copy of horizon's order book for an asset
+// pair, from which prices can be quoted without a request to horizon.
+//
+// A Book starts from the order book horizon reports and replaces it each time
+// horizon streams a new one, which it does as offers are created, updated or
+// removed.  Trades between the assets, which are streamed separately, are
+// applied to the book as soon as they are received, so that the liquidity
+// they consumed is not quoted in the meantime.
+//
+// All prices are in units of the buying (counter) asset per unit of the
+// selling (base) asset, and all arithmetic is exact.
+package orderbook
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/clients/horizon"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+	"golang.org/x/net/context"
+)
+
+var (
+	// ErrNotLoaded is returned when a book is asked for prices before it
+	// has loaded the order book.
+	ErrNotLoaded = errors.New("order book not loaded")
+
+	// ErrInsufficientLiquidity is returned when the order book does not
+	// hold enough offers to fill the amount quoted.
+	ErrInsufficientLiquidity = errors.New("insufficient liquidity")
+
+	// ErrEmpty is returned when the side of the order book asked about has
+	// no offers.
+	ErrEmpty = errors.New("no offers")
+)
+
+// Book is a live order book for the Selling and Buying assets.  Asks are
+// offers selling the Selling asset for the Buying asset, and bids are offers
+// buying the Selling asset with the Buying asset.  Its methods are safe to
+// call from multiple goroutines.
+type Book struct {
+	// Client is used to load and stream the order book and its trades.
+	Client horizon.ClientInterface
+
+	// Selling is the base asset of the book, and Buying its counter asset.
+	Selling horizon.Asset
+	Buying  horizon.Asset
+
+	lock   sync.RWMutex
+	loaded bool
+	bids   []level
+	asks   []level
+}
+
+// level is a price level with its amount in units of the base asset.
+type level struct {
+	price  *big.Rat
+	amount *big.Rat
+}
+
+// Quote is the result of filling an amount of the base asset from the order
+// book.
+type Quote struct {
+	// Amount is the amount of the base asset bought or sold.
+	Amount *big.Rat
+
+	// Total is the amount of the counter asset paid for or received from
+	// Amount.
+	Total *big.Rat
+
+	// BestPrice is the price of the first level filled, AveragePrice the
+	// price of Amount overall and WorstPrice the price of the last level
+	// filled.
+	BestPrice    *big.Rat
+	AveragePrice *big.Rat
+	WorstPrice   *big.Rat
+
+	// PriceImpact is how much worse AveragePrice is than BestPrice, as a
+	// fraction of BestPrice.
+	PriceImpact *big.Rat
+}
+
+// Run loads the order book and keeps it up to date until `ctx` is done or
+// either of its streams fails.
+func (b *Book) Run(ctx context.Context) error {
+	summary, err := b.Client.LoadOrderBookContext(ctx, b.Selling, b.Buying)
+	if err != nil {
+		return errors.Wrap(err, "load order book failed")
+	}
+	err = b.Apply(summary)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, 2)
+	go func() {
+		errs <- b.Client.StreamOrderBook(ctx, b.Selling, b.Buying, b.Apply)
+	}()
+	go func() {
+		now := horizon.Cursor("now")
+		errs <- b.Client.StreamTrades(ctx, b.Selling, b.Buying, &now, b.ApplyTrade)
+	}()
+
+	// either stream stopping ends the other, and the first error wins
+	err = <-errs
+	cancel()
+	if err2 := <-errs; err == nil {
+		err = err2
+	}
+	return errors.Wrap(err, "stream failed")
+}
+
+// Apply replaces the book with `summary`.
+func (b *Book) Apply(summary horizon.OrderBookSummary) error {
+	asks, err := levels(summary.Asks, false)
+	if err != nil {
+		return errors.Wrap(err, "invalid asks")
+	}
+	bids, err := levels(summary.Bids, true)
+	if err != nil {
+		return errors.Wrap(err, "invalid bids")
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.asks, b.bids, b.loaded = asks, bids, true
+	return nil
+}
+
+// ApplyTrade removes the amount filled by `trade` from the level of the offer
+// it filled.  Trades between other assets are ignored.
+func (b *Book) ApplyTrade(trade horizon.Trade) error {
+	base := horizon.Asset{Type: trade.BaseAssetType, Code: trade.BaseAssetCode, Issuer: trade.BaseAssetIssuer}
+	counter := horizon.Asset{Type: trade.CounterAssetType, Code: trade.CounterAssetCode, Issuer: trade.CounterAssetIssuer}
+
+	price, err := ratPrice(trade.Price)
+	if err != nil {
+		return errors.Wrap(err, "invalid trade price")
+	}
+
+	// the offer filled is the one selling the base asset of the trade if
+	// base_is_seller is set, and the one selling its counter asset if not
+	var filled *big.Rat
+	ask := trade.BaseIsSeller
+	switch {
+	case base == b.Selling && counter == b.Buying:
+		filled, err = parseAmount(trade.BaseAmount)
+	case base == b.Buying && counter == b.Selling:
+		filled, err = parseAmount(trade.CounterAmount)
+		price.Inv(price)
+		ask = !ask
+	default:
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "invalid trade amount")
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if ask {
+		b.asks = fill(b.asks, price, filled)
+	} else {
+		b.bids = fill(b.bids, price, filled)
+	}
+	return nil
+}
+
+// BestBid returns the highest price offered for the base asset.
+func (b *Book) BestBid() (*big.Rat, error) {
+	return b.best(func() []level { return b.bids })
+}
+
+// BestAsk returns the lowest price the base asset is offered at.
+func (b *Book) BestAsk() (*big.Rat, error) {
+	return b.best(func() []level { return b.asks })
+}
+
+// Spread returns the difference between the best ask and the best bid.
+func (b *Book) Spread() (*big.Rat, error) {
+	bid, err := b.BestBid()
+	if err != nil {
+		return nil, err
+	}
+	ask, err := b.BestAsk()
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Rat).Sub(ask, bid), nil
+}
+
+// QuoteBuy returns the cost of buying `amt` of the base asset from the asks.
+func (b *Book) QuoteBuy(amt string) (Quote, error) {
+	return b.quote(amt, func() []level { return b.asks })
+}
+
+// QuoteSell returns the proceeds of selling `amt` of the base asset to the
+// bids.
+func (b *Book) QuoteSell(amt string) (Quote, error) {
+	return b.quote(amt, func() []level { return b.bids })
+}
+
+func (b *Book) best(side func() []level) (*big.Rat, error) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	if !b.loaded {
+		return nil, ErrNotLoaded
+	}
+	levels := side()
+	if len(levels) == 0 {
+		return nil, ErrEmpty
+	}
+	return new(big.Rat).Set(levels[0].price), nil
+}
+
+// quote fills `amt` from the levels of `side`, best price first.
+func (b *Book) quote(amt string, side func() []level) (Quote, error) {
+	want, err := parseAmount(amt)
+	if err != nil {
+		return Quote{}, errors.Wrap(err, "invalid amount")
+	}
+	if want.Sign() <= 0 {
+		return Quote{}, errors.New("amount must be positive")
+	}
+
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	if !b.loaded {
+		return Quote{}, ErrNotLoaded
+	}
+
+	q := Quote{Amount: want, Total: new(big.Rat)}
+	remaining := new(big.Rat).Set(want)
+	for _, l := range side() {
+		if remaining.Sign() == 0 {
+			break
+		}
+
+		take := l.amount
+		if remaining.Cmp(take) < 0 {
+			take = remaining
+		}
+		q.Total.Add(q.Total, new(big.Rat).Mul(take, l.price))
+		remaining = new(big.Rat).Sub(remaining, take)
+
+		if q.BestPrice == nil {
+			q.BestPrice = new(big.Rat).Set(l.price)
+		}
+		q.WorstPrice = new(big.Rat).Set(l.price)
+	}
+
+	if remaining.Sign() > 0 {
+		return Quote{}, ErrInsufficientLiquidity
+	}
+
+	q.AveragePrice = new(big.Rat).Quo(q.Total, q.Amount)
+	impact := new(big.Rat).Sub(q.AveragePrice, q.BestPrice)
+	q.PriceImpact = impact.Quo(impact.Abs(impact), q.BestPrice)
+	return q, nil
+}
+
+// levels converts horizon's price levels to levels with amounts in units of
+// the base asset.  The amounts of bids are in units of the counter asset, the
+// one their offers sell, so they are converted at the level's price.
+func levels(pls []horizon.PriceLevel, bids bool) ([]level, error) {
+	result := make([]level, 0, len(pls))
+	for _, pl := range pls {
+		price, err := ratPrice(pl.PriceR)
+		if err != nil {
+			return nil, err
+		}
+
+		amt, err := parseAmount(pl.Amount)
+		if err != nil {
+			return nil, err
+		}
+		if bids {
+			amt.Quo(amt, price)
+		}
+
+		result = append(result, level{price: price, amount: amt})
+	}
+	return result, nil
+}
+
+// fill removes `filled` of the base asset from the level at `price`,
+// dropping the level once it is empty.
+func fill(levels []level, price, filled *big.Rat) []level {
+	for i, l := range levels {
+		if l.price.Cmp(price) != 0 {
+			continue
+		}
+
+		remaining := new(big.Rat).Sub(l.amount, filled)
+		if remaining.Sign() <= 0 {
+			return append(levels[:i], levels[i+1:]...)
+		}
+		levels[i].amount = remaining
+		return levels
+	}
+	return levels
+}
+
+// ratPrice returns `p` as an exact rational.
+func ratPrice(p horizon.Price) (*big.Rat, error) {
+	price := xdr.Price{N: xdr.Int32(p.N), D: xdr.Int32(p.D)}
+	if price.N <= 0 || price.D <= 0 {
+		return nil, errors.Errorf("invalid price %d/%d", p.N, p.D)
+	}
+	return big.NewRat(int64(price.N), int64(price.D)), nil
+}
+
+// parseAmount returns the amount `v`, a decimal string, as an exact rational.
+func parseAmount(v string) (*big.Rat, error) {
+	stroops, err := amount.Parse(v)
+	if err != nil {
+		return nil, err
+	}
+	return big.NewRat(int64(stroops), amount.One), nil
+}
@@ -0,0 +1,205 @@
+package orderbook
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/clients/horizon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+const issuer = "GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN"
+
+var (
+	native = horizon.Asset{Type: "native"}
+	usd    = horizon.Asset{Type: "credit_alphanum4", Code: "USD", Issuer: issuer}
+)
+
+func pl(n, d int32, amt string) horizon.PriceLevel {
+	return horizon.PriceLevel{PriceR: horizon.Price{N: n, D: d}, Amount: amt}
+}
+
+// summary is an order book for lumens in dollars: asks of 100 XLM at 0.25 and
+// 200 XLM at 0.30, and bids of 20 USD (100 XLM) at 0.20 and 30 USD (200 XLM)
+// at 0.15.
+func summary() horizon.OrderBookSummary {
+	return horizon.OrderBookSummary{
+		Asks:    []horizon.PriceLevel{pl(1, 4, "100.0000000"), pl(3, 10, "200.0000000")},
+		Bids:    []horizon.PriceLevel{pl(1, 5, "20.0000000"), pl(3, 20, "30.0000000")},
+		Selling: native,
+		Buying:  usd,
+	}
+}
+
+func rat(s string) *big.Rat {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		panic(s)
+	}
+	return r
+}
+
+func assertRat(t *testing.T, expected string, actual *big.Rat) {
+	if assert.NotNil(t, actual) {
+		assert.Equal(t, rat(expected).RatString(), actual.RatString())
+	}
+}
+
+func TestBook_Prices(t *testing.T) {
+	book := &Book{Selling: native, Buying: usd}
+
+	_, err := book.BestBid()
+	assert.Equal(t, ErrNotLoaded, err)
+	_, err = book.QuoteBuy("1")
+	assert.Equal(t, ErrNotLoaded, err)
+
+	require.NoError(t, book.Apply(summary()))
+
+	bid, err := book.BestBid()
+	require.NoError(t, err)
+	assertRat(t, "1/5", bid)
+	ask, err := book.BestAsk()
+	require.NoError(t, err)
+	assertRat(t, "1/4", ask)
+	spread, err := book.Spread()
+	require.NoError(t, err)
+	assertRat(t, "1/20", spread)
+
+	// 100 at 0.25 and 50 at 0.30
+	q, err := book.QuoteBuy("150")
+	require.NoError(t, err)
+	assertRat(t, "150", q.Amount)
+	assertRat(t, "40", q.Total)
+	assertRat(t, "1/4", q.BestPrice)
+	assertRat(t, "4/15", q.AveragePrice)
+	assertRat(t, "3/10", q.WorstPrice)
+	assertRat(t, "1/15", q.PriceImpact)
+
+	// 100 at 0.20 and 100 at 0.15
+	q, err = book.QuoteSell("200")
+	require.NoError(t, err)
+	assertRat(t, "35", q.Total)
+	assertRat(t, "7/40", q.AveragePrice)
+	assertRat(t, "1/8", q.PriceImpact)
+
+	q, err = book.QuoteBuy("0.0000001")
+	require.NoError(t, err)
+	assertRat(t, "1/40000000", q.Total)
+	assertRat(t, "0", q.PriceImpact)
+
+	_, err = book.QuoteBuy("300.0000001")
+	assert.Equal(t, ErrInsufficientLiquidity, err)
+	_, err = book.QuoteSell("0")
+	assert.Error(t, err)
+	_, err = book.QuoteSell("lots")
+	assert.Error(t, err)
+
+	require.NoError(t, book.Apply(horizon.OrderBookSummary{}))
+	_, err = book.BestAsk()
+	assert.Equal(t, ErrEmpty, err)
+	_, err = book.Spread()
+	assert.Equal(t, ErrEmpty, err)
+}
+
+func TestBook_ApplyTrade(t *testing.T) {
+	book := &Book{Selling: native, Buying: usd}
+	require.NoError(t, book.Apply(summary()))
+
+	// a buyer takes 60 of the 100 XLM asked at 0.25
+	err := book.ApplyTrade(horizon.Trade{
+		BaseAssetType:      "native",
+		BaseAmount:         "60.0000000",
+		CounterAssetType:   "credit_alphanum4",
+		CounterAssetCode:   "USD",
+		CounterAssetIssuer: issuer,
+		CounterAmount:      "15.0000000",
+		BaseIsSeller:       true,
+		Price:              horizon.Price{N: 1, D: 4},
+	})
+	require.NoError(t, err)
+	q, err := book.QuoteBuy("40")
+	require.NoError(t, err)
+	assertRat(t, "1/4", q.WorstPrice)
+	q, err = book.QuoteBuy("41")
+	require.NoError(t, err)
+	assertRat(t, "3/10", q.WorstPrice)
+
+	// a seller fills the whole bid at 0.20, reported the other way around
+	err = book.ApplyTrade(horizon.Trade{
+		BaseAssetType:    "credit_alphanum4",
+		BaseAssetCode:    "USD",
+		BaseAssetIssuer:  issuer,
+		BaseAmount:       "20.0000000",
+		CounterAssetType: "native",
+		CounterAmount:    "100.0000000",
+		BaseIsSeller:     true,
+		Price:            horizon.Price{N: 5, D: 1},
+	})
+	require.NoError(t, err)
+	bid, err := book.BestBid()
+	require.NoError(t, err)
+	assertRat(t, "3/20", bid)
+
+	// trades of other assets are ignored
+	err = book.ApplyTrade(horizon.Trade{
+		BaseAssetType:    "native",
+		BaseAmount:       "100.0000000",
+		CounterAssetType: "credit_alphanum4",
+		CounterAssetCode: "EUR",
+		CounterAmount:    "25.0000000",
+		BaseIsSeller:     true,
+		Price:            horizon.Price{N: 1, D: 4},
+	})
+	require.NoError(t, err)
+	ask, err := book.BestAsk()
+	require.NoError(t, err)
+	assertRat(t, "1/4", ask)
+
+	err = book.ApplyTrade(horizon.Trade{Price: horizon.Price{N: 1, D: 0}})
+	assert.Error(t, err)
+}
+
+func TestBook_Run(t *testing.T) {
+	client := &horizon.MockClient{}
+	book := &Book{Client: client, Selling: native, Buying: usd}
+
+	updated := summary()
+	updated.Asks = updated.Asks[1:]
+
+	client.On("LoadOrderBookContext", mock.Anything, native, usd).Return(summary(), nil)
+	client.On("StreamOrderBook", mock.Anything, native, usd, mock.Anything).Run(func(args mock.Arguments) {
+		handler := args.Get(3).(horizon.OrderBookHandler)
+		handler(updated)
+		<-args.Get(0).(context.Context).Done()
+	}).Return(nil)
+	client.On("StreamTrades", mock.Anything, native, usd, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		assert.Equal(t, horizon.Cursor("now"), *args.Get(3).(*horizon.Cursor))
+		<-args.Get(0).(context.Context).Done()
+	}).Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- book.Run(ctx)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		ask, err := book.BestAsk()
+		if err == nil && ask.Cmp(rat("3/10")) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("order book was not updated")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+	client.AssertExpectations(t)
+}
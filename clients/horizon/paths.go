@@ -0,0 +1,111 @@
+package horizon
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/build"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// ErrNoPath is returned by PayWithCheapestPath when no path sends the asset
+// asked for.
+var ErrNoPath = errors.New("no path found")
+
+// SourceAsset returns the asset sent along the path.
+func (p Path) SourceAsset() Asset {
+	return Asset{Type: p.SourceAssetType, Code: p.SourceAssetCode, Issuer: p.SourceAssetIssuer}
+}
+
+// DestinationAsset returns the asset received at the end of the path.
+func (p Path) DestinationAsset() Asset {
+	return Asset{Type: p.DestinationAssetType, Code: p.DestinationAssetCode, Issuer: p.DestinationAssetIssuer}
+}
+
+// PayWith returns a mutator that makes a payment send its amount along the
+// path, spending at most the path's source amount increased by `slippage`, a
+// fraction such as 0.01 for 1%, to allow for the order books changing before
+// the payment is made.
+func (p Path) PayWith(slippage float64) (build.PayWithPath, error) {
+	if slippage < 0 {
+		return build.PayWithPath{}, errors.New("slippage is negative")
+	}
+
+	send, err := amount.Parse(p.SourceAmount)
+	if err != nil {
+		return build.PayWithPath{}, errors.Wrap(err, "invalid source amount")
+	}
+
+	// use the decimal form of the slippage, so that it is applied exactly
+	margin, ok := new(big.Rat).SetString(strconv.FormatFloat(slippage, 'f', -1, 64))
+	if !ok {
+		return build.PayWithPath{}, errors.New("invalid slippage")
+	}
+	margin.Add(margin, big.NewRat(1, 1))
+
+	max := new(big.Rat).Mul(new(big.Rat).SetInt64(int64(send)), margin)
+
+	// round up to a whole number of stroops
+	stroops := new(big.Int).Quo(max.Num(), max.Denom())
+	if !max.IsInt() {
+		stroops.Add(stroops, big.NewInt(1))
+	}
+	if !stroops.IsInt64() {
+		return build.PayWithPath{}, errors.New("max amount overflows")
+	}
+
+	result := build.PayWith(buildAsset(p.SourceAsset()), amount.String(xdr.Int64(stroops.Int64())))
+	for _, asset := range p.Path {
+		result = result.Through(buildAsset(asset))
+	}
+	return result, nil
+}
+
+// PayWithCheapestPath returns a mutator that makes a payment send
+// `sendAsset` along the cheapest of `paths` that send it, such as those loaded
+// by LoadPaths, with the maximum amount sent allowing for `slippage` as in
+// Path.PayWith.  ErrNoPath is returned if none of the paths sends the asset.
+func PayWithCheapestPath(paths []Path, sendAsset Asset, slippage float64) (build.PayWithPath, error) {
+	var (
+		cheapest *Path
+		lowest   xdr.Int64
+	)
+
+	for i := range paths {
+		if !sameAsset(paths[i].SourceAsset(), sendAsset) {
+			continue
+		}
+
+		send, err := amount.Parse(paths[i].SourceAmount)
+		if err != nil {
+			return build.PayWithPath{}, errors.Wrap(err, "invalid source amount")
+		}
+
+		if cheapest == nil || send < lowest {
+			cheapest, lowest = &paths[i], send
+		}
+	}
+
+	if cheapest == nil {
+		return build.PayWithPath{}, ErrNoPath
+	}
+	return cheapest.PayWith(slippage)
+}
+
+// buildAsset returns `asset` as a build.Asset.
+func buildAsset(asset Asset) build.Asset {
+	if asset.Type == "native" {
+		return build.NativeAsset()
+	}
+	return build.CreditAsset(asset.Code, asset.Issuer)
+}
+
+// sameAsset returns whether `a` and `b` are the same asset.
+func sameAsset(a, b Asset) bool {
+	if a.Type == "native" || b.Type == "native" {
+		return a.Type == b.Type
+	}
+	return a.Code == b.Code && a.Issuer == b.Issuer
+}
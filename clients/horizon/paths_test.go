@@ -0,0 +1,104 @@
+package horizon
+
+import (
+	"testing"
+
+	"github.com/stellar/go/build"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	pathIssuer = "GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN"
+	pathDest   = "GAEDTJ4PPEFVW5XV2S7LUXBEHNQMX5Q2GM562RJGOQG7GVCE5H3HIB4V"
+)
+
+// pathTo returns a path sending `sourceAmount` of `source` through `through`
+// to receive 20 EUR.
+func pathTo(source Asset, sourceAmount string, through ...Asset) Path {
+	return Path{
+		SourceAssetType:        source.Type,
+		SourceAssetCode:        source.Code,
+		SourceAssetIssuer:      source.Issuer,
+		SourceAmount:           sourceAmount,
+		DestinationAssetType:   "credit_alphanum4",
+		DestinationAssetCode:   "EUR",
+		DestinationAssetIssuer: pathIssuer,
+		DestinationAmount:      "20.0000000",
+		Path:                   through,
+	}
+}
+
+// pathPayment returns the path payment operation built with `pay`.
+func pathPayment(t *testing.T, pay build.PayWithPath) xdr.PathPaymentOp {
+	b := build.Payment(
+		build.Destination{AddressOrSeed: pathDest},
+		build.CreditAmount{Code: "EUR", Issuer: pathIssuer, Amount: "20"},
+		pay,
+	)
+	require.NoError(t, b.Err)
+	return b.PP
+}
+
+func TestPath_PayWith(t *testing.T) {
+	usd := Asset{Type: "credit_alphanum4", Code: "USD", Issuer: pathIssuer}
+	path := pathTo(Asset{Type: "native"}, "30.0000000", usd)
+
+	pay, err := path.PayWith(0.01)
+	require.NoError(t, err)
+	assert.Equal(t, "30.3000000", pay.MaxAmount)
+	assert.True(t, pay.Asset.Native)
+	assert.Equal(t, []build.Asset{build.CreditAsset("USD", pathIssuer)}, pay.Path)
+
+	op := pathPayment(t, pay)
+	assert.Equal(t, xdr.Int64(303000000), op.SendMax)
+	assert.Equal(t, xdr.AssetTypeAssetTypeNative, op.SendAsset.Type)
+	require.Len(t, op.Path, 1)
+	assert.Equal(t, build.CreditAsset("USD", pathIssuer).MustXDR(), op.Path[0])
+
+	// the max amount is rounded up to a whole stroop
+	pay, err = pathTo(usd, "0.0000003").PayWith(0.5)
+	require.NoError(t, err)
+	assert.Equal(t, "0.0000005", pay.MaxAmount)
+
+	pay, err = path.PayWith(0)
+	require.NoError(t, err)
+	assert.Equal(t, "30.0000000", pay.MaxAmount)
+
+	_, err = path.PayWith(-0.1)
+	assert.Error(t, err)
+
+	_, err = pathTo(usd, "lots").PayWith(0.01)
+	assert.Error(t, err)
+}
+
+func TestPayWithCheapestPath(t *testing.T) {
+	native := Asset{Type: "native"}
+	usd := Asset{Type: "credit_alphanum4", Code: "USD", Issuer: pathIssuer}
+	btc := Asset{Type: "credit_alphanum4", Code: "BTC", Issuer: pathIssuer}
+
+	paths := []Path{
+		pathTo(native, "31.0000000", usd),
+		pathTo(usd, "22.0000000"),
+		pathTo(native, "30.0000000", btc, usd),
+		pathTo(native, "32.0000000"),
+	}
+
+	pay, err := PayWithCheapestPath(paths, native, 0.1)
+	require.NoError(t, err)
+	assert.Equal(t, "33.0000000", pay.MaxAmount)
+	assert.Equal(t, []build.Asset{build.CreditAsset("BTC", pathIssuer), build.CreditAsset("USD", pathIssuer)}, pay.Path)
+
+	pay, err = PayWithCheapestPath(paths, usd, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "22.0000000", pay.MaxAmount)
+	assert.Equal(t, build.CreditAsset("USD", pathIssuer), pay.Asset)
+	assert.Empty(t, pay.Path)
+
+	_, err = PayWithCheapestPath(paths, btc, 0)
+	assert.Equal(t, ErrNoPath, err)
+
+	_, err = PayWithCheapestPath(nil, native, 0)
+	assert.Equal(t, ErrNoPath, err)
+}
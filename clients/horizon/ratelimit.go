@@ -0,0 +1,145 @@
+package horizon
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// DefaultRateLimitWait is how long a client that waits for rate limits waits
+// after a rate_limit_exceeded response that did not say when the limit
+// resets.
+const DefaultRateLimitWait = time.Second
+
+// maxRateLimitRetries is the number of times a client that waits for rate
+// limits resends a request horizon rejected for exceeding the limit.
+const maxRateLimitRetries = 3
+
+// RateLimit is the rate limit horizon reports in the X-RateLimit-* headers of
+// its responses.
+type RateLimit struct {
+	// Limit is the number of requests allowed in each period.
+	Limit int
+
+	// Remaining is the number of requests left in the current period.
+	Remaining int
+
+	// Reset is when the current period ends.
+	Reset time.Time
+}
+
+// RateLimit returns the rate limit reported by the last response horizon sent
+// to the client, and false if none was reported.
+func (c *Client) RateLimit() (RateLimit, bool) {
+	c.rateLimitLock.Lock()
+	defer c.rateLimitLock.Unlock()
+	return c.rateLimit, !c.rateLimit.Reset.IsZero()
+}
+
+// do sends `req`, which is cancelled when ctx is done, through the client's
+// middleware, and records the rate limit reported by the response.  If
+// WaitForRateLimit is set the request is delayed until the rate limit resets
+// when no requests remain, and is resent when horizon rejects it for exceeding
+// the limit.
+func (c *Client) do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if c.WaitForRateLimit {
+			err := c.waitForRateLimit(ctx)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.send(req.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		limited := resp.StatusCode == http.StatusTooManyRequests
+		c.updateRateLimit(resp.Header, limited)
+
+		retry := c.WaitForRateLimit && limited && attempt < maxRateLimitRetries
+		if !retry || (req.Body != nil && req.GetBody == nil) {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		if req.GetBody != nil {
+			req.Body, err = req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// waitForRateLimit blocks until the rate limit resets if no requests remain
+// in the current period.
+func (c *Client) waitForRateLimit(ctx context.Context) error {
+	limit, ok := c.RateLimit()
+	if !ok || limit.Remaining > 0 {
+		return nil
+	}
+
+	delay := limit.Reset.Sub(time.Now())
+	if delay <= 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
+
+// updateRateLimit records the rate limit reported in `header`.  A response
+// that was `limited` leaves no requests remaining, even if it did not report
+// the rate limit.
+func (c *Client) updateRateLimit(header http.Header, limited bool) {
+	now := time.Now()
+	limit, ok := parseRateLimit(header, now)
+	if !ok && !limited {
+		return
+	}
+
+	if limited {
+		limit.Remaining = 0
+		if !limit.Reset.After(now) {
+			limit.Reset = now.Add(DefaultRateLimitWait)
+		}
+	}
+
+	c.rateLimitLock.Lock()
+	defer c.rateLimitLock.Unlock()
+	c.rateLimit = limit
+}
+
+// parseRateLimit parses the X-RateLimit-* headers of a response received at
+// `now`, returning false if they are missing or invalid.
+func parseRateLimit(header http.Header, now time.Time) (RateLimit, bool) {
+	limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+
+	// horizon reports the number of seconds until the limit resets
+	reset, err := strconv.Atoi(header.Get("X-RateLimit-Reset"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+
+	return RateLimit{
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     now.Add(time.Duration(reset) * time.Second),
+	}, true
+}
@@ -0,0 +1,141 @@
+package horizon
+
+import (
+	"fmt"
+	"net/http"
+	stdtest "net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/support/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+const rateLimitExceeded = `{
+  "type": "https://stellar.org/horizon-errors/rate_limit_exceeded",
+  "title": "Rate Limit Exceeded",
+  "status": 429
+}`
+
+// rateLimitedServer is a stand-in for horizon that rejects the first
+// `limited` requests it receives for exceeding the rate limit, and records the
+// `tx` form value of each request.
+func rateLimitedServer(limited int) (*stdtest.Server, func() []string) {
+	var (
+		lock     sync.Mutex
+		received []string
+	)
+
+	server := stdtest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		received = append(received, r.FormValue("tx"))
+		n := len(received)
+		lock.Unlock()
+
+		w.Header().Set("X-RateLimit-Limit", "3")
+		if n <= limited {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, rateLimitExceeded)
+			return
+		}
+
+		w.Header().Set("X-RateLimit-Remaining", "2")
+		w.Header().Set("X-RateLimit-Reset", "60")
+		fmt.Fprint(w, `{"hash": "abc", "ledger": 12}`)
+	}))
+
+	return server, func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]string(nil), received...)
+	}
+}
+
+func TestParseRateLimit(t *testing.T) {
+	now := time.Unix(1000, 0)
+	header := http.Header{}
+
+	_, ok := parseRateLimit(header, now)
+	assert.False(t, ok)
+
+	header.Set("X-RateLimit-Limit", "3600")
+	header.Set("X-RateLimit-Remaining", "3599")
+	header.Set("X-RateLimit-Reset", "120")
+	limit, ok := parseRateLimit(header, now)
+	assert.True(t, ok)
+	assert.Equal(t, RateLimit{Limit: 3600, Remaining: 3599, Reset: time.Unix(1120, 0)}, limit)
+
+	header.Set("X-RateLimit-Remaining", "lots")
+	_, ok = parseRateLimit(header, now)
+	assert.False(t, ok)
+}
+
+func TestClient_RateLimit(t *testing.T) {
+	server, _ := rateLimitedServer(0)
+	defer server.Close()
+
+	client := &Client{URL: server.URL, HTTP: http.DefaultClient}
+	_, ok := client.RateLimit()
+	assert.False(t, ok)
+
+	_, err := client.SubmitTransaction("AAAA")
+	require.NoError(t, err)
+
+	limit, ok := client.RateLimit()
+	if assert.True(t, ok) {
+		assert.Equal(t, 3, limit.Limit)
+		assert.Equal(t, 2, limit.Remaining)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), limit.Reset, time.Second)
+	}
+}
+
+func TestClient_WaitForRateLimit(t *testing.T) {
+	// without waiting, exceeding the rate limit is an error
+	server, received := rateLimitedServer(1)
+	defer server.Close()
+
+	client := &Client{URL: server.URL, HTTP: http.DefaultClient}
+	_, err := client.SubmitTransaction("AAAA")
+	assert.True(t, IsRateLimitExceeded(err), "expected a rate limit error, got %v", err)
+	assert.Len(t, received(), 1)
+
+	// a rejected request is resent, body and all, once the limit resets
+	server, received = rateLimitedServer(1)
+	defer server.Close()
+
+	client = &Client{URL: server.URL, HTTP: http.DefaultClient, WaitForRateLimit: true}
+	start := time.Now()
+	tx, err := client.SubmitTransaction("AAAA")
+	require.NoError(t, err)
+	assert.Equal(t, int32(12), tx.Ledger)
+	assert.Equal(t, []string{"AAAA", "AAAA"}, received())
+	assert.True(t, time.Since(start) >= DefaultRateLimitWait)
+}
+
+func TestClient_Throttle(t *testing.T) {
+	server, received := rateLimitedServer(0)
+	defer server.Close()
+
+	// requests wait until the limit resets when none remain
+	client := &Client{URL: server.URL, HTTP: http.DefaultClient, WaitForRateLimit: true}
+	client.rateLimit = RateLimit{Limit: 3, Remaining: 0, Reset: time.Now().Add(50 * time.Millisecond)}
+
+	start := time.Now()
+	_, err := client.SubmitTransaction("AAAA")
+	require.NoError(t, err)
+	assert.True(t, time.Since(start) >= 50*time.Millisecond)
+
+	// the wait ends early if the request is cancelled
+	client.rateLimit = RateLimit{Limit: 3, Remaining: 0, Reset: time.Now().Add(time.Minute)}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = client.SubmitTransactionContext(ctx, "AAAA")
+	assert.Equal(t, context.DeadlineExceeded, errors.Cause(err))
+	assert.Len(t, received(), 1)
+}
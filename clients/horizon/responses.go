@@ -15,6 +15,28 @@ type Problem struct {
 	Extras   map[string]json.RawMessage `json:"extras,omitempty"`
 }
 
+type Root struct {
+	Links struct {
+		Account             Link `json:"account"`
+		AccountTransactions Link `json:"account_transactions"`
+		Assets              Link `json:"assets"`
+		Friendbot           Link `json:"friendbot"`
+		Metrics             Link `json:"metrics"`
+		OrderBook           Link `json:"order_book"`
+		Self                Link `json:"self"`
+		Transaction         Link `json:"transaction"`
+		Transactions        Link `json:"transactions"`
+	} `json:"_links"`
+
+	HorizonVersion       string `json:"horizon_version"`
+	StellarCoreVersion   string `json:"core_version"`
+	HorizonSequence      int32  `json:"history_latest_ledger"`
+	HistoryElderSequence int32  `json:"history_elder_ledger"`
+	CoreSequence         int32  `json:"core_latest_ledger"`
+	NetworkPassphrase    string `json:"network_passphrase"`
+	ProtocolVersion      int32  `json:"protocol_version"`
+}
+
 type Account struct {
 	Links struct {
 		Self         Link `json:"self"`
@@ -81,6 +103,18 @@ type Asset struct {
 	Issuer string `json:"asset_issuer,omitempty"`
 }
 
+type AssetStat struct {
+	Links struct {
+		Toml Link `json:"toml"`
+	} `json:"_links"`
+
+	Asset
+	PT          string       `json:"paging_token"`
+	Amount      string       `json:"amount"`
+	NumAccounts int32        `json:"num_accounts"`
+	Flags       AccountFlags `json:"flags"`
+}
+
 type Balance struct {
 	Balance string `json:"balance"`
 	Limit   string `json:"limit,omitempty"`
@@ -145,6 +179,61 @@ type OrderBookSummary struct {
 	Buying  Asset        `json:"counter"`
 }
 
+// Path is a way to send an amount of one asset that results in an amount of
+// another asset being received, through the order books of the assets in
+// Path.
+type Path struct {
+	SourceAssetType        string  `json:"source_asset_type"`
+	SourceAssetCode        string  `json:"source_asset_code,omitempty"`
+	SourceAssetIssuer      string  `json:"source_asset_issuer,omitempty"`
+	SourceAmount           string  `json:"source_amount"`
+	DestinationAssetType   string  `json:"destination_asset_type"`
+	DestinationAssetCode   string  `json:"destination_asset_code,omitempty"`
+	DestinationAssetIssuer string  `json:"destination_asset_issuer,omitempty"`
+	DestinationAmount      string  `json:"destination_amount"`
+	Path                   []Asset `json:"path"`
+}
+
+type Trade struct {
+	Links struct {
+		Self      Link `json:"self"`
+		Base      Link `json:"base"`
+		Counter   Link `json:"counter"`
+		Operation Link `json:"operation"`
+	} `json:"_links"`
+
+	ID                 string    `json:"id"`
+	PT                 string    `json:"paging_token"`
+	LedgerCloseTime    time.Time `json:"ledger_close_time"`
+	OfferID            string    `json:"offer_id"`
+	BaseAccount        string    `json:"base_account"`
+	BaseAmount         string    `json:"base_amount"`
+	BaseAssetType      string    `json:"base_asset_type"`
+	BaseAssetCode      string    `json:"base_asset_code,omitempty"`
+	BaseAssetIssuer    string    `json:"base_asset_issuer,omitempty"`
+	CounterAccount     string    `json:"counter_account"`
+	CounterAmount      string    `json:"counter_amount"`
+	CounterAssetType   string    `json:"counter_asset_type"`
+	CounterAssetCode   string    `json:"counter_asset_code,omitempty"`
+	CounterAssetIssuer string    `json:"counter_asset_issuer,omitempty"`
+	BaseIsSeller       bool      `json:"base_is_seller"`
+	Price              Price     `json:"price"`
+}
+
+// TradeAggregation summarizes the trades of an asset pair during one bucket of
+// time, which starts at Timestamp (in milliseconds since the epoch).
+type TradeAggregation struct {
+	Timestamp     int64  `json:"timestamp"`
+	TradeCount    int64  `json:"trade_count"`
+	BaseVolume    string `json:"base_volume"`
+	CounterVolume string `json:"counter_volume"`
+	Average       string `json:"avg"`
+	High          string `json:"high"`
+	Low           string `json:"low"`
+	Open          string `json:"open"`
+	Close         string `json:"close"`
+}
+
 type TransactionSuccess struct {
 	Links struct {
 		Transaction Link `json:"transaction"`
@@ -181,30 +270,13 @@ type OffersPage struct {
 	} `json:"_embedded"`
 }
 
-type Payment struct {
-	ID          string `json:"id"`
-	Type        string `json:"type"`
-	PagingToken string `json:"paging_token"`
-
+type PathsPage struct {
 	Links struct {
-		Transaction struct {
-			Href string `json:"href"`
-		} `json:"transaction"`
+		Self Link `json:"self"`
 	} `json:"_links"`
-
-	// payment/path_payment fields
-	From        string `json:"from"`
-	To          string `json:"to"`
-	AssetType   string `json:"asset_type"`
-	AssetCode   string `json:"asset_code"`
-	AssetIssuer string `json:"asset_issuer"`
-	Amount      string `json:"amount"`
-
-	// transaction fields
-	Memo struct {
-		Type  string `json:"memo_type"`
-		Value string `json:"memo"`
-	}
+	Embedded struct {
+		Records []Path `json:"records"`
+	} `json:"_embedded"`
 }
 
 type Price struct {
@@ -219,6 +291,16 @@ type PriceLevel struct {
 }
 
 type Transaction struct {
+	Links struct {
+		Self       Link `json:"self"`
+		Account    Link `json:"account"`
+		Ledger     Link `json:"ledger"`
+		Operations Link `json:"operations"`
+		Effects    Link `json:"effects"`
+		Precedes   Link `json:"precedes"`
+		Succeeds   Link `json:"succeeds"`
+	} `json:"_links"`
+
 	ID              string    `json:"id"`
 	PagingToken     string    `json:"paging_token"`
 	Hash            string    `json:"hash"`
@@ -238,3 +320,58 @@ type Transaction struct {
 	ValidAfter      string    `json:"valid_after,omitempty"`
 	ValidBefore     string    `json:"valid_before,omitempty"`
 }
+
+type AssetsPage struct {
+	Links struct {
+		Self Link `json:"self"`
+		Next Link `json:"next"`
+		Prev Link `json:"prev"`
+	} `json:"_links"`
+	Embedded struct {
+		Records []AssetStat `json:"records"`
+	} `json:"_embedded"`
+}
+
+type LedgersPage struct {
+	Links struct {
+		Self Link `json:"self"`
+		Next Link `json:"next"`
+		Prev Link `json:"prev"`
+	} `json:"_links"`
+	Embedded struct {
+		Records []Ledger `json:"records"`
+	} `json:"_embedded"`
+}
+
+type TradeAggregationsPage struct {
+	Links struct {
+		Self Link `json:"self"`
+		Next Link `json:"next"`
+		Prev Link `json:"prev"`
+	} `json:"_links"`
+	Embedded struct {
+		Records []TradeAggregation `json:"records"`
+	} `json:"_embedded"`
+}
+
+type TradesPage struct {
+	Links struct {
+		Self Link `json:"self"`
+		Next Link `json:"next"`
+		Prev Link `json:"prev"`
+	} `json:"_links"`
+	Embedded struct {
+		Records []Trade `json:"records"`
+	} `json:"_embedded"`
+}
+
+type TransactionsPage struct {
+	Links struct {
+		Self Link `json:"self"`
+		Next Link `json:"next"`
+		Prev Link `json:"prev"`
+	} `json:"_links"`
+	Embedded struct {
+		Records []Transaction `json:"records"`
+	} `json:"_embedded"`
+}
@@ -0,0 +1,344 @@
+package horizon
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stellar/go/support/errors"
+	"golang.org/x/net/context"
+)
+
+const (
+	// DefaultStreamRetry is the delay before the first reconnection attempt of
+	// a stream, used until horizon provides one with the SSE `retry` field.
+	DefaultStreamRetry = time.Second
+
+	// MaxStreamBackoff is the longest a stream waits between reconnection
+	// attempts.
+	MaxStreamBackoff = time.Minute
+)
+
+// StreamEventKind identifies the kind of a StreamEvent.
+type StreamEventKind int
+
+const (
+	// StreamConnected is sent when a stream connects or reconnects to horizon.
+	StreamConnected StreamEventKind = iota
+
+	// StreamHeartbeat is sent when horizon sends an event with no data,
+	// which it does to keep idle connections open.
+	StreamHeartbeat
+
+	// StreamReconnecting is sent when a stream lost its connection and will
+	// reconnect after a delay.
+	StreamReconnecting
+)
+
+// StreamEvent describes a change in the state of a stream, other than the
+// receipt of a record.
+type StreamEvent struct {
+	Kind StreamEventKind
+
+	// URL is the url of the stream.
+	URL string
+
+	// Cursor is the position from which the stream will resume if it
+	// reconnects.
+	Cursor string
+
+	// Err is the error that ended the previous connection of a
+	// StreamReconnecting event, or nil if horizon closed it.
+	Err error
+
+	// Delay is the time a StreamReconnecting stream waits before
+	// reconnecting.
+	Delay time.Duration
+}
+
+// StreamObserver is a function that is notified of StreamEvents.  It is called
+// on the goroutine doing the streaming, so it should return quickly.
+type StreamObserver func(StreamEvent)
+
+// sseEvent is a single server-sent event.
+type sseEvent struct {
+	Event string
+	ID    string
+	Data  []byte
+
+	// Retry is the reconnection delay requested by the server, or 0 if none
+	// was requested.
+	Retry time.Duration
+}
+
+// handlerError wraps an error returned by a stream handler, which stops the
+// stream rather than causing it to reconnect.
+type handlerError struct {
+	err error
+}
+
+func (e handlerError) Error() string {
+	return e.err.Error()
+}
+
+// rejectedError wraps the error of a request horizon rejected, which stops the
+// stream rather than causing it to reconnect.
+type rejectedError struct {
+	err error
+}
+
+func (e rejectedError) Error() string {
+	return e.err.Error()
+}
+
+// stream delivers the data of each message event received from the SSE
+// endpoint at `baseURL` to `handler`, starting from `cursor`.  When the
+// connection is lost the stream reconnects with exponential backoff, resuming
+// after the last event received.  stream returns nil when ctx is done, or the
+// error if `handler` returns one or horizon rejects the request.
+func (c *Client) stream(ctx context.Context, baseURL string, cursor *Cursor, handler func(data []byte) error) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse endpoint")
+	}
+
+	s := streamState{
+		url:   u,
+		retry: DefaultStreamRetry,
+	}
+	if cursor != nil {
+		s.cursor = string(*cursor)
+	}
+
+	attempt := 0
+	for {
+		received, err := c.streamOnce(ctx, &s, handler)
+
+		switch err := err.(type) {
+		case handlerError:
+			return err.err
+		case rejectedError:
+			return err.err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		if received {
+			attempt = 0
+		}
+		attempt++
+
+		delay := backoff(s.retry, attempt)
+		c.notify(StreamEvent{
+			Kind:   StreamReconnecting,
+			URL:    baseURL,
+			Cursor: s.cursor,
+			Err:    err,
+			Delay:  delay,
+		})
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+	}
+}
+
+// streamURL returns the url of the stream of the collection at `path`, which
+// may be scoped to a resource with the ForAccount, ForLedger or ForTransaction
+// params.
+func (c *Client) streamURL(path string, query url.Values, params []interface{}) (string, error) {
+	for _, param := range params {
+		switch param.(type) {
+		case ForAccount, ForLedger, ForTransaction:
+		default:
+			return "", fmt.Errorf("Undefined stream parameter: %+v", param)
+		}
+	}
+
+	return c.collectionURL(path, query, params)
+}
+
+// streamState is the state a stream carries from one connection to the next.
+type streamState struct {
+	url    *url.URL
+	cursor string
+	retry  time.Duration
+}
+
+// streamOnce connects to the stream and delivers its events until the
+// connection ends, returning whether any message was received.
+func (c *Client) streamOnce(ctx context.Context, s *streamState, handler func(data []byte) error) (bool, error) {
+	u := *s.url
+	query := u.Query()
+	if s.cursor != "" {
+		query.Set("cursor", s.cursor)
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if s.cursor != "" {
+		req.Header.Set("Last-Event-ID", s.cursor)
+	}
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if !(resp.StatusCode >= 200 && resp.StatusCode < 300) {
+		err = decodeResponse(resp, nil)
+		if _, ok := err.(*Error); !ok {
+			err = errors.Wrapf(err, "horizon responded with status %d", resp.StatusCode)
+		}
+
+		// horizon rejected the request, so reconnecting would not help unless
+		// it asked us to slow down
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return false, rejectedError{err}
+		}
+		return false, err
+	}
+
+	c.notify(StreamEvent{Kind: StreamConnected, URL: s.url.String(), Cursor: s.cursor})
+
+	received := false
+	reader := bufio.NewReader(resp.Body)
+	for {
+		ev, err := readEvent(reader)
+		if err == io.EOF {
+			return received, nil
+		}
+		if err != nil {
+			return received, err
+		}
+
+		if ev.Retry > 0 {
+			s.retry = ev.Retry
+		}
+
+		if ev.Event != "" && ev.Event != "message" {
+			continue
+		}
+
+		if len(ev.Data) == 0 {
+			c.notify(StreamEvent{Kind: StreamHeartbeat, URL: s.url.String(), Cursor: s.cursor})
+			continue
+		}
+
+		err = handler(ev.Data)
+		if err != nil {
+			return received, handlerError{err}
+		}
+		received = true
+
+		if ev.ID != "" {
+			s.cursor = ev.ID
+		} else if pt := pagingToken(ev.Data); pt != "" {
+			s.cursor = pt
+		}
+	}
+}
+
+// notify sends `ev` to the client's stream observer, if it has one.
+func (c *Client) notify(ev StreamEvent) {
+	if c.StreamObserver != nil {
+		c.StreamObserver(ev)
+	}
+}
+
+// backoff returns the delay before reconnection attempt number `attempt`,
+// which doubles with each attempt starting from `retry`.
+func backoff(retry time.Duration, attempt int) time.Duration {
+	delay := retry
+	for i := 1; i < attempt && delay < MaxStreamBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > MaxStreamBackoff {
+		delay = MaxStreamBackoff
+	}
+	return delay
+}
+
+// pagingToken returns the paging token of the record encoded in `data`, or
+// the empty string if it has none.
+func pagingToken(data []byte) string {
+	var record struct {
+		PT string `json:"paging_token"`
+	}
+
+	if json.Unmarshal(data, &record) != nil {
+		return ""
+	}
+	return record.PT
+}
+
+// readEvent reads the next event from `r`, returning io.EOF when the stream
+// ends.  A block of only comments, which servers send to keep connections
+// alive, is returned as an event without data.
+func readEvent(r *bufio.Reader) (ev sseEvent, err error) {
+	var (
+		data    [][]byte
+		started bool
+	)
+
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF && len(line) == 0 {
+			return ev, io.EOF
+		}
+		if err != nil && err != io.EOF {
+			return ev, err
+		}
+		line = bytes.TrimRight(line, "\r\n")
+
+		if len(line) == 0 {
+			if !started {
+				continue
+			}
+			ev.Data = bytes.Join(data, []byte("\n"))
+			return ev, nil
+		}
+		started = true
+
+		field, value := string(line), ""
+		if i := bytes.IndexByte(line, ':'); i >= 0 {
+			field = string(line[:i])
+			value = strings.TrimPrefix(string(line[i+1:]), " ")
+		}
+
+		switch field {
+		case "":
+			// a comment
+		case "event":
+			ev.Event = value
+		case "id":
+			ev.ID = value
+		case "data":
+			data = append(data, []byte(value))
+		case "retry":
+			ms, err := strconv.Atoi(value)
+			if err == nil && ms > 0 {
+				ev.Retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
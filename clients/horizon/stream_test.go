@@ -0,0 +1,474 @@
+package horizon
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	stdtest "net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+// stubResponse is a canned response of a stand-in horizon server.
+type stubResponse struct {
+	Status int
+	Body   string
+}
+
+// sseServer is a stand-in for horizon's streaming endpoints.  The n-th
+// connection is sent the n-th response and then closed; connections beyond the
+// last response are held open until the client goes away.
+type sseServer struct {
+	*stdtest.Server
+
+	lock      sync.Mutex
+	responses []stubResponse
+	requests  []*url.URL
+}
+
+func newSSEServer(responses ...stubResponse) *sseServer {
+	s := &sseServer{responses: responses}
+	s.Server = stdtest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+func (s *sseServer) serve(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	n := len(s.requests)
+	s.requests = append(s.requests, r.URL)
+	s.lock.Unlock()
+
+	if n >= len(s.responses) {
+		<-r.Context().Done()
+		return
+	}
+
+	resp := s.responses[n]
+	if resp.Status == 0 {
+		w.Header().Set("Content-Type", "text/event-stream")
+		resp.Status = http.StatusOK
+	}
+	w.WriteHeader(resp.Status)
+	fmt.Fprint(w, resp.Body)
+}
+
+// Cursors returns the cursor requested by each connection so far.
+func (s *sseServer) Cursors() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	cursors := make([]string, len(s.requests))
+	for i, u := range s.requests {
+		cursors[i] = u.Query().Get("cursor")
+	}
+	return cursors
+}
+
+// LastRequest returns the url requested by the last connection.
+func (s *sseServer) LastRequest() *url.URL {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.requests[len(s.requests)-1]
+}
+
+// recorder is a StreamObserver that records the events it receives.
+type recorder struct {
+	lock   sync.Mutex
+	events []StreamEvent
+}
+
+func (r *recorder) Observe(ev StreamEvent) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.events = append(r.events, ev)
+}
+
+func (r *recorder) Kinds() []StreamEventKind {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	kinds := make([]StreamEventKind, len(r.events))
+	for i, ev := range r.events {
+		kinds[i] = ev.Kind
+	}
+	return kinds
+}
+
+func (r *recorder) Delays() []time.Duration {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var delays []time.Duration
+	for _, ev := range r.events {
+		if ev.Kind == StreamReconnecting {
+			delays = append(delays, ev.Delay)
+		}
+	}
+	return delays
+}
+
+func ledgerEvent(id string, sequence int) string {
+	var result string
+	if id != "" {
+		result = "id: " + id + "\n"
+	}
+	return result + fmt.Sprintf("data: {\"paging_token\": \"%d\", \"sequence\": %d}\n\n", sequence, sequence)
+}
+
+func streamClient(s *sseServer, r *recorder) *Client {
+	return &Client{
+		URL:            s.URL,
+		HTTP:           http.DefaultClient,
+		StreamObserver: r.Observe,
+	}
+}
+
+func TestStream_Resumes(t *testing.T) {
+	server := newSSEServer(
+		stubResponse{Body: "retry: 10\n" + ledgerEvent("id-1", 1)},
+		stubResponse{Body: ledgerEvent("", 2)},
+		stubResponse{Body: ledgerEvent("", 3)},
+	)
+	defer server.Close()
+
+	var r recorder
+	client := streamClient(server, &r)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var sequences []int32
+	cursor := Cursor("now")
+	err := client.StreamLedgers(ctx, &cursor, func(l Ledger) error {
+		sequences = append(sequences, l.Sequence)
+		if len(sequences) == 3 {
+			cancel()
+		}
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []int32{1, 2, 3}, sequences)
+
+	// the stream resumes after the last event id, falling back to the paging
+	// token of the last record
+	assert.Equal(t, []string{"now", "id-1", "2"}, server.Cursors())
+
+	assert.Equal(t, []StreamEventKind{
+		StreamConnected,
+		StreamReconnecting,
+		StreamConnected,
+		StreamReconnecting,
+		StreamConnected,
+	}, r.Kinds())
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, 10 * time.Millisecond}, r.Delays())
+}
+
+func TestStream_Backoff(t *testing.T) {
+	server := newSSEServer(
+		stubResponse{Body: "retry: 10\n\n"},
+		stubResponse{Status: http.StatusInternalServerError, Body: `{"title": "Internal Server Error", "status": 500}`},
+		stubResponse{Status: http.StatusTooManyRequests, Body: `{"title": "Rate Limit Exceeded", "status": 429}`},
+		stubResponse{Body: ledgerEvent("", 1)},
+	)
+	defer server.Close()
+
+	var r recorder
+	client := streamClient(server, &r)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := client.StreamLedgers(ctx, nil, func(l Ledger) error {
+		cancel()
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		40 * time.Millisecond,
+	}, r.Delays())
+	assert.Equal(t, []string{"", "", "", ""}, server.Cursors())
+}
+
+func TestStream_HandlerError(t *testing.T) {
+	server := newSSEServer(
+		stubResponse{Body: ledgerEvent("", 1) + ledgerEvent("", 2)},
+	)
+	defer server.Close()
+
+	var r recorder
+	client := streamClient(server, &r)
+	stop := errors.New("stop")
+
+	calls := 0
+	err := client.StreamLedgers(context.Background(), nil, func(l Ledger) error {
+		calls++
+		return stop
+	})
+
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 1, calls)
+	assert.Len(t, server.Cursors(), 1)
+}
+
+func TestStream_Rejected(t *testing.T) {
+	server := newSSEServer(
+		stubResponse{Status: http.StatusNotFound, Body: notFoundResponse},
+	)
+	defer server.Close()
+
+	var r recorder
+	client := streamClient(server, &r)
+
+	err := client.StreamPayments(context.Background(), "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H", nil, func(Operation) error {
+		return nil
+	})
+
+	herr, ok := err.(*Error)
+	if assert.True(t, ok, "expected a horizon error, got %v", err) {
+		assert.Equal(t, "Resource Missing", herr.Problem.Title)
+	}
+	assert.Empty(t, r.Kinds())
+}
+
+func TestStream_RejectedWithoutProblem(t *testing.T) {
+	server := newSSEServer(
+		stubResponse{Status: http.StatusUnauthorized, Body: "<html>Unauthorized</html>"},
+	)
+	defer server.Close()
+
+	var r recorder
+	client := streamClient(server, &r)
+
+	err := client.StreamLedgers(context.Background(), nil, func(Ledger) error {
+		return nil
+	})
+
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "status 401")
+	}
+	assert.Empty(t, r.Kinds())
+	assert.Len(t, server.Cursors(), 1)
+}
+
+func TestStream_HandlerErrorWhileCancelling(t *testing.T) {
+	server := newSSEServer(
+		stubResponse{Body: ledgerEvent("", 1)},
+	)
+	defer server.Close()
+
+	var r recorder
+	client := streamClient(server, &r)
+	stop := errors.New("stop")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := client.StreamLedgers(ctx, nil, func(l Ledger) error {
+		cancel()
+		return stop
+	})
+
+	assert.Equal(t, stop, err)
+}
+
+func TestStream_Heartbeat(t *testing.T) {
+	server := newSSEServer(
+		stubResponse{Body: "event: open\ndata: \"hello\"\n\n: keepalive\n\n" + ledgerEvent("", 1)},
+	)
+	defer server.Close()
+
+	var r recorder
+	client := streamClient(server, &r)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := client.StreamLedgers(ctx, nil, func(l Ledger) error {
+		cancel()
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []StreamEventKind{StreamConnected, StreamHeartbeat}, r.Kinds())
+}
+
+func TestStreamOperations(t *testing.T) {
+	server := newSSEServer(stubResponse{
+		Body: `data: {"id": "1", "paging_token": "1", "type": "create_account", "starting_balance": "10000.0000000"}` + "\n\n",
+	})
+	defer server.Close()
+
+	var r recorder
+	client := streamClient(server, &r)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var received Operation
+	err := client.StreamOperations(ctx, nil, func(op Operation) error {
+		received = op
+		cancel()
+		return nil
+	}, ForAccount("GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "/accounts/GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H/operations", server.LastRequest().Path)
+	if op, ok := received.(*CreateAccount); assert.True(t, ok, "expected a *CreateAccount, got %T", received) {
+		assert.Equal(t, "10000.0000000", op.StartingBalance)
+	}
+
+	err = client.StreamOperations(ctx, nil, func(Operation) error { return nil }, Limit(10))
+	assert.EqualError(t, err, "Undefined stream parameter: 10")
+}
+
+func TestStreamEffects(t *testing.T) {
+	server := newSSEServer(stubResponse{
+		Body: `data: {"id": "1-1", "paging_token": "1-1", "type": "account_created", "starting_balance": "10000.0000000"}` + "\n\n",
+	})
+	defer server.Close()
+
+	var r recorder
+	client := streamClient(server, &r)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var received Effect
+	err := client.StreamEffects(ctx, nil, func(effect Effect) error {
+		received = effect
+		cancel()
+		return nil
+	}, ForLedger(7))
+
+	require.NoError(t, err)
+	assert.Equal(t, "/ledgers/7/effects", server.LastRequest().Path)
+	if effect, ok := received.(*AccountCreated); assert.True(t, ok, "expected a *AccountCreated, got %T", received) {
+		assert.Equal(t, "10000.0000000", effect.StartingBalance)
+	}
+}
+
+func TestStreamTrades(t *testing.T) {
+	server := newSSEServer(
+		stubResponse{Body: "retry: 10\n" + `data: {"id": "1-1", "paging_token": "1-1", "base_amount": "10.0000000"}` + "\n\n"},
+		stubResponse{Body: `data: {"id": "2-1", "paging_token": "2-1", "base_amount": "20.0000000"}` + "\n\n"},
+	)
+	defer server.Close()
+
+	var r recorder
+	client := streamClient(server, &r)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	base := Asset{Type: "native"}
+	counter := Asset{Type: "credit_alphanum4", Code: "USD", Issuer: "GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN"}
+
+	var amounts []string
+	err := client.StreamTrades(ctx, base, counter, nil, func(trade Trade) error {
+		amounts = append(amounts, trade.BaseAmount)
+		if len(amounts) == 2 {
+			cancel()
+		}
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0000000", "20.0000000"}, amounts)
+	assert.Equal(t, []string{"", "1-1"}, server.Cursors())
+
+	query := server.LastRequest().Query()
+	assert.Equal(t, "/trades", server.LastRequest().Path)
+	assert.Equal(t, "native", query.Get("base_asset_type"))
+	assert.Equal(t, "USD", query.Get("counter_asset_code"))
+}
+
+func TestStreamOffers(t *testing.T) {
+	server := newSSEServer(stubResponse{Body: `data: {"id": 5, "paging_token": "5", "amount": "12.0000000"}` + "\n\n"})
+	defer server.Close()
+
+	var r recorder
+	client := streamClient(server, &r)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var received Offer
+	err := client.StreamOffers(ctx, "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H", nil, func(offer Offer) error {
+		received = offer
+		cancel()
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "/accounts/GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H/offers", server.LastRequest().Path)
+	assert.Equal(t, int64(5), received.ID)
+	assert.Equal(t, "12.0000000", received.Amount)
+}
+
+func TestStreamOrderBook(t *testing.T) {
+	server := newSSEServer(
+		stubResponse{Body: "retry: 10\n" + `data: {"bids": [{"price": "1.0", "amount": "5.0"}], "asks": []}` + "\n\n"},
+		stubResponse{Body: `data: {"bids": [], "asks": [{"price": "2.0", "amount": "3.0"}]}` + "\n\n"},
+	)
+	defer server.Close()
+
+	var r recorder
+	client := streamClient(server, &r)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	selling := Asset{Type: "native"}
+	buying := Asset{Type: "credit_alphanum4", Code: "USD", Issuer: "GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN"}
+
+	var summaries []OrderBookSummary
+	err := client.StreamOrderBook(ctx, selling, buying, func(summary OrderBookSummary) error {
+		summaries = append(summaries, summary)
+		if len(summaries) == 2 {
+			cancel()
+		}
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+	assert.Equal(t, "5.0", summaries[0].Bids[0].Amount)
+	assert.Equal(t, "2.0", summaries[1].Asks[0].Price)
+
+	// order book summaries have no paging token to resume from
+	assert.Equal(t, []string{"", ""}, server.Cursors())
+	assert.Equal(t, "/order_book", server.LastRequest().Path)
+	assert.Equal(t, "USD", server.LastRequest().Query().Get("buying_asset_code"))
+}
+
+func TestReadEvent(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader(
+		"id: 1\r\nevent: message\r\ndata: a\r\ndata:b\r\nretry: 250\r\n\r\n" +
+			"\n\ndata: c\n\n" +
+			"data: partial",
+	))
+
+	ev, err := readEvent(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "1", ev.ID)
+	assert.Equal(t, "message", ev.Event)
+	assert.Equal(t, "a\nb", string(ev.Data))
+	assert.Equal(t, 250*time.Millisecond, ev.Retry)
+
+	ev, err = readEvent(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "c", string(ev.Data))
+	assert.Equal(t, time.Duration(0), ev.Retry)
+
+	// an event cut off by the end of the stream is discarded
+	_, err = readEvent(reader)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, backoff(time.Second, 1))
+	assert.Equal(t, 4*time.Second, backoff(time.Second, 3))
+	assert.Equal(t, MaxStreamBackoff, backoff(time.Second, 100))
+	assert.Equal(t, MaxStreamBackoff, backoff(2*MaxStreamBackoff, 1))
+}
@@ -0,0 +1,235 @@
+package horizon
+
+import (
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+	"golang.org/x/net/context"
+)
+
+// DefaultSubmitRetry is the delay before the first resubmission of a
+// transaction by SubmitTransactionWithRetry, unless SubmitOptions provides
+// one.
+const DefaultSubmitRetry = time.Second
+
+// SubmitStatus is the final state of a transaction submitted with
+// SubmitTransactionWithRetry.
+type SubmitStatus int
+
+const (
+	// SubmitSucceeded means the transaction was included in a ledger.
+	SubmitSucceeded SubmitStatus = iota
+
+	// SubmitFailed means the transaction was rejected, and the rejection will
+	// not change if it is submitted again.
+	SubmitFailed
+
+	// SubmitExpired means the time bounds of the transaction ended before it
+	// was included in a ledger, so it never will be.
+	SubmitExpired
+)
+
+// SubmitOptions configures SubmitTransactionWithRetry.
+type SubmitOptions struct {
+	// NetworkPassphrase is the passphrase of the network the transaction is
+	// for, which is needed to compute its hash.  If empty, it is loaded from
+	// horizon's root resource.
+	NetworkPassphrase string
+
+	// MaxAttempts is the number of times the transaction is submitted before
+	// giving up, or 0 to retry until its time bounds end or ctx is done.
+	MaxAttempts int
+
+	// Retry is the delay before the first resubmission, which doubles with each
+	// attempt.  Defaults to DefaultSubmitRetry.
+	Retry time.Duration
+}
+
+// SubmitResult is the outcome of a transaction submitted with
+// SubmitTransactionWithRetry.
+type SubmitResult struct {
+	Status SubmitStatus
+
+	// Hash is the hex-encoded hash of the transaction.
+	Hash string
+
+	// Attempts is the number of times the transaction was submitted.
+	Attempts int
+
+	// Transaction is the response for a transaction that succeeded.
+	Transaction TransactionSuccess
+
+	// ResultCodes are the result codes of a transaction that failed or
+	// expired, when horizon provided them.
+	ResultCodes *TransactionResultCodes
+
+	// Problem is the error horizon returned for a transaction that failed or
+	// expired, if any.
+	Problem *Error
+}
+
+// SubmitTransactionWithRetry submits a transaction until it reaches a final
+// state: included in a ledger, rejected, or expired by its time bounds.
+// Submissions that end in a transport error, such as a timeout, or a 5xx
+// response are retried with exponential backoff.  Because a submission that
+// timed out may still have been applied, the transaction is looked up by hash
+// before each retry and is never reported as failed when it succeeded.
+//
+// An error is returned, instead of a result, when ctx is done, when
+// MaxAttempts is exhausted or when horizon rejects the request itself, for
+// example because the envelope is malformed.
+func (c *Client) SubmitTransactionWithRetry(
+	ctx context.Context,
+	transactionEnvelopeXdr string,
+	opts SubmitOptions,
+) (result SubmitResult, err error) {
+	var envelope xdr.TransactionEnvelope
+	err = xdr.SafeUnmarshalBase64(transactionEnvelopeXdr, &envelope)
+	if err != nil {
+		err = errors.Wrap(err, "failed to decode envelope")
+		return
+	}
+
+	if opts.NetworkPassphrase == "" {
+		var root Root
+		root, err = c.RootContext(ctx)
+		if err != nil {
+			err = errors.Wrap(err, "failed to load network passphrase")
+			return
+		}
+		opts.NetworkPassphrase = root.NetworkPassphrase
+	}
+	if opts.Retry <= 0 {
+		opts.Retry = DefaultSubmitRetry
+	}
+
+	hash, err := network.HashTransaction(&envelope.Tx, opts.NetworkPassphrase)
+	if err != nil {
+		err = errors.Wrap(err, "failed to hash transaction")
+		return
+	}
+	result.Hash = hex.EncodeToString(hash[:])
+
+	for {
+		if result.Attempts > 0 {
+			var found bool
+			found, err = c.findTransaction(ctx, &result)
+			if found || err != nil {
+				return
+			}
+
+			if expired(envelope.Tx.TimeBounds, time.Now()) {
+				result.Status = SubmitExpired
+				return
+			}
+		}
+
+		result.Attempts++
+		result.Transaction, err = c.SubmitTransactionContext(ctx, transactionEnvelopeXdr)
+		if err == nil {
+			result.Status = SubmitSucceeded
+			return
+		}
+
+		switch herr := errors.Cause(err).(type) {
+		case *Error:
+			if herr.Type() == ProblemTransactionFailed {
+				return c.submitFailed(ctx, result, herr)
+			}
+
+			status := herr.Response.StatusCode
+			if status < 500 && status != http.StatusTooManyRequests {
+				return
+			}
+		default:
+			if ctx.Err() != nil {
+				err = ctx.Err()
+				return
+			}
+		}
+
+		if opts.MaxAttempts > 0 && result.Attempts >= opts.MaxAttempts {
+			err = errors.Wrapf(err, "transaction not submitted after %d attempts", result.Attempts)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		case <-time.After(backoff(opts.Retry, result.Attempts)):
+		}
+	}
+}
+
+// submitFailed returns the result of a submission horizon rejected with
+// `herr`, a transaction_failed problem.
+func (c *Client) submitFailed(ctx context.Context, result SubmitResult, herr *Error) (SubmitResult, error) {
+	result.Problem = herr
+	result.ResultCodes, _ = herr.ResultCodes()
+
+	code := ""
+	if result.ResultCodes != nil {
+		code = result.ResultCodes.TransactionCode
+	}
+
+	switch code {
+	case "tx_too_late":
+		result.Status = SubmitExpired
+		return result, nil
+	case "tx_bad_seq":
+		// a resubmission is rejected with a bad sequence number once an
+		// earlier attempt has been applied
+		if result.Attempts > 1 {
+			found, err := c.findTransaction(ctx, &result)
+			if found || err != nil {
+				return result, err
+			}
+		}
+	}
+
+	result.Status = SubmitFailed
+	return result, nil
+}
+
+// findTransaction looks up the transaction of `result` by its hash, and marks
+// `result` as succeeded if it was found.
+func (c *Client) findTransaction(ctx context.Context, result *SubmitResult) (bool, error) {
+	tx, err := c.LoadTransactionContext(ctx, result.Hash)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+
+		// the transaction was not found, or the lookup failed.  Either way
+		// resubmitting it is safe, since stellar-core applies a transaction at
+		// most once.
+		return false, nil
+	}
+
+	result.Status = SubmitSucceeded
+	result.Problem = nil
+	result.ResultCodes = nil
+	result.Transaction = TransactionSuccess{
+		Hash:   tx.Hash,
+		Ledger: tx.Ledger,
+		Env:    tx.EnvelopeXdr,
+		Result: tx.ResultXdr,
+		Meta:   tx.ResultMetaXdr,
+	}
+	result.Transaction.Links.Transaction = tx.Links.Self
+	return true, nil
+}
+
+// expired returns whether a transaction with time bounds `tb` can no longer be
+// included in a ledger at time `now`.
+func expired(tb *xdr.TimeBounds, now time.Time) bool {
+	if tb == nil || tb.MaxTime == 0 {
+		return false
+	}
+	return now.Unix() > int64(tb.MaxTime)
+}
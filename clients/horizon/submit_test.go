@@ -0,0 +1,256 @@
+package horizon
+
+import (
+	"fmt"
+	"net/http"
+	stdtest "net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/build"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+// submitServer is a stand-in for horizon's transaction endpoints, which
+// replies to each submission and to each lookup of a transaction with the
+// next of its canned responses.  Lookups without a response are not found.
+type submitServer struct {
+	*stdtest.Server
+
+	lock        sync.Mutex
+	submissions []stubResponse
+	lookups     []stubResponse
+	submitted   int
+	looked      int
+}
+
+func newSubmitServer(submissions, lookups []stubResponse) *submitServer {
+	s := &submitServer{submissions: submissions, lookups: lookups}
+	s.Server = stdtest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+func (s *submitServer) serve(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	resp := stubResponse{Status: http.StatusNotFound, Body: notFoundResponse}
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/transactions":
+		if s.submitted < len(s.submissions) {
+			resp = s.submissions[s.submitted]
+		}
+		s.submitted++
+	case r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/transactions/"):
+		if s.looked < len(s.lookups) {
+			resp = s.lookups[s.looked]
+		}
+		s.looked++
+	}
+
+	w.WriteHeader(resp.Status)
+	fmt.Fprint(w, resp.Body)
+}
+
+// Submissions returns the number of submissions received so far.
+func (s *submitServer) Submissions() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.submitted
+}
+
+// Lookups returns the number of lookups received so far.
+func (s *submitServer) Lookups() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.looked
+}
+
+// testEnvelope returns a signed payment envelope, valid until `maxTime` unless
+// it is zero, and its hash on the test network.
+func testEnvelope(t *testing.T, maxTime time.Time) (string, string) {
+	kp, err := keypair.Random()
+	require.NoError(t, err)
+
+	tx := build.Transaction(
+		build.SourceAccount{AddressOrSeed: kp.Seed()},
+		build.Sequence{Sequence: 2},
+		build.TestNetwork,
+		build.Payment(
+			build.Destination{AddressOrSeed: "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"},
+			build.NativeAmount{Amount: "10"},
+		),
+	)
+	require.NoError(t, tx.Err)
+	if !maxTime.IsZero() {
+		tx.TX.TimeBounds = &xdr.TimeBounds{MaxTime: xdr.Uint64(maxTime.Unix())}
+	}
+
+	hash, err := tx.HashHex()
+	require.NoError(t, err)
+
+	txe := tx.Sign(kp.Seed())
+	b64, err := txe.Base64()
+	require.NoError(t, err)
+
+	return b64, hash
+}
+
+func failedSubmission(code string) stubResponse {
+	return stubResponse{
+		Status: http.StatusBadRequest,
+		Body: `{
+  "type": "https://stellar.org/horizon-errors/transaction_failed",
+  "title": "Transaction Failed",
+  "status": 400,
+  "extras": {"result_codes": {"transaction": "` + code + `"}}
+}`,
+	}
+}
+
+var (
+	gatewayTimeout = stubResponse{Status: http.StatusGatewayTimeout, Body: `<html>504 Gateway Time-out</html>`}
+	submitTimeout  = stubResponse{Status: http.StatusGatewayTimeout, Body: `{"type": "https://stellar.org/horizon-errors/timeout", "title": "Timeout", "status": 504}`}
+	submitted      = stubResponse{Status: http.StatusOK, Body: `{"hash": "abc", "ledger": 12}`}
+	foundByHash    = stubResponse{Status: http.StatusOK, Body: `{"hash": "abc", "ledger": 11, "_links": {"self": {"href": "/transactions/abc"}}}`}
+)
+
+func submitWithRetry(t *testing.T, server *submitServer, txe string, maxAttempts int) (SubmitResult, error) {
+	client := &Client{URL: server.URL, HTTP: http.DefaultClient}
+	return client.SubmitTransactionWithRetry(context.Background(), txe, SubmitOptions{
+		NetworkPassphrase: network.TestNetworkPassphrase,
+		MaxAttempts:       maxAttempts,
+		Retry:             time.Millisecond,
+	})
+}
+
+func TestSubmitTransactionWithRetry_Success(t *testing.T) {
+	txe, hash := testEnvelope(t, time.Time{})
+
+	server := newSubmitServer([]stubResponse{gatewayTimeout, submitTimeout, submitted}, nil)
+	defer server.Close()
+
+	result, err := submitWithRetry(t, server, txe, 0)
+	require.NoError(t, err)
+	assert.Equal(t, SubmitSucceeded, result.Status)
+	assert.Equal(t, hash, result.Hash)
+	assert.Equal(t, 3, result.Attempts)
+	assert.Equal(t, int32(12), result.Transaction.Ledger)
+	assert.Equal(t, 2, server.Lookups())
+}
+
+func TestSubmitTransactionWithRetry_FoundAfterTimeout(t *testing.T) {
+	txe, _ := testEnvelope(t, time.Time{})
+
+	// the first submission timed out but was applied, so the transaction is
+	// found by its hash rather than submitted again
+	server := newSubmitServer([]stubResponse{gatewayTimeout}, []stubResponse{foundByHash})
+	defer server.Close()
+
+	result, err := submitWithRetry(t, server, txe, 0)
+	require.NoError(t, err)
+	assert.Equal(t, SubmitSucceeded, result.Status)
+	assert.Equal(t, 1, result.Attempts)
+	assert.Equal(t, int32(11), result.Transaction.Ledger)
+	assert.Equal(t, "/transactions/abc", result.Transaction.Links.Transaction.Href)
+	assert.Equal(t, 1, server.Submissions())
+
+	// the transaction was applied between the lookup and its resubmission
+	server = newSubmitServer(
+		[]stubResponse{gatewayTimeout, failedSubmission("tx_bad_seq")},
+		[]stubResponse{{Status: http.StatusNotFound, Body: notFoundResponse}, foundByHash},
+	)
+	defer server.Close()
+
+	result, err = submitWithRetry(t, server, txe, 0)
+	require.NoError(t, err)
+	assert.Equal(t, SubmitSucceeded, result.Status)
+	assert.Equal(t, 2, result.Attempts)
+	assert.Nil(t, result.ResultCodes)
+}
+
+func TestSubmitTransactionWithRetry_Failed(t *testing.T) {
+	txe, _ := testEnvelope(t, time.Time{})
+
+	server := newSubmitServer([]stubResponse{failedSubmission("tx_bad_seq")}, nil)
+	defer server.Close()
+
+	result, err := submitWithRetry(t, server, txe, 0)
+	require.NoError(t, err)
+	assert.Equal(t, SubmitFailed, result.Status)
+	assert.Equal(t, 1, result.Attempts)
+	if assert.NotNil(t, result.ResultCodes) {
+		assert.Equal(t, "tx_bad_seq", result.ResultCodes.TransactionCode)
+	}
+	assert.NotNil(t, result.Problem)
+	assert.Equal(t, 0, server.Lookups())
+}
+
+func TestSubmitTransactionWithRetry_Expired(t *testing.T) {
+	// horizon rejects a transaction whose time bounds have ended
+	txe, _ := testEnvelope(t, time.Now().Add(-time.Minute))
+
+	server := newSubmitServer([]stubResponse{failedSubmission("tx_too_late")}, nil)
+	defer server.Close()
+
+	result, err := submitWithRetry(t, server, txe, 0)
+	require.NoError(t, err)
+	assert.Equal(t, SubmitExpired, result.Status)
+	assert.Equal(t, "tx_too_late", result.ResultCodes.TransactionCode)
+
+	// the time bounds end while the submission is being retried
+	server = newSubmitServer([]stubResponse{gatewayTimeout}, nil)
+	defer server.Close()
+
+	result, err = submitWithRetry(t, server, txe, 0)
+	require.NoError(t, err)
+	assert.Equal(t, SubmitExpired, result.Status)
+	assert.Equal(t, 1, result.Attempts)
+	assert.Equal(t, 1, server.Lookups())
+}
+
+func TestSubmitTransactionWithRetry_Errors(t *testing.T) {
+	txe, _ := testEnvelope(t, time.Time{})
+
+	// requests horizon rejects are not retried
+	server := newSubmitServer([]stubResponse{{
+		Status: http.StatusBadRequest,
+		Body:   `{"type": "https://stellar.org/horizon-errors/transaction_malformed", "title": "Transaction Malformed", "status": 400}`,
+	}}, nil)
+	defer server.Close()
+
+	_, err := submitWithRetry(t, server, txe, 0)
+	if herr, ok := err.(*Error); assert.True(t, ok, "expected a horizon error, got %v", err) {
+		assert.Equal(t, "Transaction Malformed", herr.Problem.Title)
+	}
+	assert.Equal(t, 1, server.Submissions())
+
+	// give up after MaxAttempts
+	server = newSubmitServer([]stubResponse{submitTimeout, submitTimeout, submitTimeout}, nil)
+	defer server.Close()
+
+	result, err := submitWithRetry(t, server, txe, 2)
+	assert.Error(t, err)
+	assert.Equal(t, 2, result.Attempts)
+	assert.Equal(t, 2, server.Submissions())
+
+	// invalid envelope
+	_, err = submitWithRetry(t, server, "not base64", 0)
+	assert.Error(t, err)
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	assert.False(t, expired(nil, now))
+	assert.False(t, expired(&xdr.TimeBounds{MinTime: 10}, now))
+	assert.False(t, expired(&xdr.TimeBounds{MaxTime: 1000}, now))
+	assert.True(t, expired(&xdr.TimeBounds{MaxTime: 999}, now))
+}
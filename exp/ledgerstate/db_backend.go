@@ -0,0 +1,157 @@
+package ledgerstate
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// DBSchema is the sql needed to create the tables used by DBBackend.
+const DBSchema = `
+CREATE TABLE ledgerstate_snapshots (
+  ledger bigint NOT NULL PRIMARY KEY
+);
+
+CREATE TABLE ledgerstate_entries (
+  ledger bigint NOT NULL,
+  ledger_key text NOT NULL,
+  entry text NOT NULL,
+  PRIMARY KEY (ledger, ledger_key)
+);
+`
+
+// DBBackend is a Backend that persists snapshots into the tables described by
+// DBSchema.
+type DBBackend struct {
+	Session *db.Session
+}
+
+type snapshotRow struct {
+	Ledger int64 `db:"ledger"`
+}
+
+type entryRow struct {
+	Ledger    int64  `db:"ledger"`
+	LedgerKey string `db:"ledger_key"`
+	Entry     string `db:"entry"`
+}
+
+// LatestSnapshot implements Backend
+func (b *DBBackend) LatestSnapshot() (*Snapshot, error) {
+	var seq int64
+	err := b.Session.GetRaw(
+		&seq,
+		"SELECT ledger FROM ledgerstate_snapshots ORDER BY ledger DESC LIMIT 1",
+	)
+
+	if b.Session.NoRows(err) {
+		return nil, ErrSnapshotNotFound
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "get latest snapshot failed")
+	}
+
+	return b.LoadSnapshot(uint32(seq))
+}
+
+// LoadSnapshot implements Backend
+func (b *DBBackend) LoadSnapshot(seq uint32) (*Snapshot, error) {
+	var row snapshotRow
+	err := b.Session.GetTable("ledgerstate_snapshots").
+		Get(&row, sq.Eq{"ledger": seq}).
+		Exec()
+
+	if b.Session.NoRows(errors.Cause(err)) {
+		return nil, ErrSnapshotNotFound
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "get snapshot failed")
+	}
+
+	var rows []entryRow
+	err = b.Session.GetTable("ledgerstate_entries").
+		Select(&rows, sq.Eq{"ledger": seq}).
+		OrderBy("ledger_key ASC").
+		Exec()
+
+	if err != nil {
+		return nil, errors.Wrap(err, "select entries failed")
+	}
+
+	snap := &Snapshot{
+		Ledger:  seq,
+		Entries: make([]xdr.LedgerEntry, len(rows)),
+	}
+
+	for i, row := range rows {
+		err = xdr.SafeUnmarshalBase64(row.Entry, &snap.Entries[i])
+		if err != nil {
+			return nil, errors.Wrap(err, "unmarshal entry failed")
+		}
+	}
+
+	return snap, nil
+}
+
+// SaveSnapshot implements Backend
+func (b *DBBackend) SaveSnapshot(snap *Snapshot) error {
+	s := b.Session.Clone()
+
+	err := s.Begin()
+	if err != nil {
+		return errors.Wrap(err, "begin failed")
+	}
+	defer s.Rollback()
+
+	seq := int64(snap.Ledger)
+
+	_, err = s.GetTable("ledgerstate_entries").
+		Delete(sq.Eq{"ledger": seq}).
+		Exec()
+	if err != nil {
+		return errors.Wrap(err, "delete entries failed")
+	}
+
+	_, err = s.GetTable("ledgerstate_snapshots").
+		Delete(sq.Eq{"ledger": seq}).
+		Exec()
+	if err != nil {
+		return errors.Wrap(err, "delete snapshot failed")
+	}
+
+	_, err = s.GetTable("ledgerstate_snapshots").
+		Insert(snapshotRow{Ledger: seq}).
+		Exec()
+	if err != nil {
+		return errors.Wrap(err, "insert snapshot failed")
+	}
+
+	if len(snap.Entries) > 0 {
+		rows := make([]interface{}, len(snap.Entries))
+		for i, entry := range snap.Entries {
+			key, err := xdr.MarshalBase64(entry.LedgerKey())
+			if err != nil {
+				return errors.Wrap(err, "marshal key failed")
+			}
+
+			raw, err := xdr.MarshalBase64(entry)
+			if err != nil {
+				return errors.Wrap(err, "marshal entry failed")
+			}
+
+			rows[i] = entryRow{Ledger: seq, LedgerKey: key, Entry: raw}
+		}
+
+		_, err = s.GetTable("ledgerstate_entries").Insert(rows...).Exec()
+		if err != nil {
+			return errors.Wrap(err, "insert entries failed")
+		}
+	}
+
+	return s.Commit()
+}
+
+var _ Backend = &DBBackend{}
@@ -0,0 +1,54 @@
+package ledgerstate
+
+import (
+	"testing"
+
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/db/dbtest"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBBackend(t *testing.T) {
+	tdb := dbtest.Postgres(t).Load(DBSchema)
+	defer tdb.Close()
+	sess := &db.Session{DB: tdb.Open()}
+	defer sess.DB.Close()
+
+	backend := &DBBackend{Session: sess}
+
+	_, err := backend.LatestSnapshot()
+	assert.Equal(t, ErrSnapshotNotFound, err)
+
+	store := Store{Backend: backend}
+	err = store.Apply(xdr.LedgerEntryChanges{
+		change(t, xdr.LedgerEntryChangeTypeLedgerEntryCreated, accountEntry(t, addressA, 100)),
+		change(t, xdr.LedgerEntryChangeTypeLedgerEntryCreated, accountEntry(t, addressB, 10)),
+	})
+	require.NoError(t, err)
+
+	_, err = store.Snapshot(5)
+	require.NoError(t, err)
+
+	// saving the same ledger twice replaces the earlier snapshot
+	_, err = store.Snapshot(5)
+	require.NoError(t, err)
+
+	restored := Store{Backend: backend}
+	err = restored.LoadLatest()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(5), restored.Ledger())
+	assert.Equal(t, 2, restored.Len())
+
+	a := accountEntry(t, addressA, 100)
+	found, err := restored.Get(&a)
+	require.NoError(t, err)
+	if assert.NotNil(t, found) {
+		assert.Equal(t, xdr.Int64(100), found.Data.MustAccount().Balance)
+	}
+
+	err = restored.Load(6)
+	assert.Equal(t, ErrSnapshotNotFound, errors.Cause(err))
+}
@@ -0,0 +1,85 @@
+// Package ledgerstate provides a local mirror of stellar ledger entries that is
+// kept up to date by replaying the `xdr.LedgerEntryChanges` emitted by
+// stellar-core.
+//
+// A Store can be restricted to the subset of entries an application cares
+// about (for example, its own accounts, trustlines and offers) by setting a
+// Filter.  Changes are applied in order and are checked for consistency
+// against the current state of the store: creating an entry that already
+// exists, or updating or removing an entry that does not exist, causes the
+// whole batch of changes to be rejected.
+//
+// Snapshots of the store can be taken at a given ledger sequence and persisted
+// using a Backend.  DBBackend is an implementation backed by a support/db
+// session.
+package ledgerstate
+
+import (
+	"errors"
+
+	"github.com/stellar/go/xdr"
+)
+
+var (
+	// ErrEntryExists is returned when a change attempts to create an entry that
+	// is already present in the store.
+	ErrEntryExists = errors.New("ledgerstate: entry already exists")
+
+	// ErrEntryMissing is returned when a change attempts to update or remove an
+	// entry that is not present in the store.
+	ErrEntryMissing = errors.New("ledgerstate: entry does not exist")
+
+	// ErrStateMismatch is returned when a "state" change reports a value for an
+	// entry that differs from the value held by the store.
+	ErrStateMismatch = errors.New("ledgerstate: state does not match stored entry")
+
+	// ErrSnapshotNotFound is returned by a Backend when no snapshot could be
+	// found for the requested ledger.
+	ErrSnapshotNotFound = errors.New("ledgerstate: snapshot not found")
+
+	// ErrNoBackend is returned when attempting to load a snapshot into a store
+	// that has no Backend configured.
+	ErrNoBackend = errors.New("ledgerstate: no backend configured")
+)
+
+// Backend represents a persistence mechanism for snapshots of a Store.
+type Backend interface {
+	// SaveSnapshot persists `snap`, replacing any snapshot previously saved for
+	// the same ledger.
+	SaveSnapshot(snap *Snapshot) error
+
+	// LoadSnapshot loads the snapshot taken at ledger `seq`, returning
+	// ErrSnapshotNotFound if none exists.
+	LoadSnapshot(seq uint32) (*Snapshot, error)
+
+	// LatestSnapshot loads the snapshot with the highest ledger sequence,
+	// returning ErrSnapshotNotFound if none exists.
+	LatestSnapshot() (*Snapshot, error)
+}
+
+// Snapshot represents the complete contents of a Store as of the close of a
+// single ledger.
+type Snapshot struct {
+	Ledger  uint32
+	Entries []xdr.LedgerEntry
+}
+
+// Store is an in-memory set of ledger entries, keyed by their `xdr.LedgerKey`.
+// The zero value is an empty store ready to use.  A Store is not safe for
+// concurrent use.
+type Store struct {
+	// Filter, when set, restricts the store to the entries whose key it
+	// accepts.  Changes to any other entry are ignored.
+	Filter func(key xdr.LedgerKey) bool
+
+	// Backend, when set, is used to persist the snapshots taken by the store.
+	Backend Backend
+
+	ledger uint32
+
+	// entries maps the base64 encoded xdr of an entry's key to the base64
+	// encoded xdr of the entry itself.  Since xdr encoding is canonical two
+	// keys encode to the same value exactly when `LedgerKey.Equals` reports
+	// them as equal.
+	entries map[string]string
+}
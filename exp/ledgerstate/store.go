@@ -0,0 +1,268 @@
+package ledgerstate
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/stellar/go/meta"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// Apply applies `changes` to the store in order.  If any change is
+// inconsistent with the state of the store none of the changes are applied and
+// an error whose cause is one of ErrEntryExists, ErrEntryMissing or
+// ErrStateMismatch is returned.
+func (s *Store) Apply(changes xdr.LedgerEntryChanges) error {
+	s.init()
+
+	// undo records the value each modified entry had prior to this call, with
+	// nil meaning that the entry did not exist.
+	undo := map[string]*string{}
+
+	for i, change := range changes {
+		err := s.applyChange(change, undo)
+		if err != nil {
+			s.rollback(undo)
+			return errors.Wrapf(err, "apply change %d failed", i)
+		}
+	}
+
+	return nil
+}
+
+// ApplyBundle applies the fee meta and then the changes of every operation
+// within `b` to the store.  Like Apply, either all of the changes are applied
+// or none are.
+func (s *Store) ApplyBundle(b *meta.Bundle) error {
+	var all xdr.LedgerEntryChanges
+	all = append(all, b.FeeMeta...)
+
+	for _, op := range b.TransactionMeta.MustOperations() {
+		all = append(all, op.Changes...)
+	}
+
+	return s.Apply(all)
+}
+
+// Get returns the entry identified by `key`, or nil if the store does not
+// contain it.
+func (s *Store) Get(key xdr.Keyer) (*xdr.LedgerEntry, error) {
+	k, err := xdr.MarshalBase64(key.LedgerKey())
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal key failed")
+	}
+
+	raw, ok := s.entries[k]
+	if !ok {
+		return nil, nil
+	}
+
+	var entry xdr.LedgerEntry
+	err = xdr.SafeUnmarshalBase64(raw, &entry)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal entry failed")
+	}
+
+	return &entry, nil
+}
+
+// Entries returns every entry in the store, ordered by their encoded key.
+func (s *Store) Entries() ([]xdr.LedgerEntry, error) {
+	keys := make([]string, 0, len(s.entries))
+	for k := range s.entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := make([]xdr.LedgerEntry, len(keys))
+	for i, k := range keys {
+		err := xdr.SafeUnmarshalBase64(s.entries[k], &result[i])
+		if err != nil {
+			return nil, errors.Wrap(err, "unmarshal entry failed")
+		}
+	}
+
+	return result, nil
+}
+
+// Ledger returns the sequence of the ledger at which the most recent snapshot
+// was taken or restored.
+func (s *Store) Ledger() uint32 {
+	return s.ledger
+}
+
+// Len returns the number of entries in the store.
+func (s *Store) Len() int {
+	return len(s.entries)
+}
+
+// Load replaces the contents of the store with the snapshot taken at ledger
+// `seq` that was persisted by the configured Backend.
+func (s *Store) Load(seq uint32) error {
+	if s.Backend == nil {
+		return ErrNoBackend
+	}
+
+	snap, err := s.Backend.LoadSnapshot(seq)
+	if err != nil {
+		return errors.Wrap(err, "load snapshot failed")
+	}
+
+	return s.Restore(snap)
+}
+
+// LoadLatest replaces the contents of the store with the most recent snapshot
+// persisted by the configured Backend.
+func (s *Store) LoadLatest() error {
+	if s.Backend == nil {
+		return ErrNoBackend
+	}
+
+	snap, err := s.Backend.LatestSnapshot()
+	if err != nil {
+		return errors.Wrap(err, "load latest snapshot failed")
+	}
+
+	return s.Restore(snap)
+}
+
+// Restore replaces the contents of the store with those of `snap`.
+func (s *Store) Restore(snap *Snapshot) error {
+	entries := make(map[string]string, len(snap.Entries))
+
+	for _, entry := range snap.Entries {
+		k, err := xdr.MarshalBase64(entry.LedgerKey())
+		if err != nil {
+			return errors.Wrap(err, "marshal key failed")
+		}
+
+		if _, exists := entries[k]; exists {
+			return errors.Wrap(ErrEntryExists, "duplicate entry in snapshot")
+		}
+
+		entries[k], err = xdr.MarshalBase64(entry)
+		if err != nil {
+			return errors.Wrap(err, "marshal entry failed")
+		}
+	}
+
+	s.entries = entries
+	s.ledger = snap.Ledger
+	return nil
+}
+
+// Snapshot marks the store as reflecting the state of the network as of the
+// close of ledger `seq` and returns a copy of its contents.  If a Backend is
+// configured, the snapshot is persisted before being returned.
+func (s *Store) Snapshot(seq uint32) (*Snapshot, error) {
+	entries, err := s.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &Snapshot{
+		Ledger:  seq,
+		Entries: entries,
+	}
+
+	if s.Backend != nil {
+		err = s.Backend.SaveSnapshot(snap)
+		if err != nil {
+			return nil, errors.Wrap(err, "save snapshot failed")
+		}
+	}
+
+	s.ledger = seq
+	return snap, nil
+}
+
+// applyChange applies a single change to the store, recording the prior value
+// of any modified entry into `undo`.
+func (s *Store) applyChange(
+	change xdr.LedgerEntryChange,
+	undo map[string]*string,
+) error {
+	key := change.LedgerKey()
+	if s.Filter != nil && !s.Filter(key) {
+		return nil
+	}
+
+	k, err := xdr.MarshalBase64(key)
+	if err != nil {
+		return errors.Wrap(err, "marshal key failed")
+	}
+
+	current, exists := s.entries[k]
+
+	var next string
+	switch change.Type {
+	case xdr.LedgerEntryChangeTypeLedgerEntryCreated:
+		if exists {
+			return ErrEntryExists
+		}
+		next, err = xdr.MarshalBase64(change.MustCreated())
+	case xdr.LedgerEntryChangeTypeLedgerEntryUpdated:
+		if !exists {
+			return ErrEntryMissing
+		}
+		next, err = xdr.MarshalBase64(change.MustUpdated())
+	case xdr.LedgerEntryChangeTypeLedgerEntryRemoved:
+		if !exists {
+			return ErrEntryMissing
+		}
+		s.record(undo, k)
+		delete(s.entries, k)
+		return nil
+	case xdr.LedgerEntryChangeTypeLedgerEntryState:
+		next, err = xdr.MarshalBase64(change.MustState())
+		if err == nil && exists {
+			if next != current {
+				return ErrStateMismatch
+			}
+			return nil
+		}
+	default:
+		return fmt.Errorf("Unknown change type: %v", change.Type)
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "marshal entry failed")
+	}
+
+	s.record(undo, k)
+	s.entries[k] = next
+	return nil
+}
+
+func (s *Store) init() {
+	if s.entries == nil {
+		s.entries = map[string]string{}
+	}
+}
+
+// record saves the current value of the entry at `k` into `undo`, unless a
+// value has already been saved for it.
+func (s *Store) record(undo map[string]*string, k string) {
+	if _, ok := undo[k]; ok {
+		return
+	}
+
+	if v, ok := s.entries[k]; ok {
+		undo[k] = &v
+	} else {
+		undo[k] = nil
+	}
+}
+
+// rollback restores the entries saved in `undo`.
+func (s *Store) rollback(undo map[string]*string) {
+	for k, v := range undo {
+		if v == nil {
+			delete(s.entries, k)
+			continue
+		}
+
+		s.entries[k] = *v
+	}
+}
@@ -0,0 +1,225 @@
+package ledgerstate
+
+import (
+	"testing"
+
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	addressA = "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"
+	addressB = "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU"
+)
+
+func TestStore_Apply(t *testing.T) {
+	var store Store
+
+	a := accountEntry(t, addressA, 100)
+	aUpdated := accountEntry(t, addressA, 50)
+	b := accountEntry(t, addressB, 10)
+
+	err := store.Apply(xdr.LedgerEntryChanges{
+		change(t, xdr.LedgerEntryChangeTypeLedgerEntryCreated, a),
+		change(t, xdr.LedgerEntryChangeTypeLedgerEntryCreated, b),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, store.Len())
+
+	err = store.Apply(xdr.LedgerEntryChanges{
+		change(t, xdr.LedgerEntryChangeTypeLedgerEntryState, a),
+		change(t, xdr.LedgerEntryChangeTypeLedgerEntryUpdated, aUpdated),
+		change(t, xdr.LedgerEntryChangeTypeLedgerEntryRemoved, b.LedgerKey()),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.Len())
+
+	found, err := store.Get(&a)
+	require.NoError(t, err)
+	if assert.NotNil(t, found) {
+		assert.Equal(t, xdr.Int64(50), found.Data.MustAccount().Balance)
+	}
+
+	found, err = store.Get(&b)
+	require.NoError(t, err)
+	assert.Nil(t, found)
+}
+
+func TestStore_ApplyInconsistent(t *testing.T) {
+	var store Store
+
+	a := accountEntry(t, addressA, 100)
+	b := accountEntry(t, addressB, 10)
+
+	err := store.Apply(xdr.LedgerEntryChanges{
+		change(t, xdr.LedgerEntryChangeTypeLedgerEntryCreated, a),
+	})
+	require.NoError(t, err)
+
+	cases := []struct {
+		Name     string
+		Changes  xdr.LedgerEntryChanges
+		Expected error
+	}{
+		{
+			Name: "create existing",
+			Changes: xdr.LedgerEntryChanges{
+				change(t, xdr.LedgerEntryChangeTypeLedgerEntryCreated, a),
+			},
+			Expected: ErrEntryExists,
+		},
+		{
+			Name: "update missing",
+			Changes: xdr.LedgerEntryChanges{
+				change(t, xdr.LedgerEntryChangeTypeLedgerEntryUpdated, b),
+			},
+			Expected: ErrEntryMissing,
+		},
+		{
+			Name: "remove missing",
+			Changes: xdr.LedgerEntryChanges{
+				change(t, xdr.LedgerEntryChangeTypeLedgerEntryRemoved, b.LedgerKey()),
+			},
+			Expected: ErrEntryMissing,
+		},
+		{
+			Name: "state mismatch",
+			Changes: xdr.LedgerEntryChanges{
+				change(t, xdr.LedgerEntryChangeTypeLedgerEntryState, accountEntry(t, addressA, 1)),
+			},
+			Expected: ErrStateMismatch,
+		},
+		{
+			Name: "partial batch",
+			Changes: xdr.LedgerEntryChanges{
+				change(t, xdr.LedgerEntryChangeTypeLedgerEntryRemoved, a.LedgerKey()),
+				change(t, xdr.LedgerEntryChangeTypeLedgerEntryCreated, b),
+				change(t, xdr.LedgerEntryChangeTypeLedgerEntryUpdated, a),
+			},
+			Expected: ErrEntryMissing,
+		},
+	}
+
+	for _, kase := range cases {
+		err := store.Apply(kase.Changes)
+		assert.Equal(t, kase.Expected, errors.Cause(err), "case %s", kase.Name)
+
+		// a failed batch must leave the store untouched
+		found, err := store.Get(&a)
+		require.NoError(t, err)
+		assert.NotNil(t, found, "case %s", kase.Name)
+		found, err = store.Get(&b)
+		require.NoError(t, err)
+		assert.Nil(t, found, "case %s", kase.Name)
+	}
+}
+
+func TestStore_Filter(t *testing.T) {
+	var watched xdr.AccountId
+	require.NoError(t, watched.SetAddress(addressA))
+
+	store := Store{
+		Filter: func(key xdr.LedgerKey) bool {
+			account, ok := key.GetAccount()
+			return ok && account.AccountId.Equals(watched)
+		},
+	}
+
+	err := store.Apply(xdr.LedgerEntryChanges{
+		change(t, xdr.LedgerEntryChangeTypeLedgerEntryCreated, accountEntry(t, addressA, 1)),
+		change(t, xdr.LedgerEntryChangeTypeLedgerEntryUpdated, accountEntry(t, addressB, 1)),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.Len())
+}
+
+func TestStore_Snapshot(t *testing.T) {
+	backend := &memoryBackend{}
+	store := Store{Backend: backend}
+
+	a := accountEntry(t, addressA, 100)
+	err := store.Apply(xdr.LedgerEntryChanges{
+		change(t, xdr.LedgerEntryChangeTypeLedgerEntryCreated, a),
+	})
+	require.NoError(t, err)
+
+	snap, err := store.Snapshot(10)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(10), snap.Ledger)
+	assert.Len(t, snap.Entries, 1)
+	assert.Equal(t, uint32(10), store.Ledger())
+
+	err = store.Apply(xdr.LedgerEntryChanges{
+		change(t, xdr.LedgerEntryChangeTypeLedgerEntryRemoved, a.LedgerKey()),
+	})
+	require.NoError(t, err)
+	_, err = store.Snapshot(11)
+	require.NoError(t, err)
+	assert.Equal(t, 0, store.Len())
+
+	err = store.Load(10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.Len())
+	assert.Equal(t, uint32(10), store.Ledger())
+
+	err = store.LoadLatest()
+	require.NoError(t, err)
+	assert.Equal(t, 0, store.Len())
+	assert.Equal(t, uint32(11), store.Ledger())
+
+	err = store.Load(12)
+	assert.Equal(t, ErrSnapshotNotFound, errors.Cause(err))
+
+	var unbacked Store
+	assert.Equal(t, ErrNoBackend, unbacked.Load(10))
+}
+
+func accountEntry(t *testing.T, address string, balance xdr.Int64) xdr.LedgerEntry {
+	var aid xdr.AccountId
+	require.NoError(t, aid.SetAddress(address))
+
+	data, err := xdr.NewLedgerEntryData(xdr.LedgerEntryTypeAccount, xdr.AccountEntry{
+		AccountId: aid,
+		Balance:   balance,
+	})
+	require.NoError(t, err)
+
+	return xdr.LedgerEntry{Data: data}
+}
+
+func change(
+	t *testing.T,
+	typ xdr.LedgerEntryChangeType,
+	value interface{},
+) xdr.LedgerEntryChange {
+	result, err := xdr.NewLedgerEntryChange(typ, value)
+	require.NoError(t, err)
+	return result
+}
+
+type memoryBackend struct {
+	snaps []*Snapshot
+}
+
+func (b *memoryBackend) LatestSnapshot() (*Snapshot, error) {
+	if len(b.snaps) == 0 {
+		return nil, ErrSnapshotNotFound
+	}
+	return b.snaps[len(b.snaps)-1], nil
+}
+
+func (b *memoryBackend) LoadSnapshot(seq uint32) (*Snapshot, error) {
+	for _, snap := range b.snaps {
+		if snap.Ledger == seq {
+			return snap, nil
+		}
+	}
+	return nil, ErrSnapshotNotFound
+}
+
+func (b *memoryBackend) SaveSnapshot(snap *Snapshot) error {
+	b.snaps = append(b.snaps, snap)
+	return nil
+}
@@ -0,0 +1,147 @@
+// Package simulator provides a deterministic, offline stand-in for the
+// transaction processing performed by stellar-core.  It is intended to let
+// applications exercise the logic that builds and submits transactions (for
+// example, payout systems) without access to a stellar network.
+//
+// A Simulator starts from a set of `xdr.LedgerEntry` values and applies
+// transaction envelopes to them, producing the `xdr.TransactionResult` and the
+// fee and transaction meta that stellar-core would have emitted.  The meta is
+// returned as a `meta.Bundle` and can therefore be consumed by the meta and
+// exp/ledgerstate packages.
+//
+// The simulator covers sequence number, fee, base reserve and signature
+// threshold checks and implements the CreateAccount, Payment, ChangeTrust,
+// AllowTrust, SetOptions, ManageData and AccountMerge operations.  ManageOffer
+// and CreatePassiveOffer are supported in a simplified form: offers are
+// created, updated and deleted on the book but are never matched against one
+// another.  Transactions holding any other operation are rejected with
+// ErrUnsupportedOperation before they are charged a fee.
+package simulator
+
+import (
+	"time"
+
+	"github.com/stellar/go/exp/ledgerstate"
+	"github.com/stellar/go/meta"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+const (
+	// DefaultBaseFee is the base fee, in stroops per operation, used by a
+	// Simulator created with New.
+	DefaultBaseFee = 100
+
+	// DefaultBaseReserve is the base reserve, in stroops, used by a Simulator
+	// created with New.
+	DefaultBaseReserve = 5000000
+
+	// MaxSigners is the maximum number of additional signers an account may
+	// have.
+	MaxSigners = 20
+)
+
+// ErrUnsupportedOperation is returned when submitting a transaction that holds
+// an operation the simulator does not implement.
+var ErrUnsupportedOperation = errors.New("unsupported operation")
+
+// Simulator represents a simulated stellar ledger.  Transactions submitted to
+// it are applied to its state immediately, in the order they are submitted.
+// A Simulator is not safe for concurrent use.
+type Simulator struct {
+	// NetworkPassphrase is the passphrase used when hashing transactions.
+	NetworkPassphrase string
+
+	// BaseFee is the minimum fee, in stroops, charged per operation.
+	BaseFee uint32
+
+	// BaseReserve is the reserve, in stroops, an account must hold for itself
+	// and for each of its subentries.  The minimum balance of an account is
+	// (2 + subentries) * BaseReserve.
+	BaseReserve xdr.Int64
+
+	// Sequence is the sequence of the ledger into which submitted transactions
+	// are applied.
+	Sequence uint32
+
+	// CloseTime is the time compared against the time bounds of submitted
+	// transactions.
+	CloseTime time.Time
+
+	store  ledgerstate.Store
+	idPool uint64
+}
+
+// Result represents the outcome of submitting a transaction to a Simulator.
+type Result struct {
+	// Hash is the hash of the submitted transaction.
+	Hash [32]byte
+
+	// Ledger is the sequence of the ledger into which the transaction was
+	// applied.
+	Ledger uint32
+
+	// Result is the result stellar-core would have produced for the
+	// transaction.
+	Result xdr.TransactionResult
+
+	// Bundle is the metadata produced when applying the transaction.  It is
+	// nil when the transaction was rejected without being applied, in which
+	// case no fee was charged.
+	Bundle *meta.Bundle
+}
+
+// Successful returns true if the transaction was applied successfully.
+func (r *Result) Successful() bool {
+	return r.Result.Result.Code == xdr.TransactionResultCodeTxSuccess
+}
+
+// New creates a new simulator for the network identified by `passphrase`,
+// starting from the state described by `entries`.
+func New(passphrase string, entries []xdr.LedgerEntry) (*Simulator, error) {
+	sim := &Simulator{
+		NetworkPassphrase: passphrase,
+		BaseFee:           DefaultBaseFee,
+		BaseReserve:       DefaultBaseReserve,
+		Sequence:          2,
+		CloseTime:         time.Unix(0, 0).UTC(),
+	}
+
+	err := sim.store.Restore(&ledgerstate.Snapshot{
+		Ledger:  sim.Sequence - 1,
+		Entries: entries,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "restore entries failed")
+	}
+
+	for _, entry := range entries {
+		offer, ok := entry.Data.GetOffer()
+		if ok && uint64(offer.OfferId) > sim.idPool {
+			sim.idPool = uint64(offer.OfferId)
+		}
+	}
+
+	return sim, nil
+}
+
+// AccountEntry returns a ledger entry for a new account identified by
+// `address` holding `balance` stroops, suitable for seeding a Simulator.
+func AccountEntry(address string, balance xdr.Int64) (xdr.LedgerEntry, error) {
+	var aid xdr.AccountId
+	err := aid.SetAddress(address)
+	if err != nil {
+		return xdr.LedgerEntry{}, errors.Wrap(err, "set address failed")
+	}
+
+	data, err := xdr.NewLedgerEntryData(xdr.LedgerEntryTypeAccount, xdr.AccountEntry{
+		AccountId:  aid,
+		Balance:    balance,
+		Thresholds: xdr.Thresholds{1, 0, 0, 0},
+	})
+	if err != nil {
+		return xdr.LedgerEntry{}, errors.Wrap(err, "make entry data failed")
+	}
+
+	return xdr.LedgerEntry{Data: data}, nil
+}
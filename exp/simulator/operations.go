@@ -0,0 +1,850 @@
+package simulator
+
+import (
+	"math"
+
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// applyOperation applies `op` using `w`, returning the operation's result.
+// The changes recorded in `w` must only be committed if the result reports
+// success.
+func (s *Simulator) applyOperation(
+	w *working,
+	tx *xdr.Transaction,
+	op xdr.Operation,
+) (xdr.OperationResult, error) {
+	source := opSource(tx, op)
+
+	entry, err := w.account(source)
+	if err != nil {
+		return xdr.OperationResult{}, err
+	}
+
+	if entry == nil {
+		return xdr.OperationResult{Code: xdr.OperationResultCodeOpNoAccount}, nil
+	}
+
+	var value interface{}
+
+	switch op.Body.Type {
+	case xdr.OperationTypeCreateAccount:
+		var code xdr.CreateAccountResultCode
+		code, err = s.createAccount(w, entry, op.Body.MustCreateAccountOp())
+		value = xdr.CreateAccountResult{Code: code}
+	case xdr.OperationTypePayment:
+		var code xdr.PaymentResultCode
+		code, err = s.payment(w, entry, op.Body.MustPaymentOp())
+		value = xdr.PaymentResult{Code: code}
+	case xdr.OperationTypeManageOffer:
+		value, err = s.manageOffer(w, entry, op.Body.MustManageOfferOp(), false)
+	case xdr.OperationTypeCreatePassiveOffer:
+		po := op.Body.MustCreatePassiveOfferOp()
+		value, err = s.manageOffer(w, entry, xdr.ManageOfferOp{
+			Selling: po.Selling,
+			Buying:  po.Buying,
+			Amount:  po.Amount,
+			Price:   po.Price,
+		}, true)
+	case xdr.OperationTypeSetOptions:
+		var code xdr.SetOptionsResultCode
+		code, err = s.setOptions(w, entry, op.Body.MustSetOptionsOp())
+		value = xdr.SetOptionsResult{Code: code}
+	case xdr.OperationTypeChangeTrust:
+		var code xdr.ChangeTrustResultCode
+		code, err = s.changeTrust(w, entry, op.Body.MustChangeTrustOp())
+		value = xdr.ChangeTrustResult{Code: code}
+	case xdr.OperationTypeAllowTrust:
+		var code xdr.AllowTrustResultCode
+		code, err = s.allowTrust(w, entry, op.Body.MustAllowTrustOp())
+		value = xdr.AllowTrustResult{Code: code}
+	case xdr.OperationTypeAccountMerge:
+		value, err = s.accountMerge(w, entry, op.Body.MustDestination())
+	case xdr.OperationTypeManageData:
+		var code xdr.ManageDataResultCode
+		code, err = s.manageData(w, entry, op.Body.MustManageDataOp())
+		value = xdr.ManageDataResult{Code: code}
+	default:
+		return xdr.OperationResult{}, errors.Wrap(ErrUnsupportedOperation, op.Body.Type.String())
+	}
+
+	if err != nil {
+		return xdr.OperationResult{}, err
+	}
+
+	return opResult(op.Body.Type, value)
+}
+
+func (s *Simulator) createAccount(
+	w *working,
+	source *xdr.LedgerEntry,
+	op xdr.CreateAccountOp,
+) (xdr.CreateAccountResultCode, error) {
+	if op.StartingBalance <= 0 {
+		return xdr.CreateAccountResultCodeCreateAccountMalformed, nil
+	}
+
+	dest, err := w.account(op.Destination)
+	if err != nil {
+		return 0, err
+	}
+
+	if dest != nil {
+		return xdr.CreateAccountResultCodeCreateAccountAlreadyExist, nil
+	}
+
+	if op.StartingBalance < s.minBalance(xdr.AccountEntry{}, 0) {
+		return xdr.CreateAccountResultCodeCreateAccountLowReserve, nil
+	}
+
+	account := source.Data.Account
+	if s.available(*account) < op.StartingBalance {
+		return xdr.CreateAccountResultCodeCreateAccountUnderfunded, nil
+	}
+
+	account.Balance -= op.StartingBalance
+
+	err = w.create(xdr.LedgerEntryTypeAccount, xdr.AccountEntry{
+		AccountId:  op.Destination,
+		Balance:    op.StartingBalance,
+		SeqNum:     xdr.SequenceNumber(uint64(s.Sequence) << 32),
+		Thresholds: xdr.Thresholds{1, 0, 0, 0},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return xdr.CreateAccountResultCodeCreateAccountSuccess, nil
+}
+
+func (s *Simulator) payment(
+	w *working,
+	source *xdr.LedgerEntry,
+	op xdr.PaymentOp,
+) (xdr.PaymentResultCode, error) {
+	if op.Amount <= 0 {
+		return xdr.PaymentResultCodePaymentMalformed, nil
+	}
+
+	dest, err := w.account(op.Destination)
+	if err != nil {
+		return 0, err
+	}
+
+	if dest == nil {
+		return xdr.PaymentResultCodePaymentNoDestination, nil
+	}
+
+	if op.Asset.Type == xdr.AssetTypeAssetTypeNative {
+		if s.available(*source.Data.Account) < op.Amount {
+			return xdr.PaymentResultCodePaymentUnderfunded, nil
+		}
+
+		if dest.Data.Account.Balance > math.MaxInt64-op.Amount {
+			return xdr.PaymentResultCodePaymentLineFull, nil
+		}
+
+		source.Data.Account.Balance -= op.Amount
+		dest.Data.Account.Balance += op.Amount
+		return xdr.PaymentResultCodePaymentSuccess, nil
+	}
+
+	issuer := issuerOf(op.Asset)
+	issuerEntry, err := w.account(issuer)
+	if err != nil {
+		return 0, err
+	}
+
+	if issuerEntry == nil {
+		return xdr.PaymentResultCodePaymentNoIssuer, nil
+	}
+
+	sourceID := source.Data.Account.AccountId
+	if !sourceID.Equals(issuer) {
+		line, err := w.trustline(sourceID, op.Asset)
+		if err != nil {
+			return 0, err
+		}
+
+		switch {
+		case line == nil:
+			return xdr.PaymentResultCodePaymentSrcNoTrust, nil
+		case !authorized(line.Data.TrustLine):
+			return xdr.PaymentResultCodePaymentSrcNotAuthorized, nil
+		case line.Data.TrustLine.Balance < op.Amount:
+			return xdr.PaymentResultCodePaymentUnderfunded, nil
+		}
+
+		line.Data.TrustLine.Balance -= op.Amount
+	}
+
+	if !op.Destination.Equals(issuer) {
+		line, err := w.trustline(op.Destination, op.Asset)
+		if err != nil {
+			return 0, err
+		}
+
+		switch {
+		case line == nil:
+			return xdr.PaymentResultCodePaymentNoTrust, nil
+		case !authorized(line.Data.TrustLine):
+			return xdr.PaymentResultCodePaymentNotAuthorized, nil
+		case line.Data.TrustLine.Limit-line.Data.TrustLine.Balance < op.Amount:
+			return xdr.PaymentResultCodePaymentLineFull, nil
+		}
+
+		line.Data.TrustLine.Balance += op.Amount
+	}
+
+	return xdr.PaymentResultCodePaymentSuccess, nil
+}
+
+func (s *Simulator) changeTrust(
+	w *working,
+	source *xdr.LedgerEntry,
+	op xdr.ChangeTrustOp,
+) (xdr.ChangeTrustResultCode, error) {
+	if op.Line.Type == xdr.AssetTypeAssetTypeNative || op.Limit < 0 {
+		return xdr.ChangeTrustResultCodeChangeTrustMalformed, nil
+	}
+
+	account := source.Data.Account
+	issuer := issuerOf(op.Line)
+	if account.AccountId.Equals(issuer) {
+		return xdr.ChangeTrustResultCodeChangeTrustSelfNotAllowed, nil
+	}
+
+	line, err := w.trustline(account.AccountId, op.Line)
+	if err != nil {
+		return 0, err
+	}
+
+	if line != nil {
+		tl := line.Data.TrustLine
+		if op.Limit < tl.Balance {
+			return xdr.ChangeTrustResultCodeChangeTrustInvalidLimit, nil
+		}
+
+		if op.Limit == 0 {
+			account.NumSubEntries--
+			err = w.remove(line.LedgerKey())
+			if err != nil {
+				return 0, err
+			}
+			return xdr.ChangeTrustResultCodeChangeTrustSuccess, nil
+		}
+
+		tl.Limit = op.Limit
+		return xdr.ChangeTrustResultCodeChangeTrustSuccess, nil
+	}
+
+	if op.Limit == 0 {
+		return xdr.ChangeTrustResultCodeChangeTrustInvalidLimit, nil
+	}
+
+	issuerEntry, err := w.account(issuer)
+	if err != nil {
+		return 0, err
+	}
+
+	if issuerEntry == nil {
+		return xdr.ChangeTrustResultCodeChangeTrustNoIssuer, nil
+	}
+
+	if account.Balance < s.minBalance(*account, 1) {
+		return xdr.ChangeTrustResultCodeChangeTrustLowReserve, nil
+	}
+
+	var flags xdr.Uint32
+	if issuerEntry.Data.Account.Flags&xdr.Uint32(xdr.AccountFlagsAuthRequiredFlag) == 0 {
+		flags = xdr.Uint32(xdr.TrustLineFlagsAuthorizedFlag)
+	}
+
+	account.NumSubEntries++
+	err = w.create(xdr.LedgerEntryTypeTrustline, xdr.TrustLineEntry{
+		AccountId: account.AccountId,
+		Asset:     op.Line,
+		Limit:     op.Limit,
+		Flags:     flags,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return xdr.ChangeTrustResultCodeChangeTrustSuccess, nil
+}
+
+func (s *Simulator) allowTrust(
+	w *working,
+	source *xdr.LedgerEntry,
+	op xdr.AllowTrustOp,
+) (xdr.AllowTrustResultCode, error) {
+	account := source.Data.Account
+
+	var (
+		asset xdr.Asset
+		err   error
+	)
+
+	switch op.Asset.Type {
+	case xdr.AssetTypeAssetTypeCreditAlphanum4:
+		asset, err = xdr.NewAsset(op.Asset.Type, xdr.AssetAlphaNum4{
+			AssetCode: op.Asset.MustAssetCode4(),
+			Issuer:    account.AccountId,
+		})
+	case xdr.AssetTypeAssetTypeCreditAlphanum12:
+		asset, err = xdr.NewAsset(op.Asset.Type, xdr.AssetAlphaNum12{
+			AssetCode: op.Asset.MustAssetCode12(),
+			Issuer:    account.AccountId,
+		})
+	default:
+		return xdr.AllowTrustResultCodeAllowTrustMalformed, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "make asset failed")
+	}
+
+	if account.Flags&xdr.Uint32(xdr.AccountFlagsAuthRequiredFlag) == 0 {
+		return xdr.AllowTrustResultCodeAllowTrustTrustNotRequired, nil
+	}
+
+	if !op.Authorize && account.Flags&xdr.Uint32(xdr.AccountFlagsAuthRevocableFlag) == 0 {
+		return xdr.AllowTrustResultCodeAllowTrustCantRevoke, nil
+	}
+
+	if op.Trustor.Equals(account.AccountId) {
+		return xdr.AllowTrustResultCodeAllowTrustSelfNotAllowed, nil
+	}
+
+	line, err := w.trustline(op.Trustor, asset)
+	if err != nil {
+		return 0, err
+	}
+
+	if line == nil {
+		return xdr.AllowTrustResultCodeAllowTrustNoTrustLine, nil
+	}
+
+	flag := xdr.Uint32(xdr.TrustLineFlagsAuthorizedFlag)
+	if op.Authorize {
+		line.Data.TrustLine.Flags |= flag
+	} else {
+		line.Data.TrustLine.Flags &^= flag
+	}
+
+	return xdr.AllowTrustResultCodeAllowTrustSuccess, nil
+}
+
+func (s *Simulator) setOptions(
+	w *working,
+	source *xdr.LedgerEntry,
+	op xdr.SetOptionsOp,
+) (xdr.SetOptionsResultCode, error) {
+	account := source.Data.Account
+
+	if op.InflationDest != nil {
+		dest, err := w.account(*op.InflationDest)
+		if err != nil {
+			return 0, err
+		}
+
+		if dest == nil {
+			return xdr.SetOptionsResultCodeSetOptionsInvalidInflation, nil
+		}
+
+		inflationDest := *op.InflationDest
+		account.InflationDest = &inflationDest
+	}
+
+	const knownFlags = xdr.AccountFlagsAuthRequiredFlag |
+		xdr.AccountFlagsAuthRevocableFlag |
+		xdr.AccountFlagsAuthImmutableFlag
+
+	if op.ClearFlags != nil || op.SetFlags != nil {
+		var clear, set xdr.Uint32
+		if op.ClearFlags != nil {
+			clear = *op.ClearFlags
+		}
+		if op.SetFlags != nil {
+			set = *op.SetFlags
+		}
+
+		if clear&set != 0 {
+			return xdr.SetOptionsResultCodeSetOptionsBadFlags, nil
+		}
+
+		if (clear|set)&^xdr.Uint32(knownFlags) != 0 {
+			return xdr.SetOptionsResultCodeSetOptionsUnknownFlag, nil
+		}
+
+		if account.Flags&xdr.Uint32(xdr.AccountFlagsAuthImmutableFlag) != 0 {
+			return xdr.SetOptionsResultCodeSetOptionsCantChange, nil
+		}
+
+		account.Flags = (account.Flags &^ clear) | set
+	}
+
+	thresholds := []struct {
+		value *xdr.Uint32
+		index xdr.ThresholdIndexes
+	}{
+		{op.MasterWeight, xdr.ThresholdIndexesThresholdMasterWeight},
+		{op.LowThreshold, xdr.ThresholdIndexesThresholdLow},
+		{op.MedThreshold, xdr.ThresholdIndexesThresholdMed},
+		{op.HighThreshold, xdr.ThresholdIndexesThresholdHigh},
+	}
+
+	for _, t := range thresholds {
+		if t.value == nil {
+			continue
+		}
+
+		if *t.value > math.MaxUint8 {
+			return xdr.SetOptionsResultCodeSetOptionsThresholdOutOfRange, nil
+		}
+
+		account.Thresholds[t.index] = byte(*t.value)
+	}
+
+	if op.HomeDomain != nil {
+		for _, c := range []byte(*op.HomeDomain) {
+			if c < 0x20 || c > 0x7e {
+				return xdr.SetOptionsResultCodeSetOptionsInvalidHomeDomain, nil
+			}
+		}
+
+		account.HomeDomain = *op.HomeDomain
+	}
+
+	if op.Signer != nil {
+		return s.setSigner(account, *op.Signer), nil
+	}
+
+	return xdr.SetOptionsResultCodeSetOptionsSuccess, nil
+}
+
+// setSigner adds, updates or (when its weight is 0) removes `signer` from
+// `account`.
+func (s *Simulator) setSigner(
+	account *xdr.AccountEntry,
+	signer xdr.Signer,
+) xdr.SetOptionsResultCode {
+	if signer.Weight > math.MaxUint8 {
+		return xdr.SetOptionsResultCodeSetOptionsBadSigner
+	}
+
+	if signer.Key.Type == xdr.SignerKeyTypeSignerKeyTypeEd25519 &&
+		signer.Key.MustEd25519() == account.AccountId.MustEd25519() {
+		return xdr.SetOptionsResultCodeSetOptionsBadSigner
+	}
+
+	for i, existing := range account.Signers {
+		if !existing.Key.Equals(signer.Key) {
+			continue
+		}
+
+		if signer.Weight == 0 {
+			account.Signers = append(account.Signers[:i], account.Signers[i+1:]...)
+			account.NumSubEntries--
+		} else {
+			account.Signers[i].Weight = signer.Weight
+		}
+
+		return xdr.SetOptionsResultCodeSetOptionsSuccess
+	}
+
+	if signer.Weight == 0 {
+		return xdr.SetOptionsResultCodeSetOptionsSuccess
+	}
+
+	if len(account.Signers) >= MaxSigners {
+		return xdr.SetOptionsResultCodeSetOptionsTooManySigners
+	}
+
+	if account.Balance < s.minBalance(*account, 1) {
+		return xdr.SetOptionsResultCodeSetOptionsLowReserve
+	}
+
+	account.Signers = append(account.Signers, signer)
+	account.NumSubEntries++
+	return xdr.SetOptionsResultCodeSetOptionsSuccess
+}
+
+func (s *Simulator) manageData(
+	w *working,
+	source *xdr.LedgerEntry,
+	op xdr.ManageDataOp,
+) (xdr.ManageDataResultCode, error) {
+	if len(op.DataName) == 0 {
+		return xdr.ManageDataResultCodeManageDataInvalidName, nil
+	}
+
+	for _, c := range []byte(op.DataName) {
+		if c < 0x20 || c > 0x7e {
+			return xdr.ManageDataResultCodeManageDataInvalidName, nil
+		}
+	}
+
+	account := source.Data.Account
+	entry, err := w.data(account.AccountId, op.DataName)
+	if err != nil {
+		return 0, err
+	}
+
+	if op.DataValue == nil {
+		if entry == nil {
+			return xdr.ManageDataResultCodeManageDataNameNotFound, nil
+		}
+
+		account.NumSubEntries--
+		err = w.remove(entry.LedgerKey())
+		if err != nil {
+			return 0, err
+		}
+		return xdr.ManageDataResultCodeManageDataSuccess, nil
+	}
+
+	if entry != nil {
+		entry.Data.Data.DataValue = *op.DataValue
+		return xdr.ManageDataResultCodeManageDataSuccess, nil
+	}
+
+	if account.Balance < s.minBalance(*account, 1) {
+		return xdr.ManageDataResultCodeManageDataLowReserve, nil
+	}
+
+	account.NumSubEntries++
+	err = w.create(xdr.LedgerEntryTypeData, xdr.DataEntry{
+		AccountId: account.AccountId,
+		DataName:  op.DataName,
+		DataValue: *op.DataValue,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return xdr.ManageDataResultCodeManageDataSuccess, nil
+}
+
+func (s *Simulator) accountMerge(
+	w *working,
+	source *xdr.LedgerEntry,
+	destination xdr.AccountId,
+) (xdr.AccountMergeResult, error) {
+	account := source.Data.Account
+
+	fail := func(code xdr.AccountMergeResultCode) (xdr.AccountMergeResult, error) {
+		return xdr.AccountMergeResult{Code: code}, nil
+	}
+
+	if account.AccountId.Equals(destination) {
+		return fail(xdr.AccountMergeResultCodeAccountMergeMalformed)
+	}
+
+	dest, err := w.account(destination)
+	if err != nil {
+		return xdr.AccountMergeResult{}, err
+	}
+
+	if dest == nil {
+		return fail(xdr.AccountMergeResultCodeAccountMergeNoAccount)
+	}
+
+	if account.Flags&xdr.Uint32(xdr.AccountFlagsAuthImmutableFlag) != 0 {
+		return fail(xdr.AccountMergeResultCodeAccountMergeImmutableSet)
+	}
+
+	if account.NumSubEntries != 0 {
+		return fail(xdr.AccountMergeResultCodeAccountMergeHasSubEntries)
+	}
+
+	balance := account.Balance
+	dest.Data.Account.Balance += balance
+
+	err = w.remove(source.LedgerKey())
+	if err != nil {
+		return xdr.AccountMergeResult{}, err
+	}
+
+	return xdr.NewAccountMergeResult(xdr.AccountMergeResultCodeAccountMergeSuccess, balance)
+}
+
+// manageOffer creates, updates or deletes an offer.  Offers are never matched
+// against the existing offers on the book.
+func (s *Simulator) manageOffer(
+	w *working,
+	source *xdr.LedgerEntry,
+	op xdr.ManageOfferOp,
+	passive bool,
+) (xdr.ManageOfferResult, error) {
+	account := source.Data.Account
+
+	fail := func(code xdr.ManageOfferResultCode) (xdr.ManageOfferResult, error) {
+		return xdr.ManageOfferResult{Code: code}, nil
+	}
+
+	if op.Amount < 0 ||
+		op.Price.N <= 0 ||
+		op.Price.D <= 0 ||
+		op.Selling.Equals(op.Buying) ||
+		(op.Amount == 0 && op.OfferId == 0) {
+		return fail(xdr.ManageOfferResultCodeManageOfferMalformed)
+	}
+
+	var existing *xdr.LedgerEntry
+	if op.OfferId != 0 {
+		var err error
+		existing, err = w.offer(account.AccountId, op.OfferId)
+		if err != nil {
+			return xdr.ManageOfferResult{}, err
+		}
+
+		if existing == nil {
+			return fail(xdr.ManageOfferResultCodeManageOfferNotFound)
+		}
+	}
+
+	success := func(effect xdr.ManageOfferEffect, offer *xdr.OfferEntry) (xdr.ManageOfferResult, error) {
+		var value interface{}
+		if offer != nil {
+			value = *offer
+		}
+
+		result, err := xdr.NewManageOfferSuccessResultOffer(effect, value)
+		if err != nil {
+			return xdr.ManageOfferResult{}, errors.Wrap(err, "make offer result failed")
+		}
+
+		return xdr.NewManageOfferResult(
+			xdr.ManageOfferResultCodeManageOfferSuccess,
+			xdr.ManageOfferSuccessResult{Offer: result},
+		)
+	}
+
+	if op.Amount == 0 {
+		account.NumSubEntries--
+		err := w.remove(existing.LedgerKey())
+		if err != nil {
+			return xdr.ManageOfferResult{}, err
+		}
+		return success(xdr.ManageOfferEffectManageOfferDeleted, nil)
+	}
+
+	sides := []struct {
+		asset                        xdr.Asset
+		noIssuer, noTrust, notAuthed xdr.ManageOfferResultCode
+		available                    *xdr.Int64
+	}{
+		{
+			asset:     op.Selling,
+			noIssuer:  xdr.ManageOfferResultCodeManageOfferSellNoIssuer,
+			noTrust:   xdr.ManageOfferResultCodeManageOfferSellNoTrust,
+			notAuthed: xdr.ManageOfferResultCodeManageOfferSellNotAuthorized,
+		},
+		{
+			asset:     op.Buying,
+			noIssuer:  xdr.ManageOfferResultCodeManageOfferBuyNoIssuer,
+			noTrust:   xdr.ManageOfferResultCodeManageOfferBuyNoTrust,
+			notAuthed: xdr.ManageOfferResultCodeManageOfferBuyNotAuthorized,
+		},
+	}
+
+	var selling xdr.Int64 = math.MaxInt64
+	sides[0].available = &selling
+
+	for _, side := range sides {
+		if side.asset.Type == xdr.AssetTypeAssetTypeNative {
+			if side.available != nil {
+				*side.available = s.available(*account)
+			}
+			continue
+		}
+
+		issuer := issuerOf(side.asset)
+		issuerEntry, err := w.account(issuer)
+		if err != nil {
+			return xdr.ManageOfferResult{}, err
+		}
+
+		if issuerEntry == nil {
+			return fail(side.noIssuer)
+		}
+
+		if account.AccountId.Equals(issuer) {
+			continue
+		}
+
+		line, err := w.trustline(account.AccountId, side.asset)
+		if err != nil {
+			return xdr.ManageOfferResult{}, err
+		}
+
+		if line == nil {
+			return fail(side.noTrust)
+		}
+
+		if !authorized(line.Data.TrustLine) {
+			return fail(side.notAuthed)
+		}
+
+		if side.available != nil {
+			*side.available = line.Data.TrustLine.Balance
+		}
+	}
+
+	if selling <= 0 {
+		return fail(xdr.ManageOfferResultCodeManageOfferUnderfunded)
+	}
+
+	if existing != nil {
+		offer := existing.Data.Offer
+		offer.Selling = op.Selling
+		offer.Buying = op.Buying
+		offer.Amount = op.Amount
+		offer.Price = op.Price
+		return success(xdr.ManageOfferEffectManageOfferUpdated, offer)
+	}
+
+	if account.Balance < s.minBalance(*account, 1) {
+		return fail(xdr.ManageOfferResultCodeManageOfferLowReserve)
+	}
+
+	s.idPool++
+	offer := xdr.OfferEntry{
+		SellerId: account.AccountId,
+		OfferId:  xdr.Uint64(s.idPool),
+		Selling:  op.Selling,
+		Buying:   op.Buying,
+		Amount:   op.Amount,
+		Price:    op.Price,
+	}
+	if passive {
+		offer.Flags = xdr.Uint32(xdr.OfferEntryFlagsPassiveFlag)
+	}
+
+	account.NumSubEntries++
+	err := w.create(xdr.LedgerEntryTypeOffer, offer)
+	if err != nil {
+		return xdr.ManageOfferResult{}, err
+	}
+
+	return success(xdr.ManageOfferEffectManageOfferCreated, &offer)
+}
+
+// available returns the amount of native currency `account` can spend without
+// dropping below its minimum balance.
+func (s *Simulator) available(account xdr.AccountEntry) xdr.Int64 {
+	return account.Balance - s.minBalance(account, 0)
+}
+
+func authorized(line *xdr.TrustLineEntry) bool {
+	return line.Flags&xdr.Uint32(xdr.TrustLineFlagsAuthorizedFlag) != 0
+}
+
+func issuerOf(asset xdr.Asset) xdr.AccountId {
+	switch asset.Type {
+	case xdr.AssetTypeAssetTypeCreditAlphanum4:
+		return asset.MustAlphaNum4().Issuer
+	case xdr.AssetTypeAssetTypeCreditAlphanum12:
+		return asset.MustAlphaNum12().Issuer
+	default:
+		return xdr.AccountId{}
+	}
+}
+
+func opResult(typ xdr.OperationType, value interface{}) (xdr.OperationResult, error) {
+	tr, err := xdr.NewOperationResultTr(typ, value)
+	if err != nil {
+		return xdr.OperationResult{}, errors.Wrap(err, "make operation result failed")
+	}
+
+	result, err := xdr.NewOperationResult(xdr.OperationResultCodeOpInner, tr)
+	if err != nil {
+		return xdr.OperationResult{}, errors.Wrap(err, "make operation result failed")
+	}
+
+	return result, nil
+}
+
+// supportedOperation returns true if the simulator implements operations of
+// type `typ`.
+func supportedOperation(typ xdr.OperationType) bool {
+	switch typ {
+	case xdr.OperationTypeCreateAccount,
+		xdr.OperationTypePayment,
+		xdr.OperationTypeManageOffer,
+		xdr.OperationTypeCreatePassiveOffer,
+		xdr.OperationTypeSetOptions,
+		xdr.OperationTypeChangeTrust,
+		xdr.OperationTypeAllowTrust,
+		xdr.OperationTypeAccountMerge,
+		xdr.OperationTypeManageData:
+		return true
+	}
+	return false
+}
+
+// successResult returns an operation result of type `typ` that reports
+// success.
+func successResult(typ xdr.OperationType) (xdr.OperationResult, error) {
+	var value interface{}
+
+	switch typ {
+	case xdr.OperationTypeCreateAccount:
+		value = xdr.CreateAccountResult{}
+	case xdr.OperationTypePayment:
+		value = xdr.PaymentResult{}
+	case xdr.OperationTypePathPayment:
+		value = xdr.PathPaymentResult{Success: &xdr.PathPaymentResultSuccess{}}
+	case xdr.OperationTypeManageOffer, xdr.OperationTypeCreatePassiveOffer:
+		value = xdr.ManageOfferResult{Success: &xdr.ManageOfferSuccessResult{
+			Offer: xdr.ManageOfferSuccessResultOffer{Offer: &xdr.OfferEntry{}},
+		}}
+	case xdr.OperationTypeSetOptions:
+		value = xdr.SetOptionsResult{}
+	case xdr.OperationTypeChangeTrust:
+		value = xdr.ChangeTrustResult{}
+	case xdr.OperationTypeAllowTrust:
+		value = xdr.AllowTrustResult{}
+	case xdr.OperationTypeAccountMerge:
+		value = xdr.AccountMergeResult{SourceAccountBalance: new(xdr.Int64)}
+	case xdr.OperationTypeInflation:
+		value = xdr.InflationResult{Payouts: &[]xdr.InflationPayout{}}
+	case xdr.OperationTypeManageData:
+		value = xdr.ManageDataResult{}
+	}
+
+	return opResult(typ, value)
+}
+
+// opSucceeded returns true if `result` reports the success of its operation.
+func opSucceeded(result xdr.OperationResult) bool {
+	if result.Code != xdr.OperationResultCodeOpInner {
+		return false
+	}
+
+	tr := result.MustTr()
+	switch tr.Type {
+	case xdr.OperationTypeCreateAccount:
+		return tr.MustCreateAccountResult().Code == xdr.CreateAccountResultCodeCreateAccountSuccess
+	case xdr.OperationTypePayment:
+		return tr.MustPaymentResult().Code == xdr.PaymentResultCodePaymentSuccess
+	case xdr.OperationTypeManageOffer:
+		return tr.MustManageOfferResult().Code == xdr.ManageOfferResultCodeManageOfferSuccess
+	case xdr.OperationTypeCreatePassiveOffer:
+		return tr.MustCreatePassiveOfferResult().Code == xdr.ManageOfferResultCodeManageOfferSuccess
+	case xdr.OperationTypeSetOptions:
+		return tr.MustSetOptionsResult().Code == xdr.SetOptionsResultCodeSetOptionsSuccess
+	case xdr.OperationTypeChangeTrust:
+		return tr.MustChangeTrustResult().Code == xdr.ChangeTrustResultCodeChangeTrustSuccess
+	case xdr.OperationTypeAllowTrust:
+		return tr.MustAllowTrustResult().Code == xdr.AllowTrustResultCodeAllowTrustSuccess
+	case xdr.OperationTypeAccountMerge:
+		return tr.MustAccountMergeResult().Code == xdr.AccountMergeResultCodeAccountMergeSuccess
+	case xdr.OperationTypeManageData:
+		return tr.MustManageDataResult().Code == xdr.ManageDataResultCodeManageDataSuccess
+	default:
+		return false
+	}
+}
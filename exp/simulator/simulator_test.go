@@ -0,0 +1,440 @@
+package simulator
+
+import (
+	"testing"
+
+	"github.com/stellar/go/build"
+	"github.com/stellar/go/exp/ledgerstate"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulator_Payments(t *testing.T) {
+	root, sim := newSimulator(t)
+	dest, err := keypair.Random()
+	require.NoError(t, err)
+
+	// mirror the simulator using only the meta it produces
+	initial, err := sim.Entries()
+	require.NoError(t, err)
+	var mirror ledgerstate.Store
+	require.NoError(t, mirror.Restore(&ledgerstate.Snapshot{Entries: initial}))
+
+	result := submit(t, sim, root,
+		build.CreateAccount(
+			build.Destination{AddressOrSeed: dest.Address()},
+			build.NativeAmount{Amount: "10"},
+		),
+		build.Payment(
+			build.Destination{AddressOrSeed: dest.Address()},
+			build.NativeAmount{Amount: "5"},
+		),
+	)
+	require.True(t, result.Successful())
+	assert.Equal(t, xdr.Int64(200), result.Result.FeeCharged)
+	require.NoError(t, mirror.ApplyBundle(result.Bundle))
+
+	account, err := sim.Account(root.Address())
+	require.NoError(t, err)
+	assert.Equal(t, xdr.Int64(9750000000-200), account.Balance)
+	assert.Equal(t, xdr.SequenceNumber(1), account.SeqNum)
+
+	account, err = sim.Account(dest.Address())
+	require.NoError(t, err)
+	assert.Equal(t, xdr.Int64(150000000), account.Balance)
+	assert.Equal(t, xdr.SequenceNumber(uint64(sim.Sequence)<<32), account.SeqNum)
+
+	// the second payment fails, reverting the first but still charging a fee
+	result = submit(t, sim, root,
+		build.Payment(
+			build.Destination{AddressOrSeed: dest.Address()},
+			build.NativeAmount{Amount: "1"},
+		),
+		build.Payment(
+			build.Destination{AddressOrSeed: keypair.Master("missing").Address()},
+			build.NativeAmount{Amount: "1"},
+		),
+	)
+	assert.Equal(t, xdr.TransactionResultCodeTxFailed, result.Result.Result.Code)
+	opResults := result.Result.Result.MustResults()
+	assert.Equal(t,
+		xdr.PaymentResultCodePaymentSuccess,
+		opResults[0].MustTr().MustPaymentResult().Code,
+	)
+	assert.Equal(t,
+		xdr.PaymentResultCodePaymentNoDestination,
+		opResults[1].MustTr().MustPaymentResult().Code,
+	)
+	assert.Empty(t, result.Bundle.TransactionMeta.MustOperations())
+	require.NoError(t, mirror.ApplyBundle(result.Bundle))
+
+	account, err = sim.Account(dest.Address())
+	require.NoError(t, err)
+	assert.Equal(t, xdr.Int64(150000000), account.Balance)
+
+	account, err = sim.Account(root.Address())
+	require.NoError(t, err)
+	assert.Equal(t, xdr.Int64(9750000000-400), account.Balance)
+	assert.Equal(t, xdr.SequenceNumber(2), account.SeqNum)
+
+	expected, err := sim.Entries()
+	require.NoError(t, err)
+	actual, err := mirror.Entries()
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestSimulator_Validation(t *testing.T) {
+	root, sim := newSimulator(t)
+	other, err := keypair.Random()
+	require.NoError(t, err)
+
+	pay := build.Payment(
+		build.Destination{AddressOrSeed: root.Address()},
+		build.NativeAmount{Amount: "1"},
+	)
+
+	cases := []struct {
+		Name     string
+		Muts     []build.TransactionMutator
+		Fee      xdr.Uint32
+		Signers  []string
+		Expected xdr.TransactionResultCode
+	}{
+		{
+			Name:     "bad seq",
+			Muts:     []build.TransactionMutator{build.Sequence{Sequence: 5}},
+			Signers:  []string{root.Seed()},
+			Expected: xdr.TransactionResultCodeTxBadSeq,
+		},
+		{
+			Name:     "bad auth",
+			Signers:  []string{other.Seed()},
+			Expected: xdr.TransactionResultCodeTxBadAuth,
+		},
+		{
+			Name:     "bad auth extra",
+			Signers:  []string{root.Seed(), other.Seed()},
+			Expected: xdr.TransactionResultCodeTxBadAuthExtra,
+		},
+		{
+			Name:     "insufficient fee",
+			Fee:      10,
+			Signers:  []string{root.Seed()},
+			Expected: xdr.TransactionResultCodeTxInsufficientFee,
+		},
+		{
+			Name: "no account",
+			Muts: []build.TransactionMutator{
+				build.SourceAccount{AddressOrSeed: other.Address()},
+			},
+			Signers:  []string{other.Seed()},
+			Expected: xdr.TransactionResultCodeTxNoAccount,
+		},
+	}
+
+	for _, kase := range cases {
+		muts := []build.TransactionMutator{
+			build.SourceAccount{AddressOrSeed: root.Address()},
+			build.Sequence{Sequence: 1},
+			build.Network{Passphrase: sim.NetworkPassphrase},
+			pay,
+		}
+		muts = append(muts, kase.Muts...)
+
+		tx := build.Transaction(muts...)
+		require.NoError(t, tx.Err, "case %s", kase.Name)
+		if kase.Fee != 0 {
+			tx.TX.Fee = kase.Fee
+		}
+		txe := tx.Sign(kase.Signers...)
+
+		result, err := sim.Submit(&txe)
+		require.NoError(t, err, "case %s", kase.Name)
+		assert.Equal(t, kase.Expected, result.Result.Result.Code, "case %s", kase.Name)
+		assert.Nil(t, result.Bundle, "case %s", kase.Name)
+		assert.Equal(t, xdr.Int64(0), result.Result.FeeCharged, "case %s", kase.Name)
+
+		account, err := sim.Account(root.Address())
+		require.NoError(t, err)
+		assert.Equal(t, xdr.Int64(9900000000), account.Balance, "case %s", kase.Name)
+	}
+}
+
+func TestSimulator_UnsupportedOperation(t *testing.T) {
+	root, sim := newSimulator(t)
+	dest, err := keypair.Random()
+	require.NoError(t, err)
+
+	before, err := sim.Entries()
+	require.NoError(t, err)
+
+	tx := build.Transaction(
+		build.SourceAccount{AddressOrSeed: root.Address()},
+		build.AutoSequence{SequenceProvider: sim},
+		build.Network{Passphrase: sim.NetworkPassphrase},
+		build.CreateAccount(
+			build.Destination{AddressOrSeed: dest.Address()},
+			build.NativeAmount{Amount: "10"},
+		),
+		build.Payment(
+			build.Destination{AddressOrSeed: dest.Address()},
+			build.NativeAmount{Amount: "5"},
+			build.PayWith(build.NativeAsset(), "5"),
+		),
+	)
+	require.NoError(t, tx.Err)
+	txe := tx.Sign(root.Seed())
+
+	result, err := sim.Submit(&txe)
+	assert.Nil(t, result)
+	assert.Equal(t, ErrUnsupportedOperation, errors.Cause(err))
+
+	// neither the fee nor the sequence number were consumed
+	after, err := sim.Entries()
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+
+	account, err := sim.Account(root.Address())
+	require.NoError(t, err)
+	assert.Equal(t, xdr.Int64(9900000000), account.Balance)
+	assert.Equal(t, xdr.SequenceNumber(0), account.SeqNum)
+}
+
+func TestSimulator_Reserve(t *testing.T) {
+	root, sim := newSimulator(t)
+	dest, err := keypair.Random()
+	require.NoError(t, err)
+
+	result := submit(t, sim, root, build.CreateAccount(
+		build.Destination{AddressOrSeed: dest.Address()},
+		build.NativeAmount{Amount: "0.9"},
+	))
+	require.Equal(t, xdr.TransactionResultCodeTxFailed, result.Result.Result.Code)
+	assert.Equal(t,
+		xdr.CreateAccountResultCodeCreateAccountLowReserve,
+		result.Result.Result.MustResults()[0].MustTr().MustCreateAccountResult().Code,
+	)
+
+	// root may spend everything but its own minimum balance
+	result = submit(t, sim, root, build.CreateAccount(
+		build.Destination{AddressOrSeed: dest.Address()},
+		build.NativeAmount{Amount: "988.9999900"},
+	))
+	require.Equal(t, xdr.TransactionResultCodeTxFailed, result.Result.Result.Code)
+	assert.Equal(t,
+		xdr.CreateAccountResultCodeCreateAccountUnderfunded,
+		result.Result.Result.MustResults()[0].MustTr().MustCreateAccountResult().Code,
+	)
+
+	result = submit(t, sim, root, build.CreateAccount(
+		build.Destination{AddressOrSeed: dest.Address()},
+		build.NativeAmount{Amount: "988.9999700"},
+	))
+	require.True(t, result.Successful())
+
+	account, err := sim.Account(root.Address())
+	require.NoError(t, err)
+	assert.Equal(t, sim.minBalance(*account, 0), account.Balance)
+}
+
+func TestSimulator_Credit(t *testing.T) {
+	root, sim := newSimulator(t)
+	issuer, err := keypair.Random()
+	require.NoError(t, err)
+	holder, err := keypair.Random()
+	require.NoError(t, err)
+
+	result := submit(t, sim, root,
+		build.CreateAccount(
+			build.Destination{AddressOrSeed: issuer.Address()},
+			build.NativeAmount{Amount: "100"},
+		),
+		build.CreateAccount(
+			build.Destination{AddressOrSeed: holder.Address()},
+			build.NativeAmount{Amount: "100"},
+		),
+	)
+	require.True(t, result.Successful())
+
+	result = submit(t, sim, issuer, build.SetOptions(
+		build.SetAuthRequired(),
+		build.SetAuthRevocable(),
+	))
+	require.True(t, result.Successful())
+
+	result = submit(t, sim, holder,
+		build.Trust("USD", issuer.Address()),
+		build.SetData("note", []byte("hello")),
+	)
+	require.True(t, result.Successful())
+
+	account, err := sim.Account(holder.Address())
+	require.NoError(t, err)
+	assert.Equal(t, xdr.Uint32(2), account.NumSubEntries)
+
+	// the trustline has not yet been authorized
+	usd := build.CreditAmount{Code: "USD", Issuer: issuer.Address(), Amount: "50"}
+	pay := build.Payment(build.Destination{AddressOrSeed: holder.Address()}, usd)
+	result = submit(t, sim, issuer, pay)
+	require.Equal(t, xdr.TransactionResultCodeTxFailed, result.Result.Result.Code)
+	assert.Equal(t,
+		xdr.PaymentResultCodePaymentNotAuthorized,
+		result.Result.Result.MustResults()[0].MustTr().MustPaymentResult().Code,
+	)
+
+	result = submit(t, sim, issuer,
+		build.AllowTrust(
+			build.Trustor{Address: holder.Address()},
+			build.AllowTrustAsset{Code: "USD"},
+			build.Authorize{Value: true},
+		),
+		pay,
+	)
+	require.True(t, result.Successful())
+
+	var asset xdr.Asset
+	var issuerID xdr.AccountId
+	require.NoError(t, issuerID.SetAddress(issuer.Address()))
+	require.NoError(t, asset.SetCredit("USD", issuerID))
+	var holderID xdr.AccountId
+	require.NoError(t, holderID.SetAddress(holder.Address()))
+	var key xdr.LedgerKey
+	require.NoError(t, key.SetTrustline(holderID, asset))
+
+	line, err := sim.Get(&key)
+	require.NoError(t, err)
+	require.NotNil(t, line)
+	assert.Equal(t, xdr.Int64(500000000), line.Data.MustTrustLine().Balance)
+
+	// offers count as subentries, and are assigned ids from the pool
+	rate := build.Rate{
+		Selling: build.CreditAsset("USD", issuer.Address()),
+		Buying:  build.NativeAsset(),
+		Price:   build.Price("2"),
+	}
+	result = submit(t, sim, holder, build.CreateOffer(rate, build.Amount("10")))
+	require.True(t, result.Successful())
+	offer := result.Result.Result.MustResults()[0].MustTr().MustManageOfferResult().MustSuccess()
+	assert.Equal(t, xdr.ManageOfferEffectManageOfferCreated, offer.Offer.Effect)
+	assert.Equal(t, xdr.Uint64(1), offer.Offer.MustOffer().OfferId)
+
+	// an account with subentries cannot be merged
+	result = submit(t, sim, holder, build.AccountMerge(
+		build.Destination{AddressOrSeed: root.Address()},
+	))
+	require.Equal(t, xdr.TransactionResultCodeTxFailed, result.Result.Result.Code)
+	assert.Equal(t,
+		xdr.AccountMergeResultCodeAccountMergeHasSubEntries,
+		result.Result.Result.MustResults()[0].MustTr().MustAccountMergeResult().Code,
+	)
+
+	result = submit(t, sim, holder,
+		build.DeleteOffer(rate, build.OfferID(1)),
+		build.ClearData("note"),
+		build.Payment(
+			build.Destination{AddressOrSeed: issuer.Address()},
+			build.CreditAmount{Code: "USD", Issuer: issuer.Address(), Amount: "50"},
+		),
+		build.RemoveTrust("USD", issuer.Address()),
+		build.AccountMerge(build.Destination{AddressOrSeed: root.Address()}),
+	)
+	require.True(t, result.Successful())
+
+	account, err = sim.Account(holder.Address())
+	require.NoError(t, err)
+	assert.Nil(t, account)
+}
+
+func TestSimulator_Signers(t *testing.T) {
+	root, sim := newSimulator(t)
+	signer, err := keypair.Random()
+	require.NoError(t, err)
+
+	result := submit(t, sim, root, build.SetOptions(
+		build.MasterWeight(1),
+		build.AddSigner(signer.Address(), 2),
+		build.SetThresholds(1, 2, 3),
+	))
+	require.True(t, result.Successful())
+
+	pay := build.Payment(
+		build.Destination{AddressOrSeed: root.Address()},
+		build.NativeAmount{Amount: "1"},
+	)
+
+	// the master key alone no longer meets the medium threshold
+	result = submitSigned(t, sim, root.Address(), []string{root.Seed()}, pay)
+	require.Equal(t, xdr.TransactionResultCodeTxFailed, result.Result.Result.Code)
+	assert.Equal(t,
+		xdr.OperationResultCodeOpBadAuth,
+		result.Result.Result.MustResults()[0].Code,
+	)
+	assert.Nil(t, result.Bundle)
+
+	result = submitSigned(t, sim, root.Address(), []string{signer.Seed()}, pay)
+	require.True(t, result.Successful())
+
+	// changing signers requires the high threshold
+	result = submitSigned(t, sim, root.Address(), []string{signer.Seed()},
+		build.SetOptions(build.RemoveSigner(signer.Address())),
+	)
+	require.Equal(t, xdr.TransactionResultCodeTxFailed, result.Result.Result.Code)
+
+	result = submitSigned(t, sim, root.Address(), []string{root.Seed(), signer.Seed()},
+		build.SetOptions(build.RemoveSigner(signer.Address())),
+	)
+	require.True(t, result.Successful())
+
+	account, err := sim.Account(root.Address())
+	require.NoError(t, err)
+	assert.Empty(t, account.Signers)
+	assert.Equal(t, xdr.Uint32(0), account.NumSubEntries)
+}
+
+func newSimulator(t *testing.T) (*keypair.Full, *Simulator) {
+	root, err := keypair.Random()
+	require.NoError(t, err)
+
+	entry, err := AccountEntry(root.Address(), 9900000000)
+	require.NoError(t, err)
+
+	sim, err := New(build.TestNetwork.Passphrase, []xdr.LedgerEntry{entry})
+	require.NoError(t, err)
+
+	return root, sim
+}
+
+func submit(
+	t *testing.T,
+	sim *Simulator,
+	source *keypair.Full,
+	muts ...build.TransactionMutator,
+) *Result {
+	return submitSigned(t, sim, source.Address(), []string{source.Seed()}, muts...)
+}
+
+func submitSigned(
+	t *testing.T,
+	sim *Simulator,
+	source string,
+	signers []string,
+	muts ...build.TransactionMutator,
+) *Result {
+	muts = append([]build.TransactionMutator{
+		build.SourceAccount{AddressOrSeed: source},
+		build.AutoSequence{SequenceProvider: sim},
+		build.Network{Passphrase: sim.NetworkPassphrase},
+	}, muts...)
+
+	tx := build.Transaction(muts...)
+	require.NoError(t, tx.Err)
+
+	txe := tx.Sign(signers...)
+	result, err := sim.Submit(&txe)
+	require.NoError(t, err)
+	return result
+}
@@ -0,0 +1,447 @@
+package simulator
+
+import (
+	"crypto/sha256"
+
+	"github.com/stellar/go/build"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/meta"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// Account returns the account entry for `address`, or nil if the account
+// does not exist.
+func (s *Simulator) Account(address string) (*xdr.AccountEntry, error) {
+	var aid xdr.AccountId
+	err := aid.SetAddress(address)
+	if err != nil {
+		return nil, errors.Wrap(err, "set address failed")
+	}
+
+	entry, err := s.store.Get(&aid)
+	if err != nil {
+		return nil, errors.Wrap(err, "get entry failed")
+	}
+
+	if entry == nil {
+		return nil, nil
+	}
+
+	account := entry.Data.MustAccount()
+	return &account, nil
+}
+
+// Entries returns every entry in the simulated ledger.
+func (s *Simulator) Entries() ([]xdr.LedgerEntry, error) {
+	return s.store.Entries()
+}
+
+// Get returns the entry identified by `key`, or nil if it does not exist.
+func (s *Simulator) Get(key xdr.Keyer) (*xdr.LedgerEntry, error) {
+	return s.store.Get(key)
+}
+
+// SequenceForAccount implements build.SequenceProvider
+func (s *Simulator) SequenceForAccount(aid string) (xdr.SequenceNumber, error) {
+	account, err := s.Account(aid)
+	if err != nil {
+		return 0, err
+	}
+
+	if account == nil {
+		return 0, errors.Errorf("account not found: %s", aid)
+	}
+
+	return account.SeqNum, nil
+}
+
+// Submit applies the transaction envelope built by `b`.
+func (s *Simulator) Submit(b *build.TransactionEnvelopeBuilder) (*Result, error) {
+	if b.Err != nil {
+		return nil, errors.Wrap(b.Err, "builder failed")
+	}
+
+	if b.E == nil {
+		return nil, errors.New("builder has no envelope")
+	}
+
+	return s.SubmitEnvelope(*b.E)
+}
+
+// SubmitEnvelope applies `txe` to the simulated ledger.  An error is returned
+// only when the transaction could not be processed at all, in which case the
+// simulated ledger is left unchanged; a transaction rejected by the network is
+// reported through the returned Result.
+func (s *Simulator) SubmitEnvelope(txe xdr.TransactionEnvelope) (*Result, error) {
+	tx := &txe.Tx
+
+	hash, err := network.HashTransaction(tx, s.NetworkPassphrase)
+	if err != nil {
+		return nil, errors.Wrap(err, "hash transaction failed")
+	}
+
+	result := &Result{
+		Hash:   hash,
+		Ledger: s.Sequence,
+	}
+
+	code, opResults, err := s.validate(&txe, hash)
+	if err != nil {
+		return nil, errors.Wrap(err, "validate failed")
+	}
+
+	if code != xdr.TransactionResultCodeTxSuccess {
+		result.Result.Result, err = txResult(code, opResults)
+		return result, err
+	}
+
+	// any error from here on reverts the transaction entirely, fee included
+	before, err := s.store.Snapshot(s.store.Ledger())
+	if err != nil {
+		return nil, errors.Wrap(err, "snapshot failed")
+	}
+	idPool := s.idPool
+
+	result, err = s.apply(tx, result)
+	if err != nil {
+		s.idPool = idPool
+		rerr := s.store.Restore(before)
+		if rerr != nil {
+			return nil, errors.Wrap(rerr, "restore failed")
+		}
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// apply charges the fee of the validated transaction `tx`, consumes its
+// sequence number and applies its operations, completing `result`.
+func (s *Simulator) apply(tx *xdr.Transaction, result *Result) (*Result, error) {
+	// charge the fee and consume the sequence number
+	w := s.working()
+	source, err := w.account(tx.SourceAccount)
+	if err != nil {
+		return nil, err
+	}
+	account := source.Data.Account
+	account.Balance -= xdr.Int64(tx.Fee)
+	account.SeqNum = tx.SeqNum
+
+	feeMeta, err := w.commit()
+	if err != nil {
+		return nil, errors.Wrap(err, "charge fee failed")
+	}
+	result.Result.FeeCharged = xdr.Int64(tx.Fee)
+
+	// apply the operations, reverting all of them if any fails
+	snap, err := s.store.Snapshot(s.store.Ledger())
+	if err != nil {
+		return nil, errors.Wrap(err, "snapshot failed")
+	}
+	idPool := s.idPool
+
+	opResults := make([]xdr.OperationResult, len(tx.Operations))
+	opMetas := []xdr.OperationMeta{}
+	failed := false
+
+	for i, op := range tx.Operations {
+		w := s.working()
+
+		opResults[i], err = s.applyOperation(w, tx, op)
+		if err != nil {
+			return nil, errors.Wrapf(err, "apply operation %d failed", i)
+		}
+
+		if !opSucceeded(opResults[i]) {
+			failed = true
+			continue
+		}
+
+		changes, err := w.commit()
+		if err != nil {
+			return nil, errors.Wrapf(err, "commit operation %d failed", i)
+		}
+		opMetas = append(opMetas, xdr.OperationMeta{Changes: changes})
+	}
+
+	code := xdr.TransactionResultCodeTxSuccess
+	if failed {
+		code = xdr.TransactionResultCodeTxFailed
+		opMetas = []xdr.OperationMeta{}
+		s.idPool = idPool
+
+		err = s.store.Restore(snap)
+		if err != nil {
+			return nil, errors.Wrap(err, "restore failed")
+		}
+	}
+
+	result.Result.Result, err = txResult(code, opResults)
+	if err != nil {
+		return nil, err
+	}
+
+	txMeta, err := xdr.NewTransactionMeta(0, opMetas)
+	if err != nil {
+		return nil, errors.Wrap(err, "make meta failed")
+	}
+
+	result.Bundle = &meta.Bundle{
+		FeeMeta:         feeMeta,
+		TransactionMeta: txMeta,
+	}
+
+	return result, nil
+}
+
+// validate performs the checks stellar-core makes before accepting a
+// transaction.  Transactions that fail validation are not applied and are not
+// charged a fee.
+func (s *Simulator) validate(
+	txe *xdr.TransactionEnvelope,
+	hash [32]byte,
+) (xdr.TransactionResultCode, []xdr.OperationResult, error) {
+	tx := &txe.Tx
+
+	if len(tx.Operations) == 0 {
+		return xdr.TransactionResultCodeTxMissingOperation, nil, nil
+	}
+
+	for _, op := range tx.Operations {
+		if !supportedOperation(op.Body.Type) {
+			return 0, nil, errors.Wrap(ErrUnsupportedOperation, op.Body.Type.String())
+		}
+	}
+
+	if tx.TimeBounds != nil {
+		now := xdr.Uint64(s.CloseTime.Unix())
+		if tx.TimeBounds.MinTime > now {
+			return xdr.TransactionResultCodeTxTooEarly, nil, nil
+		}
+		if tx.TimeBounds.MaxTime != 0 && now > tx.TimeBounds.MaxTime {
+			return xdr.TransactionResultCodeTxTooLate, nil, nil
+		}
+	}
+
+	if int64(tx.Fee) < int64(s.BaseFee)*int64(len(tx.Operations)) {
+		return xdr.TransactionResultCodeTxInsufficientFee, nil, nil
+	}
+
+	w := s.working()
+	source, err := w.account(tx.SourceAccount)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if source == nil {
+		return xdr.TransactionResultCodeTxNoAccount, nil, nil
+	}
+	account := source.Data.MustAccount()
+
+	if tx.SeqNum != account.SeqNum+1 {
+		return xdr.TransactionResultCodeTxBadSeq, nil, nil
+	}
+
+	checker := &signatureChecker{
+		hash:       hash,
+		signatures: txe.Signatures,
+		used:       make([]bool, len(txe.Signatures)),
+	}
+
+	if !checker.check(account, account.Thresholds[xdr.ThresholdIndexesThresholdLow]) {
+		return xdr.TransactionResultCodeTxBadAuth, nil, nil
+	}
+
+	opResults := make([]xdr.OperationResult, len(tx.Operations))
+	failed := false
+
+	for i, op := range tx.Operations {
+		opResults[i], err = successResult(op.Body.Type)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		entry, err := w.account(opSource(tx, op))
+		if err != nil {
+			return 0, nil, err
+		}
+
+		if entry == nil {
+			opResults[i] = xdr.OperationResult{Code: xdr.OperationResultCodeOpNoAccount}
+			failed = true
+			continue
+		}
+
+		opAccount := entry.Data.MustAccount()
+		if !checker.check(opAccount, neededThreshold(opAccount, op)) {
+			opResults[i] = xdr.OperationResult{Code: xdr.OperationResultCodeOpBadAuth}
+			failed = true
+		}
+	}
+
+	if failed {
+		return xdr.TransactionResultCodeTxFailed, opResults, nil
+	}
+
+	if !checker.allUsed() {
+		return xdr.TransactionResultCodeTxBadAuthExtra, nil, nil
+	}
+
+	if account.Balance-xdr.Int64(tx.Fee) < s.minBalance(account, 0) {
+		return xdr.TransactionResultCodeTxInsufficientBalance, nil, nil
+	}
+
+	return xdr.TransactionResultCodeTxSuccess, nil, nil
+}
+
+// minBalance returns the balance `account` must maintain were it to have
+// `extra` additional subentries.
+func (s *Simulator) minBalance(account xdr.AccountEntry, extra int) xdr.Int64 {
+	return xdr.Int64(2+int64(account.NumSubEntries)+int64(extra)) * s.BaseReserve
+}
+
+// neededThreshold returns the signature weight required to authorize `op` on
+// behalf of `account`.
+func neededThreshold(account xdr.AccountEntry, op xdr.Operation) byte {
+	level := xdr.ThresholdIndexesThresholdMed
+
+	switch op.Body.Type {
+	case xdr.OperationTypeAllowTrust, xdr.OperationTypeInflation:
+		level = xdr.ThresholdIndexesThresholdLow
+	case xdr.OperationTypeAccountMerge:
+		level = xdr.ThresholdIndexesThresholdHigh
+	case xdr.OperationTypeSetOptions:
+		so := op.Body.MustSetOptionsOp()
+		if so.MasterWeight != nil ||
+			so.LowThreshold != nil ||
+			so.MedThreshold != nil ||
+			so.HighThreshold != nil ||
+			so.Signer != nil {
+			level = xdr.ThresholdIndexesThresholdHigh
+		}
+	}
+
+	return account.Thresholds[level]
+}
+
+// opSource returns the account on whose behalf `op` is performed.
+func opSource(tx *xdr.Transaction, op xdr.Operation) xdr.AccountId {
+	if op.SourceAccount != nil {
+		return *op.SourceAccount
+	}
+	return tx.SourceAccount
+}
+
+// signatureChecker tracks which of a transaction's signatures have been used
+// to authorize it.
+type signatureChecker struct {
+	hash       [32]byte
+	signatures []xdr.DecoratedSignature
+	used       []bool
+}
+
+// check returns true if the signatures provide at least `needed` weight (and
+// never less than 1) on behalf of `account`.
+func (c *signatureChecker) check(account xdr.AccountEntry, needed byte) bool {
+	if needed == 0 {
+		needed = 1
+	}
+
+	total := 0
+	add := func(weight int) bool {
+		total += weight
+		return total >= int(needed)
+	}
+
+	master := account.Thresholds[xdr.ThresholdIndexesThresholdMasterWeight]
+	if master > 0 && c.ed25519(account.AccountId.MustEd25519()) {
+		if add(int(master)) {
+			return true
+		}
+	}
+
+	for _, signer := range account.Signers {
+		var ok bool
+
+		switch signer.Key.Type {
+		case xdr.SignerKeyTypeSignerKeyTypeEd25519:
+			ok = c.ed25519(signer.Key.MustEd25519())
+		case xdr.SignerKeyTypeSignerKeyTypeHashTx:
+			ok = xdr.Uint256(c.hash) == signer.Key.MustHashTx()
+		case xdr.SignerKeyTypeSignerKeyTypeHashX:
+			ok = c.hashX(signer.Key.MustHashX())
+		}
+
+		if ok && add(int(signer.Weight)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *signatureChecker) ed25519(key xdr.Uint256) bool {
+	kp, err := keypair.Parse(strkey.MustEncode(strkey.VersionByteAccountID, key[:]))
+	if err != nil {
+		return false
+	}
+
+	hint := kp.Hint()
+	for i, sig := range c.signatures {
+		if sig.Hint != xdr.SignatureHint(hint) {
+			continue
+		}
+
+		if kp.Verify(c.hash[:], sig.Signature) == nil {
+			c.used[i] = true
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *signatureChecker) hashX(key xdr.Uint256) bool {
+	for i, sig := range c.signatures {
+		if sig.Hint != xdr.SignatureHint([4]byte{key[28], key[29], key[30], key[31]}) {
+			continue
+		}
+
+		if xdr.Uint256(sha256.Sum256(sig.Signature)) == key {
+			c.used[i] = true
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *signatureChecker) allUsed() bool {
+	for _, used := range c.used {
+		if !used {
+			return false
+		}
+	}
+	return true
+}
+
+func txResult(
+	code xdr.TransactionResultCode,
+	opResults []xdr.OperationResult,
+) (xdr.TransactionResultResult, error) {
+	var value interface{}
+	switch code {
+	case xdr.TransactionResultCodeTxSuccess, xdr.TransactionResultCodeTxFailed:
+		value = opResults
+	}
+
+	result, err := xdr.NewTransactionResultResult(code, value)
+	if err != nil {
+		return result, errors.Wrap(err, "make result failed")
+	}
+	return result, nil
+}
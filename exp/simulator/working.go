@@ -0,0 +1,204 @@
+package simulator
+
+import (
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// working tracks the entries loaded and modified while applying a single
+// operation (or while charging a transaction's fee) so that the resulting
+// changes can be expressed in the form emitted by stellar-core.
+type working struct {
+	sim   *Simulator
+	order []string
+	keys  map[string]xdr.LedgerKey
+
+	// before holds the value of each loaded entry prior to modification and
+	// after holds its current value.  A nil value means the entry does not
+	// exist.
+	before map[string]*xdr.LedgerEntry
+	after  map[string]*xdr.LedgerEntry
+}
+
+func (s *Simulator) working() *working {
+	return &working{
+		sim:    s,
+		keys:   map[string]xdr.LedgerKey{},
+		before: map[string]*xdr.LedgerEntry{},
+		after:  map[string]*xdr.LedgerEntry{},
+	}
+}
+
+// load returns the current value of the entry identified by `key`, or nil if
+// it does not exist.  Modifications made to the returned entry are recorded.
+func (w *working) load(key xdr.LedgerKey) (*xdr.LedgerEntry, error) {
+	k, err := xdr.MarshalBase64(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal key failed")
+	}
+
+	if _, ok := w.keys[k]; ok {
+		return w.after[k], nil
+	}
+
+	before, err := w.sim.store.Get(&key)
+	if err != nil {
+		return nil, errors.Wrap(err, "get entry failed")
+	}
+
+	after, err := w.sim.store.Get(&key)
+	if err != nil {
+		return nil, errors.Wrap(err, "get entry failed")
+	}
+
+	w.order = append(w.order, k)
+	w.keys[k] = key
+	w.before[k] = before
+	w.after[k] = after
+	return after, nil
+}
+
+func (w *working) account(aid xdr.AccountId) (*xdr.LedgerEntry, error) {
+	return w.load(aid.LedgerKey())
+}
+
+func (w *working) trustline(aid xdr.AccountId, asset xdr.Asset) (*xdr.LedgerEntry, error) {
+	var key xdr.LedgerKey
+	err := key.SetTrustline(aid, asset)
+	if err != nil {
+		return nil, errors.Wrap(err, "make key failed")
+	}
+	return w.load(key)
+}
+
+func (w *working) offer(aid xdr.AccountId, id xdr.Uint64) (*xdr.LedgerEntry, error) {
+	var key xdr.LedgerKey
+	err := key.SetOffer(aid, uint64(id))
+	if err != nil {
+		return nil, errors.Wrap(err, "make key failed")
+	}
+	return w.load(key)
+}
+
+func (w *working) data(aid xdr.AccountId, name xdr.String64) (*xdr.LedgerEntry, error) {
+	var key xdr.LedgerKey
+	err := key.SetData(aid, string(name))
+	if err != nil {
+		return nil, errors.Wrap(err, "make key failed")
+	}
+	return w.load(key)
+}
+
+// create adds a new entry.  The caller must have checked that no entry with
+// the same key exists.
+func (w *working) create(typ xdr.LedgerEntryType, value interface{}) error {
+	data, err := xdr.NewLedgerEntryData(typ, value)
+	if err != nil {
+		return errors.Wrap(err, "make entry data failed")
+	}
+
+	entry := xdr.LedgerEntry{Data: data}
+	_, err = w.load(entry.LedgerKey())
+	if err != nil {
+		return err
+	}
+
+	k, err := xdr.MarshalBase64(entry.LedgerKey())
+	if err != nil {
+		return errors.Wrap(err, "marshal key failed")
+	}
+
+	w.after[k] = &entry
+	return nil
+}
+
+// remove deletes the entry identified by `key`.
+func (w *working) remove(key xdr.LedgerKey) error {
+	_, err := w.load(key)
+	if err != nil {
+		return err
+	}
+
+	k, err := xdr.MarshalBase64(key)
+	if err != nil {
+		return errors.Wrap(err, "marshal key failed")
+	}
+
+	w.after[k] = nil
+	return nil
+}
+
+// changes returns the changes made to the loaded entries, in the order they
+// were first loaded.  Entries that are left unmodified are omitted.
+func (w *working) changes() (xdr.LedgerEntryChanges, error) {
+	var result xdr.LedgerEntryChanges
+
+	for _, k := range w.order {
+		before, after := w.before[k], w.after[k]
+
+		var (
+			typs   []xdr.LedgerEntryChangeType
+			values []interface{}
+		)
+
+		switch {
+		case before == nil && after == nil:
+			continue
+		case before == nil:
+			after.LastModifiedLedgerSeq = xdr.Uint32(w.sim.Sequence)
+			typs = append(typs, xdr.LedgerEntryChangeTypeLedgerEntryCreated)
+			values = append(values, *after)
+		case after == nil:
+			typs = append(typs,
+				xdr.LedgerEntryChangeTypeLedgerEntryState,
+				xdr.LedgerEntryChangeTypeLedgerEntryRemoved,
+			)
+			values = append(values, *before, w.keys[k])
+		default:
+			b, err := xdr.MarshalBase64(before)
+			if err != nil {
+				return nil, errors.Wrap(err, "marshal entry failed")
+			}
+			a, err := xdr.MarshalBase64(after)
+			if err != nil {
+				return nil, errors.Wrap(err, "marshal entry failed")
+			}
+			if a == b {
+				continue
+			}
+
+			after.LastModifiedLedgerSeq = xdr.Uint32(w.sim.Sequence)
+			typs = append(typs,
+				xdr.LedgerEntryChangeTypeLedgerEntryState,
+				xdr.LedgerEntryChangeTypeLedgerEntryUpdated,
+			)
+			values = append(values, *before, *after)
+		}
+
+		for i, typ := range typs {
+			change, err := xdr.NewLedgerEntryChange(typ, values[i])
+			if err != nil {
+				return nil, errors.Wrap(err, "make change failed")
+			}
+			result = append(result, change)
+		}
+	}
+
+	return result, nil
+}
+
+// commit applies the changes made to the loaded entries to the simulator's
+// state and returns them.
+func (w *working) commit() (xdr.LedgerEntryChanges, error) {
+	changes, err := w.changes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.sim.store.Apply(changes)
+	if err != nil {
+		return nil, errors.Wrap(err, "apply changes failed")
+	}
+
+	return changes, nil
+}
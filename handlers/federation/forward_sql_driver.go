@@ -0,0 +1,66 @@
+package federation
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stellar/go/support/errors"
+)
+
+// LookupForwardingRecord implements `ForwardDriver` by performing the query of
+// `drv.LookupForwardingRecordQueries` for the request's forward_type against
+// `drv.DB`, providing it the request's parameters as named parameters
+func (drv *ForwardSQLDriver) LookupForwardingRecord(
+	query url.Values,
+) (*Record, error) {
+	if len(drv.LookupForwardingRecordQueries) == 0 {
+		return nil, notImplemented("forward")
+	}
+
+	typ := query.Get("forward_type")
+	fq, ok := drv.LookupForwardingRecordQueries[typ]
+	if !ok {
+		return nil, invalidQuery(fmt.Sprintf("invalid forward_type: '%s'", typ))
+	}
+
+	params := map[string]interface{}{}
+	for _, name := range fq.Params {
+		value := query.Get(name)
+		if value == "" {
+			return nil, invalidQuery(fmt.Sprintf("%s parameter is blank", name))
+		}
+		params[name] = value
+	}
+
+	sql, args, err := sqlx.Named(fq.Query, params)
+	if err != nil {
+		return nil, errors.Wrap(err, "bind named parameters")
+	}
+
+	drv.initDB()
+	var result Record
+
+	err = drv.db.GetRaw(&result, sql, args...)
+
+	if drv.db.NoRows(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "db get")
+	}
+
+	return &result, nil
+}
+
+// invalidQuery returns the error a driver returns for a request whose
+// parameters it cannot use, described by `msg`.
+func invalidQuery(msg string) error {
+	return ErrorResponse{
+		StatusCode: http.StatusBadRequest,
+		Code:       "invalid_query",
+		Message:    msg,
+	}
+}
+
+var _ ForwardDriver = &ForwardSQLDriver{}
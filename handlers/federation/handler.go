@@ -1,6 +1,7 @@
 package federation
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -32,7 +33,7 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	case "forward":
 		h.lookupByForward(w, r.URL.Query())
 	case "txid":
-		h.failNotImplemented(w, "txid type queries are not supported")
+		h.lookupByTxID(w, q)
 	default:
 		h.writeJSON(w, ErrorResponse{
 			Code:    "invalid_request",
@@ -81,6 +82,40 @@ func (h *Handler) lookupByID(w http.ResponseWriter, q string) {
 	}, http.StatusOK)
 }
 
+func (h *Handler) lookupByTxID(w http.ResponseWriter, q string) {
+	td, ok := h.Driver.(TxIDDriver)
+
+	if !ok {
+		h.failNotImplemented(w, "txid type queries are not supported")
+		return
+	}
+
+	hash, err := hex.DecodeString(q)
+	if err != nil || len(hash) != 32 {
+		h.writeJSON(w, ErrorResponse{
+			Code:    "invalid_query",
+			Message: "Please use a hex-encoded transaction hash",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	// drivers are given the hash in lowercase, whatever case it was sent in
+	rec, err := td.LookupTxIDRecord(hex.EncodeToString(hash))
+	if err != nil {
+		h.writeError(w, errors.Wrap(err, "lookupByTxID"))
+		return
+	}
+
+	if rec == nil {
+		h.failNotFound(w)
+		return
+	}
+
+	h.writeJSON(w, proto.TxIDResponse{
+		Address: address.New(rec.Name, rec.Domain),
+	}, http.StatusOK)
+}
+
 func (h *Handler) lookupByName(w http.ResponseWriter, q string) {
 	name, domain, err := address.Split(q)
 	if err != nil {
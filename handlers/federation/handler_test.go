@@ -7,6 +7,7 @@ import (
 
 	"github.com/stellar/go/support/db/dbtest"
 	"github.com/stellar/go/support/http/httptest"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestHandler(t *testing.T) {
@@ -15,6 +16,9 @@ func TestHandler(t *testing.T) {
     INSERT INTO people (id, name, domain) VALUES 
       ('GD2GJPL3UOK5LX7TWXOACK2ZPWPFSLBNKL3GTGH6BLBNISK4BGWMFBBG', 'scott', 'stellar.org'),
       ('GCYMGWPZ6NC2U7SO6SMXOP5ZLXOEC5SYPKITDMVEONLCHFSCCQR2J4S3', 'bartek', 'stellar.org');
+    CREATE TABLE transactions (hash character varying, sender character varying);
+    INSERT INTO transactions (hash, sender) VALUES 
+      ('5f3f6b9b5b1ce3d3b8c0bcb1d7a0b69e0ce0a5ad12ab4ae4a1bf0c58fb8c0e0e', 'GCYMGWPZ6NC2U7SO6SMXOP5ZLXOEC5SYPKITDMVEONLCHFSCCQR2J4S3');
   `)
 	defer db.Close()
 
@@ -25,6 +29,7 @@ func TestHandler(t *testing.T) {
 			LookupRecordQuery: "SELECT id FROM people WHERE name = ? AND domain = ?",
 		},
 		LookupReverseRecordQuery: "SELECT name, domain FROM people WHERE id = ?",
+		LookupTxIDRecordQuery:    "SELECT name, domain FROM people JOIN transactions ON sender = id WHERE hash = ?",
 	}
 
 	defer driver.DB.Close()
@@ -116,15 +121,46 @@ func TestHandler(t *testing.T) {
 		ContainsKey("code").
 		ValueEqual("code", "not_found")
 
-	// TXID request
+	// TXID requests
+
+	// Good request
+	server.GET("/federation").
+		WithQuery("type", "txid").
+		WithQuery("q", "5f3f6b9b5b1ce3d3b8c0bcb1d7a0b69e0ce0a5ad12ab4ae4a1bf0c58fb8c0e0e").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().
+		ContainsKey("stellar_address").
+		ValueEqual("stellar_address", "bartek*stellar.org")
+
+	// Uppercase hash
+	server.GET("/federation").
+		WithQuery("type", "txid").
+		WithQuery("q", "5F3F6B9B5B1CE3D3B8C0BCB1D7A0B69E0CE0A5AD12AB4AE4A1BF0C58FB8C0E0E").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().
+		ValueEqual("stellar_address", "bartek*stellar.org")
+
+	// No record in DB
+	server.GET("/federation").
+		WithQuery("type", "txid").
+		WithQuery("q", "0000000000000000000000000000000000000000000000000000000000000000").
+		Expect().
+		Status(http.StatusNotFound).
+		JSON().Object().
+		ContainsKey("code").
+		ValueEqual("code", "not_found")
+
+	// Invalid hash
 	server.GET("/federation").
 		WithQuery("type", "txid").
 		WithQuery("q", "hello").
 		Expect().
-		Status(http.StatusNotImplemented).
+		Status(http.StatusBadRequest).
 		JSON().Object().
 		ContainsKey("code").
-		ValueEqual("code", "not_implemented")
+		ValueEqual("code", "invalid_query")
 
 	// Invalid type
 	server.GET("/federation").
@@ -178,6 +214,28 @@ func TestNameHandler(t *testing.T) {
 		JSON().Object().
 		ContainsKey("code").
 		ValueEqual("code", "not_implemented")
+
+	// TXID request
+	server.GET("/federation").
+		WithQuery("type", "txid").
+		WithQuery("q", "5f3f6b9b5b1ce3d3b8c0bcb1d7a0b69e0ce0a5ad12ab4ae4a1bf0c58fb8c0e0e").
+		Expect().
+		Status(http.StatusNotImplemented).
+		JSON().Object().
+		ContainsKey("code").
+		ValueEqual("code", "not_implemented")
+}
+
+func TestReverseSQLDriver_UnsetQueries(t *testing.T) {
+	driver := &ReverseSQLDriver{}
+
+	_, err := driver.LookupReverseRecord("GA3R753JKGXU6ETHNY3U6PYIY7D6UUCXXDYBRF4XURNAGXW3CVGQH2ZA")
+	assert.Equal(t, "not_implemented", err.(ErrorResponse).Code)
+	assert.Equal(t, "id type queries are not supported", err.Error())
+
+	_, err = driver.LookupTxIDRecord("5f3f6b9b5b1ce3d3b8c0bcb1d7a0b69e0ce0a5ad12ab4ae4a1bf0c58fb8c0e0e")
+	assert.Equal(t, http.StatusNotImplemented, err.(ErrorResponse).StatusCode)
+	assert.Equal(t, "txid type queries are not supported", err.Error())
 }
 
 type ForwardTestDriver struct{}
@@ -250,3 +308,103 @@ func TestForwardHandler(t *testing.T) {
 		ContainsKey("code").
 		ValueEqual("code", "not_found")
 }
+
+func TestForwardSQLDriver(t *testing.T) {
+	db := dbtest.Postgres(t).Load(`
+    CREATE TABLE bank_accounts (account_id character varying, swift character varying, acct character varying, memo character varying);
+    INSERT INTO bank_accounts (account_id, swift, acct, memo) VALUES 
+      ('GD2GJPL3UOK5LX7TWXOACK2ZPWPFSLBNKL3GTGH6BLBNISK4BGWMFBBG', 'BOPBPHMM', '1234', '1');
+  `)
+	defer db.Close()
+
+	driver := &ForwardSQLDriver{
+		ReverseSQLDriver: ReverseSQLDriver{
+			SQLDriver: SQLDriver{
+				DB:                db.Open().DB,
+				Dialect:           db.Dialect,
+				LookupRecordQuery: "SELECT account_id AS id FROM bank_accounts WHERE acct = ? AND swift = ?",
+			},
+		},
+		LookupForwardingRecordQueries: map[string]ForwardQuery{
+			"bank_account": {
+				Query:  "SELECT account_id AS id, memo, 'id' AS memo_type FROM bank_accounts WHERE swift = :swift AND acct = :acct",
+				Params: []string{"acct", "swift"},
+			},
+		},
+	}
+
+	defer driver.DB.Close()
+
+	handler := &Handler{driver}
+	server := httptest.NewServer(t, handler)
+	defer server.Close()
+
+	// Good forward request
+	server.GET("/federation").
+		WithQuery("type", "forward").
+		WithQuery("forward_type", "bank_account").
+		WithQuery("swift", "BOPBPHMM").
+		WithQuery("acct", "1234").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().
+		ValueEqual("account_id", "GD2GJPL3UOK5LX7TWXOACK2ZPWPFSLBNKL3GTGH6BLBNISK4BGWMFBBG").
+		ValueEqual("memo_type", "id").
+		ValueEqual("memo", 1)
+
+	// No record in DB
+	server.GET("/federation").
+		WithQuery("type", "forward").
+		WithQuery("forward_type", "bank_account").
+		WithQuery("swift", "BOPBPHMM").
+		WithQuery("acct", "4321").
+		Expect().
+		Status(http.StatusNotFound).
+		JSON().Object().
+		ValueEqual("code", "not_found")
+
+	// Missing parameter
+	server.GET("/federation").
+		WithQuery("type", "forward").
+		WithQuery("forward_type", "bank_account").
+		WithQuery("acct", "1234").
+		Expect().
+		Status(http.StatusBadRequest).
+		JSON().Object().
+		ValueEqual("code", "invalid_query").
+		ValueEqual("message", "swift parameter is blank")
+
+	// Unknown forward type
+	server.GET("/federation").
+		WithQuery("type", "forward").
+		WithQuery("forward_type", "crypto").
+		WithQuery("acct", "1234").
+		Expect().
+		Status(http.StatusBadRequest).
+		JSON().Object().
+		ValueEqual("code", "invalid_query").
+		ValueEqual("message", "invalid forward_type: 'crypto'")
+
+	// Other queries are still answered
+	server.GET("/federation").
+		WithQuery("type", "name").
+		WithQuery("q", "1234*BOPBPHMM").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().
+		ValueEqual("account_id", "GD2GJPL3UOK5LX7TWXOACK2ZPWPFSLBNKL3GTGH6BLBNISK4BGWMFBBG")
+
+	server.GET("/federation").
+		WithQuery("type", "id").
+		WithQuery("q", "GD2GJPL3UOK5LX7TWXOACK2ZPWPFSLBNKL3GTGH6BLBNISK4BGWMFBBG").
+		Expect().
+		Status(http.StatusNotImplemented)
+}
+
+func TestForwardSQLDriver_UnsetQueries(t *testing.T) {
+	driver := &ForwardSQLDriver{}
+
+	_, err := driver.LookupForwardingRecord(url.Values{"forward_type": {"bank_account"}})
+	assert.Equal(t, "not_implemented", err.(ErrorResponse).Code)
+	assert.Equal(t, "forward type queries are not supported", err.Error())
+}
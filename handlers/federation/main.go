@@ -6,8 +6,9 @@
 // these interfaces allows a developer to plug in their own back end, whether it
 // be a RDBMS, a KV store, or even just an in memory data structure.
 //
-// A pre-baked implementation of `Driver` and `ReverseDriver` that provides
-// simple access to SQL systems is included. See `SQLDriver` for more details.
+// Pre-baked implementations of `Driver`, `ReverseDriver`, `TxIDDriver` and
+// `ForwardDriver` that provide simple access to SQL systems are included. See
+// `SQLDriver`, `ReverseSQLDriver` and `ForwardSQLDriver` for more details.
 package federation
 
 import (
@@ -79,15 +80,30 @@ type ForwardDriver interface {
 	LookupForwardingRecord(query url.Values) (*Record, error)
 }
 
+// TxIDDriver represents a data source against which txid queries can be
+// executed.
+type TxIDDriver interface {
+	// LookupTxIDRecord is called when a handler receives a "txid" federation
+	// request to lookup the `ReverseRecord` of the sender of the transaction
+	// with the provided hash, hex-encoded in lowercase. An implementation
+	// should return a nil `*ReverseRecord` value if the lookup successfully
+	// executed but no result was found.
+	LookupTxIDRecord(txid string) (*ReverseRecord, error)
+}
+
 // ReverseRecord represents the result from performing a "Reverse federation"
-// lookup, in which an Account ID is used to lookup an associated address.
+// lookup, in which an Account ID is used to lookup an associated address, or a
+// "txid" lookup, in which a transaction hash is used to lookup the address of
+// the transaction's sender.
 type ReverseRecord struct {
 	Name   string `db:"name"`
 	Domain string `db:"domain"`
 }
 
-// ReverseSQLDriver provides a `ReverseDriver` implementation based upon a SQL
-// Server.  See `SQLDriver`, the forward only version, for more details.
+// ReverseSQLDriver provides `ReverseDriver` and `TxIDDriver` implementations
+// based upon a SQL Server.  See `SQLDriver`, the forward only version, for more
+// details.  Queries of a type whose SQL query is left blank are answered with
+// a "not_implemented" error.
 type ReverseSQLDriver struct {
 	SQLDriver
 
@@ -97,6 +113,41 @@ type ReverseSQLDriver struct {
 	// stellar account id to lookup, such as
 	// "GDOP3VI4UA5LS7AMLJI66RJUXEQ4HX46WUXTRTJGI5IKDLNWUBOW3FUK".
 	LookupReverseRecordQuery string
+
+	// LookupTxIDRecordQuery is a SQL query used for performing "txid"
+	// federation queries.  Like LookupReverseRecordQuery, it should return the
+	// name and domain of an address, and accomodate a single parameter, using
+	// "?" as the placeholder.  This provided parameter will be the hash of the
+	// transaction whose sender to lookup, hex-encoded in lowercase.
+	LookupTxIDRecordQuery string
+}
+
+// ForwardSQLDriver provides a `ForwardDriver` implementation based upon a SQL
+// Server, in addition to the queries of `ReverseSQLDriver`.  Forward queries
+// are answered with a "not_implemented" error if no SQL query is configured
+// for them.
+type ForwardSQLDriver struct {
+	ReverseSQLDriver
+
+	// LookupForwardingRecordQueries are the SQL queries used for performing
+	// "forward" federation queries, keyed by the "forward_type" parameter of
+	// the requests they answer, such as "bank_account".  Requests with a
+	// forward_type that has no query are rejected as invalid.
+	LookupForwardingRecordQueries map[string]ForwardQuery
+}
+
+// ForwardQuery is a SQL query used by `ForwardSQLDriver` to answer "forward"
+// federation queries of one forward_type.
+type ForwardQuery struct {
+	// Query should return the same columns as `SQLDriver.LookupRecordQuery`,
+	// and use named parameters, such as ":swift", for the request's
+	// parameters.
+	Query string
+
+	// Params are the names of the request parameters provided to Query,
+	// each as the named parameter of the same name.  A request missing any of
+	// them is rejected as invalid.
+	Params []string
 }
 
 // SQLDriver represents an implementation of `Driver` that
@@ -1,16 +1,43 @@
 package federation
 
-import "github.com/stellar/go/support/errors"
+import (
+	"net/http"
+
+	"github.com/stellar/go/support/errors"
+)
 
 // LookupReverseRecord implements `ReverseDriver` by performing
 // `drv.LookupReverseRecordQuery` against `drv.DB` using the provided parameter
 func (drv *ReverseSQLDriver) LookupReverseRecord(
 	accountid string,
+) (*ReverseRecord, error) {
+	if drv.LookupReverseRecordQuery == "" {
+		return nil, notImplemented("id")
+	}
+
+	return drv.lookupReverse(drv.LookupReverseRecordQuery, accountid)
+}
+
+// LookupTxIDRecord implements `TxIDDriver` by performing
+// `drv.LookupTxIDRecordQuery` against `drv.DB` using the provided parameter
+func (drv *ReverseSQLDriver) LookupTxIDRecord(
+	txid string,
+) (*ReverseRecord, error) {
+	if drv.LookupTxIDRecordQuery == "" {
+		return nil, notImplemented("txid")
+	}
+
+	return drv.lookupReverse(drv.LookupTxIDRecordQuery, txid)
+}
+
+func (drv *ReverseSQLDriver) lookupReverse(
+	query string,
+	param string,
 ) (*ReverseRecord, error) {
 	drv.initDB()
 	var result ReverseRecord
 
-	err := drv.db.GetRaw(&result, drv.LookupReverseRecordQuery, accountid)
+	err := drv.db.GetRaw(&result, query, param)
 
 	if drv.db.NoRows(err) {
 		return nil, nil
@@ -21,4 +48,15 @@ func (drv *ReverseSQLDriver) LookupReverseRecord(
 	return &result, nil
 }
 
+// notImplemented returns the error a driver returns for queries of type `typ`
+// that it has not been configured to answer.
+func notImplemented(typ string) error {
+	return ErrorResponse{
+		StatusCode: http.StatusNotImplemented,
+		Code:       "not_implemented",
+		Message:    typ + " type queries are not supported",
+	}
+}
+
 var _ ReverseDriver = &ReverseSQLDriver{}
+var _ TxIDDriver = &ReverseSQLDriver{}
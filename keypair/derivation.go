@@ -0,0 +1,103 @@
+package keypair
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidPath is returned when a derivation path is malformed or includes a
+// non-hardened index, which ed25519 derivation does not support.
+var ErrInvalidPath = errors.New("invalid derivation path")
+
+const (
+	// StellarPrimaryAccountPath is the SEP-5 derivation path of the primary
+	// account of a wallet.
+	StellarPrimaryAccountPath = "m/44'/148'/0'"
+
+	// StellarAccountPathFormat is the SEP-5 derivation path of the n-th
+	// account of a wallet, for use with fmt.Sprintf.
+	StellarAccountPathFormat = "m/44'/148'/%d'"
+
+	// FirstHardenedIndex is the index of the first hardened child key.
+	FirstHardenedIndex = uint32(0x80000000)
+
+	// seedModifier is the HMAC key used by SLIP-0010 when deriving the master
+	// key for the ed25519 curve.
+	seedModifier = "ed25519 seed"
+)
+
+// FromMnemonic returns the keypair of the account at `index` of the wallet
+// described by the BIP-39 `mnemonic` and optional `passphrase`, derived along
+// the SEP-5 path m/44'/148'/index'.
+func FromMnemonic(mnemonic, passphrase string, index uint32) (*Full, error) {
+	seed, err := MnemonicSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromBIP39Seed(seed, index)
+}
+
+// FromBIP39Seed returns the keypair of the account at `index` of the wallet
+// with the provided BIP-39 seed, derived along the SEP-5 path
+// m/44'/148'/index'.
+func FromBIP39Seed(seed []byte, index uint32) (*Full, error) {
+	key, _, err := DeriveForPath(fmt.Sprintf(StellarAccountPathFormat, index), seed)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromRawSeed(key)
+}
+
+// DeriveForPath derives the ed25519 private key and chain code at `path` from
+// `seed` following SLIP-0010.  Every component of `path` must be hardened,
+// e.g. "m/44'/148'/0'".
+func DeriveForPath(path string, seed []byte) (key, chainCode [32]byte, err error) {
+	segments := strings.Split(path, "/")
+	if segments[0] != "m" {
+		return key, chainCode, ErrInvalidPath
+	}
+
+	mac := hmac.New(sha512.New, []byte(seedModifier))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	copy(key[:], sum[:32])
+	copy(chainCode[:], sum[32:])
+
+	for _, segment := range segments[1:] {
+		if !strings.HasSuffix(segment, "'") {
+			return key, chainCode, ErrInvalidPath
+		}
+
+		index, err := strconv.ParseUint(strings.TrimSuffix(segment, "'"), 10, 31)
+		if err != nil {
+			return key, chainCode, ErrInvalidPath
+		}
+
+		key, chainCode = deriveChild(key, chainCode, uint32(index)+FirstHardenedIndex)
+	}
+
+	return key, chainCode, nil
+}
+
+// deriveChild derives the hardened child key at `index` from the parent `key`
+// and `chainCode`.
+func deriveChild(key, chainCode [32]byte, index uint32) (childKey, childChainCode [32]byte) {
+	data := make([]byte, 37)
+	copy(data[1:33], key[:])
+	binary.BigEndian.PutUint32(data[33:], index)
+
+	mac := hmac.New(sha512.New, chainCode[:])
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	copy(childKey[:], sum[:32])
+	copy(childChainCode[:], sum[32:])
+	return
+}
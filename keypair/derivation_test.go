@@ -0,0 +1,114 @@
+package keypair
+
+import (
+	"encoding/hex"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("keypair derivation", func() {
+	type PathCase struct {
+		Path      string
+		Key       string
+		ChainCode string
+	}
+
+	// test vector 1 for ed25519 from
+	// https://github.com/satoshilabs/slips/blob/master/slip-0010.md
+	DescribeTable("DeriveForPath()",
+		func(c PathCase) {
+			seed, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+			Expect(err).To(BeNil())
+
+			key, chainCode, err := DeriveForPath(c.Path, seed)
+			Expect(err).To(BeNil())
+			Expect(hex.EncodeToString(key[:])).To(Equal(c.Key))
+			Expect(hex.EncodeToString(chainCode[:])).To(Equal(c.ChainCode))
+		},
+
+		Entry("m", PathCase{
+			"m",
+			"2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7",
+			"90046a93de5380a72b5e45010748567d5ea02bbf6522f979e05c0d8d8ca9fffb",
+		}),
+		Entry("m/0'", PathCase{
+			"m/0'",
+			"68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3",
+			"8b59aa11380b624e81507a27fedda59fea6d0b779a778918a2fd3590e16e9c69",
+		}),
+		Entry("m/0'/1'", PathCase{
+			"m/0'/1'",
+			"b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2",
+			"a320425f77d1b5c2505a6b1b27382b37368ee640e3557c315416801243552f14",
+		}),
+		Entry("m/0'/1'/2'", PathCase{
+			"m/0'/1'/2'",
+			"92a5b23c0b8a99e37d07df3fb9966917f5d06e02ddbd909c7e184371463e9fc9",
+			"2e69929e00b5ab250f49c3fb1c12f252de4fed2c1db88387094a0f8c4c9ccd6c",
+		}),
+	)
+
+	DescribeTable("DeriveForPath() with an invalid path",
+		func(path string) {
+			_, _, err := DeriveForPath(path, []byte("seed"))
+			Expect(err).To(Equal(ErrInvalidPath))
+		},
+		Entry("missing root", "44'/148'"),
+		Entry("non-hardened", "m/44'/148"),
+		Entry("not a number", "m/x'"),
+		Entry("out of range", "m/2147483648'"),
+	)
+
+	type AccountCase struct {
+		Index   uint32
+		Address string
+		Seed    string
+	}
+
+	// test vector 1 from
+	// https://github.com/stellar/stellar-protocol/blob/master/ecosystem/sep-0005.md
+	DescribeTable("FromMnemonic()",
+		func(c AccountCase) {
+			kp, err := FromMnemonic(
+				"illness spike retreat truth genius clock brain pass fit cave bargain toe",
+				"",
+				c.Index,
+			)
+			Expect(err).To(BeNil())
+			Expect(kp.Address()).To(Equal(c.Address))
+			Expect(kp.Seed()).To(Equal(c.Seed))
+		},
+
+		Entry("m/44'/148'/0'", AccountCase{
+			0,
+			"GDRXE2BQUC3AZNPVFSCEZ76NJ3WWL25FYFK6RGZGIEKWE4SOOHSUJUJ6",
+			"SBGWSG6BTNCKCOB3DIFBGCVMUPQFYPA2G4O34RMTB343OYPXU5DJDVMN",
+		}),
+		Entry("m/44'/148'/1'", AccountCase{
+			1,
+			"GBAW5XGWORWVFE2XTJYDTLDHXTY2Q2MO73HYCGB3XMFMQ562Q2W2GJQX",
+			"SCEPFFWGAG5P2VX5DHIYK3XEMZYLTYWIPWYEKXFHSK25RVMIUNJ7CTIS",
+		}),
+		Entry("m/44'/148'/2'", AccountCase{
+			2,
+			"GAY5PRAHJ2HIYBYCLZXTHID6SPVELOOYH2LBPH3LD4RUMXUW3DOYTLXW",
+			"SDAILLEZCSA67DUEP3XUPZJ7NYG7KGVRM46XA7K5QWWUIGADUZCZWTJP",
+		}),
+	)
+
+	It("derives the BIP-39 seed of the SEP-5 test vector", func() {
+		seed, err := MnemonicSeed(
+			"illness spike retreat truth genius clock brain pass fit cave bargain toe",
+			"",
+		)
+		Expect(err).To(BeNil())
+		Expect(hex.EncodeToString(seed)).To(Equal(
+			"e4a5a632e70943ae7f07659df1332160937fad82587216a4c64315a0fb39497ee4a01f76ddab4cba68147977f3a147b6ad584c41808e8238a07f6cc4b582f186",
+		))
+
+		_, err = FromMnemonic("not a mnemonic", "", 0)
+		Expect(err).To(Equal(ErrInvalidMnemonic))
+	})
+})
@@ -0,0 +1,268 @@
+package keypair
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+var (
+	// ErrWrongPassword is returned when a keystore cannot be decrypted with the
+	// provided password.
+	ErrWrongPassword = errors.New("keystore: wrong password")
+
+	// ErrUnsupportedKeystore is returned when reading a keystore that uses a
+	// version, key derivation function or cipher this package does not
+	// support.
+	ErrUnsupportedKeystore = errors.New("keystore: unsupported format")
+
+	// ErrAddressMismatch is returned when the seed decrypted from a keystore
+	// does not correspond to the address recorded in it.
+	ErrAddressMismatch = errors.New("keystore: seed does not match address")
+)
+
+const (
+	// KeystoreVersion is the version of the keystore format written by this
+	// package.
+	KeystoreVersion = 1
+
+	// KeystoreKDF is the key derivation function used to turn a password into
+	// an encryption key.
+	KeystoreKDF = "scrypt"
+
+	// KeystoreCipher is the authenticated cipher used to encrypt the seed.
+	KeystoreCipher = "aes-256-gcm"
+)
+
+// keystoreNonceSize is the size of the nonces used with KeystoreCipher.
+const keystoreNonceSize = 12
+
+// maxKeystoreScryptN is the largest scrypt cost parameter a keystore may
+// record, which bounds the memory needed to decrypt it.
+const maxKeystoreScryptN = 1 << 20
+
+// KeystoreScryptN is the scrypt cost parameter used when encrypting new
+// keystores.  Keystores record the parameters they were encrypted with, so
+// changing this value does not affect existing keystores.
+var KeystoreScryptN = 1 << 15
+
+// Keystore represents a seed encrypted with a password-derived key.  It is
+// serialized as JSON, with the account's address stored in clear so that
+// keystores can be listed and looked up without a password.
+type Keystore struct {
+	Version int            `json:"version"`
+	Address string         `json:"address"`
+	Crypto  KeystoreCrypto `json:"crypto"`
+}
+
+// KeystoreCrypto holds the parameters needed to decrypt a keystore.  Byte
+// fields are base64 encoded in the JSON form.
+type KeystoreCrypto struct {
+	KDF        string       `json:"kdf"`
+	KDFParams  ScryptParams `json:"kdfparams"`
+	Cipher     string       `json:"cipher"`
+	Nonce      []byte       `json:"nonce"`
+	Ciphertext []byte       `json:"ciphertext"`
+}
+
+// ScryptParams are the parameters of the scrypt key derivation function.
+type ScryptParams struct {
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt []byte `json:"salt"`
+}
+
+// EncryptKeystore encrypts the seed of `kp` using a key derived from
+// `password`.
+func EncryptKeystore(kp *Full, password string) (*Keystore, error) {
+	ks := &Keystore{
+		Version: KeystoreVersion,
+		Address: kp.Address(),
+	}
+
+	err := ks.encrypt(kp, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return ks, nil
+}
+
+// ReadKeystore reads the keystore stored at `path`.
+func ReadKeystore(path string) (*Keystore, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ks Keystore
+	err = json.Unmarshal(raw, &ks)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ks.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = Parse(ks.Address)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+
+	return &ks, nil
+}
+
+// LoadKeystore reads the keystore stored at `path` and decrypts it using
+// `password`.
+func LoadKeystore(path, password string) (KP, error) {
+	ks, err := ReadKeystore(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ks.Decrypt(password)
+}
+
+// Decrypt returns the keypair stored in the keystore, returning
+// ErrWrongPassword if `password` is incorrect.
+func (ks *Keystore) Decrypt(password string) (*Full, error) {
+	err := ks.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := ks.aead(password)
+	if err != nil {
+		return nil, err
+	}
+
+	seed, err := aead.Open(nil, ks.Crypto.Nonce, ks.Crypto.Ciphertext, []byte(ks.Address))
+	if err != nil {
+		return nil, ErrWrongPassword
+	}
+
+	kp, err := Parse(string(seed))
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+
+	full, ok := kp.(*Full)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+
+	if full.Address() != ks.Address {
+		return nil, ErrAddressMismatch
+	}
+
+	return full, nil
+}
+
+// Reencrypt changes the password protecting the keystore from `oldPassword`
+// to `newPassword`.  A fresh salt and nonce are used.
+func (ks *Keystore) Reencrypt(oldPassword, newPassword string) error {
+	kp, err := ks.Decrypt(oldPassword)
+	if err != nil {
+		return err
+	}
+
+	return ks.encrypt(kp, newPassword)
+}
+
+// Write stores the keystore at `path`, readable only by the current user.
+func (ks *Keystore) Write(path string) error {
+	raw, err := json.MarshalIndent(ks, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, raw, 0600)
+}
+
+func (ks *Keystore) encrypt(kp *Full, password string) error {
+	ks.Crypto = KeystoreCrypto{
+		KDF: KeystoreKDF,
+		KDFParams: ScryptParams{
+			N:    KeystoreScryptN,
+			R:    8,
+			P:    1,
+			Salt: make([]byte, 32),
+		},
+		Cipher: KeystoreCipher,
+	}
+
+	_, err := io.ReadFull(rand.Reader, ks.Crypto.KDFParams.Salt)
+	if err != nil {
+		return err
+	}
+
+	aead, err := ks.aead(password)
+	if err != nil {
+		return err
+	}
+
+	ks.Crypto.Nonce = make([]byte, aead.NonceSize())
+	_, err = io.ReadFull(rand.Reader, ks.Crypto.Nonce)
+	if err != nil {
+		return err
+	}
+
+	// the address is authenticated as additional data so that it cannot be
+	// altered without detection.
+	ks.Crypto.Ciphertext = aead.Seal(nil, ks.Crypto.Nonce, []byte(kp.Seed()), []byte(ks.Address))
+	return nil
+}
+
+// aead returns the cipher keyed with the key derived from `password`.
+func (ks *Keystore) aead(password string) (cipher.AEAD, error) {
+	params := ks.Crypto.KDFParams
+	key, err := scrypt.Key([]byte(password), params.Salt, params.N, params.R, params.P, 32)
+	if err != nil {
+		return nil, ErrUnsupportedKeystore
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return aead, nil
+}
+
+// validate returns ErrUnsupportedKeystore unless the keystore uses the
+// supported format and holds the parameters needed to decrypt it.
+func (ks *Keystore) validate() error {
+	if ks.Version != KeystoreVersion ||
+		ks.Crypto.KDF != KeystoreKDF ||
+		ks.Crypto.Cipher != KeystoreCipher {
+		return ErrUnsupportedKeystore
+	}
+
+	// scrypt requires N to be a power of two greater than 1
+	params := ks.Crypto.KDFParams
+	if params.N <= 1 || params.N > maxKeystoreScryptN || params.N&(params.N-1) != 0 ||
+		params.R <= 0 || params.P <= 0 || params.R*params.P >= 1<<30 ||
+		len(params.Salt) == 0 {
+		return ErrUnsupportedKeystore
+	}
+
+	if len(ks.Crypto.Nonce) != keystoreNonceSize || len(ks.Crypto.Ciphertext) == 0 {
+		return ErrUnsupportedKeystore
+	}
+
+	return nil
+}
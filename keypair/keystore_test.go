@@ -0,0 +1,125 @@
+package keypair
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("keypair.Keystore", func() {
+	var (
+		dir   string
+		path  string
+		kp    *Full
+		prevN int
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		// keep the tests fast
+		prevN = KeystoreScryptN
+		KeystoreScryptN = 1 << 10
+
+		dir, err = ioutil.TempDir("", "keystore")
+		Expect(err).To(BeNil())
+		path = filepath.Join(dir, "key.json")
+
+		kp = &Full{seed}
+		ks, err := EncryptKeystore(kp, "hunter2")
+		Expect(err).To(BeNil())
+		Expect(ks.Write(path)).To(BeNil())
+	})
+
+	AfterEach(func() {
+		KeystoreScryptN = prevN
+		os.RemoveAll(dir)
+	})
+
+	It("stores the address in clear and not the seed", func() {
+		raw, err := ioutil.ReadFile(path)
+		Expect(err).To(BeNil())
+		Expect(string(raw)).To(ContainSubstring(address))
+		Expect(string(raw)).NotTo(ContainSubstring(seed))
+
+		info, err := os.Stat(path)
+		Expect(err).To(BeNil())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+
+	It("loads the keypair with the right password", func() {
+		loaded, err := LoadKeystore(path, "hunter2")
+		Expect(err).To(BeNil())
+		Expect(loaded.Address()).To(Equal(address))
+		Expect(loaded.(*Full).Seed()).To(Equal(seed))
+	})
+
+	It("rejects the wrong password", func() {
+		_, err := LoadKeystore(path, "hunter3")
+		Expect(err).To(Equal(ErrWrongPassword))
+	})
+
+	It("detects a tampered address", func() {
+		ks, err := ReadKeystore(path)
+		Expect(err).To(BeNil())
+
+		ks.Address = "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU"
+		_, err = ks.Decrypt("hunter2")
+		Expect(err).To(Equal(ErrWrongPassword))
+	})
+
+	It("re-encrypts with a new password", func() {
+		ks, err := ReadKeystore(path)
+		Expect(err).To(BeNil())
+
+		Expect(ks.Reencrypt("wrong", "new")).To(Equal(ErrWrongPassword))
+		Expect(ks.Reencrypt("hunter2", "new")).To(BeNil())
+		Expect(ks.Write(path)).To(BeNil())
+
+		_, err = LoadKeystore(path, "hunter2")
+		Expect(err).To(Equal(ErrWrongPassword))
+
+		loaded, err := LoadKeystore(path, "new")
+		Expect(err).To(BeNil())
+		Expect(loaded.Address()).To(Equal(address))
+	})
+
+	It("rejects unsupported keystores", func() {
+		ks, err := ReadKeystore(path)
+		Expect(err).To(BeNil())
+
+		ks.Crypto.Cipher = "rot13"
+		Expect(ks.Write(path)).To(BeNil())
+
+		_, err = ReadKeystore(path)
+		Expect(err).To(Equal(ErrUnsupportedKeystore))
+	})
+
+	It("rejects keystores missing their nonce or kdf params", func() {
+		mutations := map[string]func(*Keystore){
+			"nonce":       func(ks *Keystore) { ks.Crypto.Nonce = nil },
+			"short nonce": func(ks *Keystore) { ks.Crypto.Nonce = ks.Crypto.Nonce[:4] },
+			"salt":        func(ks *Keystore) { ks.Crypto.KDFParams.Salt = nil },
+			"kdf params":  func(ks *Keystore) { ks.Crypto.KDFParams = ScryptParams{} },
+			"bad n":       func(ks *Keystore) { ks.Crypto.KDFParams.N = 1000 },
+			"huge n":      func(ks *Keystore) { ks.Crypto.KDFParams.N = 1 << 30 },
+		}
+
+		for name, mutate := range mutations {
+			ks, err := ReadKeystore(path)
+			Expect(err).To(BeNil())
+
+			mutate(ks)
+			_, err = ks.Decrypt("hunter2")
+			Expect(err).To(Equal(ErrUnsupportedKeystore), name)
+
+			broken := filepath.Join(dir, "broken.json")
+			Expect(ks.Write(broken)).To(BeNil())
+			_, err = LoadKeystore(broken, "hunter2")
+			Expect(err).To(Equal(ErrUnsupportedKeystore), name)
+		}
+	})
+})
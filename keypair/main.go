@@ -73,21 +73,19 @@ func Master(networkPassphrase string) KP {
 // an address, or a seed.  If the provided input is a seed, the resulting KP
 // will have signing capabilities.
 func Parse(addressOrSeed string) (KP, error) {
-	_, err := strkey.Decode(strkey.VersionByteAccountID, addressOrSeed)
-	if err == nil {
-		return &FromAddress{addressOrSeed}, nil
-	}
-
-	if err != strkey.ErrInvalidVersionByte {
+	k, err := strkey.ParseKey(addressOrSeed)
+	if err != nil {
 		return nil, err
 	}
 
-	_, err = strkey.Decode(strkey.VersionByteSeed, addressOrSeed)
-	if err == nil {
+	switch k.(type) {
+	case strkey.AccountID:
+		return &FromAddress{addressOrSeed}, nil
+	case strkey.Seed:
 		return &Full{addressOrSeed}, nil
+	default:
+		return nil, strkey.ErrInvalidVersionByte
 	}
-
-	return nil, err
 }
 
 // FromRawSeed creates a new keypair from the provided raw ED25519 seed:w
@@ -0,0 +1,51 @@
+package keypair
+
+import (
+	"encoding/base64"
+
+	"github.com/stellar/go/hash"
+)
+
+// SignedMessagePrefix is prepended to every message signed by SignMessage.
+// Transaction signatures cover the hash of a payload that starts with a
+// network ID, so the prefix ensures that a signed message can never be
+// mistaken for (or replayed as) the signature of a transaction.
+const SignedMessagePrefix = "Stellar Signed Message:\n"
+
+// MessageHash returns the hash that is signed when signing `message`.
+func MessageHash(message []byte) [32]byte {
+	payload := make([]byte, 0, len(SignedMessagePrefix)+len(message))
+	payload = append(payload, SignedMessagePrefix...)
+	payload = append(payload, message...)
+	return hash.Hash(payload)
+}
+
+// SignMessage signs `message`, prefixed with SignedMessagePrefix, using `kp`
+// and returns the base64 encoded signature.
+func SignMessage(kp KP, message []byte) (string, error) {
+	h := MessageHash(message)
+	sig, err := kp.Sign(h[:])
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// VerifyMessage checks that `signature`, as produced by SignMessage, is a
+// valid signature of `message` by the account `address`.  It returns
+// ErrInvalidSignature if it is not.
+func VerifyMessage(address string, message []byte, signature string) error {
+	kp, err := Parse(address)
+	if err != nil {
+		return err
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	h := MessageHash(message)
+	return kp.Verify(h[:], sig)
+}
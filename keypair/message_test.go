@@ -0,0 +1,61 @@
+package keypair
+
+import (
+	"encoding/base64"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// messageSignature is the signature of "I own this account" by `seed`
+const messageSignature = "vCDEBnfVkjbvkkxviVKQMDmCfPWB7Bl8hSU5BJ0vMJX92ulkNF3BxE4X9UvZOE+M3qybGNfyCPMvF2mHxhufAA=="
+
+var _ = Describe("keypair message signing", func() {
+	var (
+		kp      *Full
+		msg     []byte
+		signed  string
+		signErr error
+	)
+
+	BeforeEach(func() {
+		kp = &Full{seed}
+		msg = []byte("I own this account")
+		signed, signErr = SignMessage(kp, msg)
+	})
+
+	It("produces a deterministic base64 signature", func() {
+		Expect(signErr).To(BeNil())
+		Expect(signed).To(Equal(messageSignature))
+	})
+
+	It("verifies against the signing address", func() {
+		Expect(VerifyMessage(address, msg, signed)).To(BeNil())
+	})
+
+	It("rejects altered messages and other accounts", func() {
+		Expect(VerifyMessage(address, []byte("I own that account"), signed)).
+			To(Equal(ErrInvalidSignature))
+
+		Expect(VerifyMessage("GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU", msg, signed)).
+			To(Equal(ErrInvalidSignature))
+
+		Expect(VerifyMessage(address, msg, "not base64!")).To(Equal(ErrInvalidSignature))
+	})
+
+	It("is not interchangeable with raw signatures", func() {
+		raw, err := kp.Sign(msg)
+		Expect(err).To(BeNil())
+		Expect(VerifyMessage(address, msg, base64.StdEncoding.EncodeToString(raw))).
+			To(Equal(ErrInvalidSignature))
+
+		sig, err := base64.StdEncoding.DecodeString(signed)
+		Expect(err).To(BeNil())
+		Expect(kp.Verify(msg, sig)).To(Equal(ErrInvalidSignature))
+	})
+
+	It("cannot sign without a seed", func() {
+		_, err := SignMessage(&FromAddress{address}, msg)
+		Expect(err).To(Equal(ErrCannotSign))
+	})
+})
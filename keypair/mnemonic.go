@@ -0,0 +1,148 @@
+package keypair
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"io"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+var (
+	// ErrInvalidEntropy is returned when generating a mnemonic from entropy
+	// whose length is not a multiple of 32 bits between 128 and 256 bits.
+	ErrInvalidEntropy = errors.New("invalid entropy length")
+
+	// ErrInvalidMnemonic is returned when a mnemonic contains an unknown word,
+	// has an unsupported number of words or fails its checksum.
+	ErrInvalidMnemonic = errors.New("invalid mnemonic")
+)
+
+const (
+	// DefaultMnemonicBits is the amount of entropy, in bits, encoded by the 24
+	// word mnemonics that are recommended for stellar wallets.
+	DefaultMnemonicBits = 256
+
+	// mnemonicSeedIterations is the number of pbkdf2 rounds BIP-39 uses to
+	// stretch a mnemonic into a seed.
+	mnemonicSeedIterations = 2048
+)
+
+// NewMnemonic returns a new BIP-39 mnemonic encoding `bits` bits of random
+// entropy.  `bits` must be a multiple of 32 between 128 and 256, yielding a
+// mnemonic of between 12 and 24 words.
+func NewMnemonic(bits int) (string, error) {
+	if !validEntropyBits(bits) {
+		return "", ErrInvalidEntropy
+	}
+
+	entropy := make([]byte, bits/8)
+	_, err := io.ReadFull(rand.Reader, entropy)
+	if err != nil {
+		return "", err
+	}
+
+	return MnemonicFromEntropy(entropy)
+}
+
+// MnemonicFromEntropy returns the BIP-39 mnemonic that encodes `entropy`.
+func MnemonicFromEntropy(entropy []byte) (string, error) {
+	bits := len(entropy) * 8
+	if !validEntropyBits(bits) {
+		return "", ErrInvalidEntropy
+	}
+
+	checksumBits := uint(bits / 32)
+	sum := sha256.Sum256(entropy)
+
+	// append the checksum to the entropy, then read it back 11 bits at a time
+	data := new(big.Int).SetBytes(entropy)
+	data.Lsh(data, checksumBits)
+	data.Or(data, big.NewInt(int64(sum[0]>>(8-checksumBits))))
+
+	count := (bits + int(checksumBits)) / 11
+	words := make([]string, count)
+	mask := big.NewInt(2047)
+	index := new(big.Int)
+
+	for i := count - 1; i >= 0; i-- {
+		index.And(data, mask)
+		words[i] = wordlist[index.Int64()]
+		data.Rsh(data, 11)
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy returns the entropy encoded by `mnemonic`, checking that
+// every word is known and that the checksum is valid.
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+
+	// each word encodes 11 bits, of which one in every 33 is checksum
+	totalBits := len(words) * 11
+	checksumBits := uint(totalBits / 33)
+	entropyBits := totalBits - int(checksumBits)
+	if len(words)%3 != 0 || !validEntropyBits(entropyBits) {
+		return nil, ErrInvalidMnemonic
+	}
+
+	data := new(big.Int)
+	for _, word := range words {
+		index, ok := wordIndex[word]
+		if !ok {
+			return nil, ErrInvalidMnemonic
+		}
+
+		data.Lsh(data, 11)
+		data.Or(data, big.NewInt(int64(index)))
+	}
+
+	checksum := new(big.Int).And(data, big.NewInt(1<<checksumBits-1))
+	data.Rsh(data, checksumBits)
+
+	entropy := make([]byte, entropyBits/8)
+	raw := data.Bytes()
+	copy(entropy[len(entropy)-len(raw):], raw)
+
+	sum := sha256.Sum256(entropy)
+	if checksum.Int64() != int64(sum[0]>>(8-checksumBits)) {
+		return nil, ErrInvalidMnemonic
+	}
+
+	return entropy, nil
+}
+
+// ValidateMnemonic returns ErrInvalidMnemonic if `mnemonic` is not a valid
+// BIP-39 mnemonic.
+func ValidateMnemonic(mnemonic string) error {
+	_, err := MnemonicToEntropy(mnemonic)
+	return err
+}
+
+// MnemonicSeed returns the 64 byte BIP-39 seed derived from `mnemonic`, which
+// is first validated, and the optional `passphrase`.  BIP-39 requires the
+// passphrase to be NFKD normalized; callers accepting non-ASCII passphrases
+// must normalize them before calling this function.
+func MnemonicSeed(mnemonic, passphrase string) ([]byte, error) {
+	err := ValidateMnemonic(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+
+	return pbkdf2.Key(
+		[]byte(strings.Join(strings.Fields(mnemonic), " ")),
+		[]byte("mnemonic"+passphrase),
+		mnemonicSeedIterations,
+		64,
+		sha512.New,
+	), nil
+}
+
+func validEntropyBits(bits int) bool {
+	return bits >= 128 && bits <= 256 && bits%32 == 0
+}
@@ -0,0 +1,74 @@
+package keypair
+
+import (
+	"encoding/hex"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("keypair mnemonics", func() {
+	type MnemonicCase struct {
+		Entropy  string
+		Mnemonic string
+		Seed     string
+	}
+
+	// vectors from https://github.com/trezor/python-mnemonic/blob/master/vectors.json,
+	// using the passphrase "TREZOR"
+	DescribeTable("MnemonicFromEntropy() and MnemonicSeed()",
+		func(c MnemonicCase) {
+			entropy, err := hex.DecodeString(c.Entropy)
+			Expect(err).To(BeNil())
+
+			mnemonic, err := MnemonicFromEntropy(entropy)
+			Expect(err).To(BeNil())
+			Expect(mnemonic).To(Equal(c.Mnemonic))
+
+			decoded, err := MnemonicToEntropy(mnemonic)
+			Expect(err).To(BeNil())
+			Expect(hex.EncodeToString(decoded)).To(Equal(c.Entropy))
+
+			seed, err := MnemonicSeed(mnemonic, "TREZOR")
+			Expect(err).To(BeNil())
+			Expect(hex.EncodeToString(seed)).To(Equal(c.Seed))
+		},
+
+		Entry("128 bits", MnemonicCase{
+			"00000000000000000000000000000000",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		}),
+	)
+
+	It("encodes 256 bits of entropy as 24 words", func() {
+		entropy, err := hex.DecodeString(strings.Repeat("ff", 32))
+		Expect(err).To(BeNil())
+
+		mnemonic, err := MnemonicFromEntropy(entropy)
+		Expect(err).To(BeNil())
+		Expect(mnemonic).To(Equal(strings.Repeat("zoo ", 23) + "vote"))
+	})
+
+	It("generates valid mnemonics", func() {
+		mnemonic, err := NewMnemonic(DefaultMnemonicBits)
+		Expect(err).To(BeNil())
+		Expect(strings.Fields(mnemonic)).To(HaveLen(24))
+		Expect(ValidateMnemonic(mnemonic)).To(BeNil())
+
+		_, err = NewMnemonic(100)
+		Expect(err).To(Equal(ErrInvalidEntropy))
+	})
+
+	DescribeTable("ValidateMnemonic()",
+		func(mnemonic string) {
+			Expect(ValidateMnemonic(mnemonic)).To(Equal(ErrInvalidMnemonic))
+		},
+		Entry("bad checksum", strings.Repeat("abandon ", 12)),
+		Entry("unknown word", strings.Repeat("abandon ", 11)+"stellar"),
+		Entry("bad length", strings.Repeat("abandon ", 10)+"about"),
+		Entry("empty", ""),
+	)
+})
@@ -0,0 +1,236 @@
+package keypair
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"io"
+
+	"github.com/stellar/go/crc16"
+)
+
+var (
+	// ErrInvalidShare is returned when a share cannot be decoded, usually
+	// because it was mistyped.
+	ErrInvalidShare = errors.New("invalid share")
+
+	// ErrInsufficientShares is returned when combining fewer shares than the
+	// threshold they were split with, or shares from different splits.
+	ErrInsufficientShares = errors.New("insufficient shares")
+
+	// ErrInvalidThreshold is returned when splitting a seed with a threshold
+	// that is less than 2 or greater than the number of shares.
+	ErrInvalidThreshold = errors.New("invalid threshold")
+)
+
+// shareVersionByte is the first byte of an encoded share.  It base32-encodes
+// to 'P...'.
+const shareVersionByte = 15 << 3
+
+// shareEncoding is the encoding of shares.  Shares are 37 bytes long, so
+// padding is omitted.
+var shareEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// MaxShares is the maximum number of shares a seed can be split into.
+const MaxShares = 255
+
+// SplitSeed splits the seed of `kp` into `n` shares using Shamir's secret
+// sharing scheme, such that any `k` of them can be combined to recover the
+// seed while fewer than `k` reveal nothing about it.
+//
+// Each share is a base32 string that starts with 'P' and that, like the
+// addresses and seeds produced by the strkey package, includes a crc16
+// checksum so that typos are detected.
+func SplitSeed(kp *Full, n, k int) ([]string, error) {
+	if n < 2 || n > MaxShares || k < 2 || k > n {
+		return nil, ErrInvalidThreshold
+	}
+
+	ys, err := splitSecret(kp.rawSeed(), n, k, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := make([]string, n)
+	for i, y := range ys {
+		shares[i] = encodeShare(byte(k), byte(i+1), y)
+	}
+
+	return shares, nil
+}
+
+// CombineShares recovers the keypair whose seed was split into `shares` by
+// SplitSeed.  At least as many shares as the threshold used when splitting
+// must be provided.
+func CombineShares(shares []string) (*Full, error) {
+	if len(shares) == 0 {
+		return nil, ErrInsufficientShares
+	}
+
+	var (
+		threshold byte
+		xs        []byte
+		ys        [][]byte
+	)
+
+	for i, share := range shares {
+		k, x, y, err := decodeShare(share)
+		if err != nil {
+			return nil, err
+		}
+
+		if i == 0 {
+			threshold = k
+		}
+
+		if k != threshold || bytes.IndexByte(xs, x) != -1 {
+			return nil, ErrInsufficientShares
+		}
+
+		xs = append(xs, x)
+		ys = append(ys, y)
+	}
+
+	if len(xs) < int(threshold) {
+		return nil, ErrInsufficientShares
+	}
+
+	var rawSeed [32]byte
+	copy(rawSeed[:], combineSecret(xs[:threshold], ys[:threshold]))
+	return FromRawSeed(rawSeed)
+}
+
+// splitSecret returns the values at x = 1..n of a random polynomial of degree
+// k-1 whose value at 0 is `secret`, one polynomial per byte of the secret.
+func splitSecret(secret []byte, n, k int, random io.Reader) ([][]byte, error) {
+	coefficients := make([]byte, k-1)
+	ys := make([][]byte, n)
+	for i := range ys {
+		ys[i] = make([]byte, len(secret))
+	}
+
+	for j, s := range secret {
+		_, err := io.ReadFull(random, coefficients)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range ys {
+			ys[i][j] = evaluate(s, coefficients, byte(i+1))
+		}
+	}
+
+	return ys, nil
+}
+
+// combineSecret recovers the secret from the points (xs[i], ys[i]) by
+// interpolating each byte's polynomial at 0.
+func combineSecret(xs []byte, ys [][]byte) []byte {
+	secret := make([]byte, len(ys[0]))
+	column := make([]byte, len(xs))
+
+	for j := range secret {
+		for i := range xs {
+			column[i] = ys[i][j]
+		}
+		secret[j] = interpolate(xs, column, 0)
+	}
+
+	return secret
+}
+
+// evaluate returns the value at `x` of the polynomial with constant term `c`
+// and the remaining coefficients `coefficients`, in increasing order of
+// degree.
+func evaluate(c byte, coefficients []byte, x byte) byte {
+	result := byte(0)
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		result = gfMul(result, x) ^ coefficients[i]
+	}
+	return gfMul(result, x) ^ c
+}
+
+// interpolate returns the value at `x` of the lowest degree polynomial passing
+// through the points (xs[i], ys[i]).
+func interpolate(xs, ys []byte, x byte) byte {
+	result := byte(0)
+
+	for i := range xs {
+		basis := byte(1)
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			basis = gfMul(basis, gfDiv(x^xs[j], xs[i]^xs[j]))
+		}
+		result ^= gfMul(ys[i], basis)
+	}
+
+	return result
+}
+
+// gfExp and gfLog are exponent and logarithm tables for GF(2^8), using the
+// AES reduction polynomial x^8 + x^4 + x^3 + x + 1 and the generator 3.
+var gfExp, gfLog = func() (exp [510]byte, log [256]byte) {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		exp[i] = x
+		exp[i+255] = x
+		log[x] = byte(i)
+
+		// multiply x by the generator 3 (that is, x + 1)
+		high := x & 0x80
+		next := x << 1
+		if high != 0 {
+			next ^= 0x1b
+		}
+		x ^= next
+	}
+	return
+}()
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+255-int(gfLog[b])]
+}
+
+// encodeShare encodes the `y` values of share `x` of a split with threshold
+// `k`.
+func encodeShare(k, x byte, y []byte) string {
+	var raw bytes.Buffer
+	raw.WriteByte(shareVersionByte)
+	raw.WriteByte(k)
+	raw.WriteByte(x)
+	raw.Write(y)
+	raw.Write(crc16.Checksum(raw.Bytes()))
+
+	return shareEncoding.EncodeToString(raw.Bytes())
+}
+
+func decodeShare(src string) (k, x byte, y []byte, err error) {
+	raw, err := shareEncoding.DecodeString(src)
+	if err != nil || len(raw) != 3+32+2 {
+		return 0, 0, nil, ErrInvalidShare
+	}
+
+	payload, checksum := raw[:len(raw)-2], raw[len(raw)-2:]
+	if crc16.Validate(payload, checksum) != nil {
+		return 0, 0, nil, ErrInvalidShare
+	}
+
+	if payload[0] != shareVersionByte || payload[1] < 2 || payload[2] == 0 {
+		return 0, 0, nil, ErrInvalidShare
+	}
+
+	return payload[1], payload[2], payload[3:], nil
+}
@@ -0,0 +1,124 @@
+package keypair
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("keypair shamir secret sharing", func() {
+	var (
+		kp     *Full
+		shares []string
+	)
+
+	BeforeEach(func() {
+		var err error
+		kp = &Full{seed}
+		shares, err = SplitSeed(kp, 5, 3)
+		Expect(err).To(BeNil())
+	})
+
+	It("produces checksummed shares", func() {
+		Expect(shares).To(HaveLen(5))
+		for _, share := range shares {
+			Expect(share).To(HavePrefix("P"))
+		}
+	})
+
+	It("recovers the seed from any 3 shares", func() {
+		for i := 0; i < 5; i++ {
+			for j := i + 1; j < 5; j++ {
+				for k := j + 1; k < 5; k++ {
+					recovered, err := CombineShares([]string{shares[k], shares[i], shares[j]})
+					Expect(err).To(BeNil())
+					Expect(recovered.Seed()).To(Equal(seed))
+				}
+			}
+		}
+
+		recovered, err := CombineShares(shares)
+		Expect(err).To(BeNil())
+		Expect(recovered.Seed()).To(Equal(seed))
+	})
+
+	It("round trips through FromRawSeed", func() {
+		random, err := Random()
+		Expect(err).To(BeNil())
+
+		split, err := SplitSeed(random, 2, 2)
+		Expect(err).To(BeNil())
+
+		recovered, err := CombineShares(split)
+		Expect(err).To(BeNil())
+
+		var raw [32]byte
+		copy(raw[:], recovered.rawSeed())
+		fromRaw, err := FromRawSeed(raw)
+		Expect(err).To(BeNil())
+		Expect(fromRaw.Address()).To(Equal(random.Address()))
+	})
+
+	It("refuses to combine too few shares", func() {
+		_, err := CombineShares(shares[:2])
+		Expect(err).To(Equal(ErrInsufficientShares))
+
+		_, err = CombineShares([]string{shares[0], shares[0], shares[1]})
+		Expect(err).To(Equal(ErrInsufficientShares))
+
+		_, err = CombineShares(nil)
+		Expect(err).To(Equal(ErrInsufficientShares))
+	})
+
+	It("reveals nothing about the seed with 2 shares", func() {
+		// Every candidate value of a secret byte is consistent with any 2
+		// shares: each determines a distinct polynomial (and so a distinct
+		// third share), so all 256 candidates remain equally likely.
+		var xs, ys []byte
+		for _, share := range shares[:2] {
+			_, x, y, err := decodeShare(share)
+			Expect(err).To(BeNil())
+			xs = append(xs, x)
+			ys = append(ys, y[0])
+		}
+
+		thirds := map[byte]bool{}
+		for candidate := 0; candidate < 256; candidate++ {
+			points := append([]byte{0}, xs...)
+			values := append([]byte{byte(candidate)}, ys...)
+
+			// the polynomial through the candidate passes through both shares
+			for i := range xs {
+				Expect(interpolate(points, values, xs[i])).To(Equal(ys[i]))
+			}
+
+			thirds[interpolate(points, values, 3)] = true
+		}
+		Expect(thirds).To(HaveLen(256))
+	})
+
+	It("detects typos", func() {
+		share := shares[0]
+		typo := share[:10] + strings.Map(func(r rune) rune {
+			if r == 'A' {
+				return 'B'
+			}
+			return 'A'
+		}, share[10:11]) + share[11:]
+
+		_, err := CombineShares([]string{typo, shares[1], shares[2]})
+		Expect(err).To(Equal(ErrInvalidShare))
+
+		_, err = CombineShares([]string{seed, shares[1], shares[2]})
+		Expect(err).To(Equal(ErrInvalidShare))
+	})
+
+	It("validates the threshold", func() {
+		_, err := SplitSeed(kp, 3, 4)
+		Expect(err).To(Equal(ErrInvalidThreshold))
+
+		_, err = SplitSeed(kp, 3, 1)
+		Expect(err).To(Equal(ErrInvalidThreshold))
+	})
+})
@@ -19,6 +19,12 @@ type IDResponse struct {
 	Address string `json:"stellar_address"`
 }
 
+// TxIDResponse represents the result of a federation request
+// for `txid` request: the address of the sender of the transaction.
+type TxIDResponse struct {
+	Address string `json:"stellar_address"`
+}
+
 // Memo value can be either integer or string in JSON. This struct
 // allows marshaling and unmarshaling both types.
 type Memo struct {
@@ -10,6 +10,7 @@ import (
 	"github.com/rs/cors"
 	"github.com/spf13/cobra"
 	complianceHandler "github.com/stellar/go/handlers/compliance"
+	"github.com/stellar/go/keypair"
 	complianceProtocol "github.com/stellar/go/protocols/compliance"
 	"github.com/stellar/go/support/app"
 	"github.com/stellar/go/support/config"
@@ -18,6 +19,10 @@ import (
 	"github.com/stellar/go/support/log"
 )
 
+// KeystorePasswordEnv is the environment variable holding the password of the
+// keystore configured by `keys.signing_keystore`.
+const KeystorePasswordEnv = "COMPLIANCE_KEYSTORE_PASSWORD"
+
 // Config represents the configuration of a federation server
 type Config struct {
 	ExternalPort      int    `valid:"required" toml:"external_port"`
@@ -25,7 +30,8 @@ type Config struct {
 	NeedsAuth         bool   `valid:"required" toml:"needs_auth"`
 	NetworkPassphrase string `valid:"required" toml:"network_passphrase"`
 	Keys              struct {
-		SigningSeed string `valid:"stellar_seed,required" toml:"signing_seed"`
+		SigningSeed     string `valid:"stellar_seed,optional" toml:"signing_seed"`
+		SigningKeystore string `valid:"optional" toml:"signing_keystore"`
 	} `valid:"required"`
 	Callbacks struct {
 		Sanctions   string `valid:"url,optional" toml:"sanctions"`
@@ -68,6 +74,12 @@ func run(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	signer, err := signingKey(cfg)
+	if err != nil {
+		log.Error(err)
+		os.Exit(1)
+	}
+
 	strategy := &complianceHandler.CallbackStrategy{
 		SanctionsCheckURL: cfg.Callbacks.Sanctions,
 		GetUserDataURL:    cfg.Callbacks.GetUserData,
@@ -84,10 +96,37 @@ func run(cmd *cobra.Command, args []string) {
 		OnStarting: func() {
 			log.Infof("starting compliance server - %s", app.Version())
 			log.Infof("listening on %s", addr)
+			log.Infof("signing as %s", signer.Address())
 		},
 	})
 }
 
+// signingKey loads the key the server signs with, either from the seed or
+// from the keystore named in the config.  Exactly one of the two must be set.
+func signingKey(cfg Config) (keypair.KP, error) {
+	seed, path := cfg.Keys.SigningSeed, cfg.Keys.SigningKeystore
+
+	switch {
+	case seed != "" && path != "":
+		return nil, errors.New("config file: only one of keys.signing_seed and keys.signing_keystore may be set")
+	case seed != "":
+		return keypair.Parse(seed)
+	case path != "":
+		password, ok := os.LookupEnv(KeystorePasswordEnv)
+		if !ok {
+			return nil, errors.Errorf("%s must be set to load the signing keystore", KeystorePasswordEnv)
+		}
+
+		kp, err := keypair.LoadKeystore(path, password)
+		if err != nil {
+			return nil, errors.Wrap(err, "load signing keystore failed")
+		}
+		return kp, nil
+	default:
+		return nil, errors.New("config file: one of keys.signing_seed and keys.signing_keystore is required")
+	}
+}
+
 func initMux(strategy complianceHandler.Strategy) *goji.Mux {
 	mux := goji.NewMux()
 
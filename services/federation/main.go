@@ -28,6 +28,11 @@ type Config struct {
 	Queries struct {
 		Federation        string `valid:"required"`
 		ReverseFederation string `toml:"reverse-federation" valid:"optional"`
+		TxIDFederation    string `toml:"txid-federation" valid:"optional"`
+		Forward           map[string]struct {
+			Query  string   `valid:"required"`
+			Params []string `valid:"optional"`
+		} `valid:"optional"`
 	} `valid:"required"`
 	TLS struct {
 		CertificateFile string `toml:"certificate-file" valid:"required"`
@@ -116,16 +121,46 @@ func initDriver(cfg Config) (federation.Driver, error) {
 		LookupRecordQuery: cfg.Queries.Federation,
 	}
 
-	if cfg.Queries.ReverseFederation == "" {
+	if cfg.Queries.ReverseFederation == "" &&
+		cfg.Queries.TxIDFederation == "" &&
+		len(cfg.Queries.Forward) == 0 {
 		return &sqld, nil
 	}
 
-	rsqld := federation.ReverseSQLDriver{
-		SQLDriver:                sqld,
-		LookupReverseRecordQuery: cfg.Queries.ReverseFederation,
+	if len(cfg.Queries.Forward) == 0 {
+		rsqld := federation.ReverseSQLDriver{
+			SQLDriver:                sqld,
+			LookupReverseRecordQuery: cfg.Queries.ReverseFederation,
+			LookupTxIDRecordQuery:    cfg.Queries.TxIDFederation,
+		}
+
+		return &rsqld, nil
+	}
+
+	forward := map[string]federation.ForwardQuery{}
+	for typ, fq := range cfg.Queries.Forward {
+		forward[typ] = federation.ForwardQuery{
+			Query:  fq.Query,
+			Params: fq.Params,
+		}
+	}
+
+	// build the SQLDriver in place rather than copying sqld, whose sync.Once
+	// must not be copied
+	fsqld := federation.ForwardSQLDriver{
+		ReverseSQLDriver: federation.ReverseSQLDriver{
+			SQLDriver: federation.SQLDriver{
+				DB:                repo.DB.DB,
+				Dialect:           dialect,
+				LookupRecordQuery: cfg.Queries.Federation,
+			},
+			LookupReverseRecordQuery: cfg.Queries.ReverseFederation,
+			LookupTxIDRecordQuery:    cfg.Queries.TxIDFederation,
+		},
+		LookupForwardingRecordQueries: forward,
 	}
 
-	return &rsqld, nil
+	return &fsqld, nil
 }
 
 func initMux(driver federation.Driver) *goji.Mux {
@@ -0,0 +1,198 @@
+package strkey
+
+import (
+	"fmt"
+
+	"github.com/stellar/go/crc16"
+	"github.com/stellar/go/support/errors"
+)
+
+// Key is a decoded strkey.  Each kind of strkey is represented by its own
+// type, so callers that accept several kinds can switch on the type of the
+// value returned by ParseKey.
+type Key interface {
+	// Version returns the version byte of the key.
+	Version() VersionByte
+
+	// Address returns the strkey encoded form of the key.
+	Address() string
+
+	// Raw returns the 32 byte payload of the key.
+	Raw() [32]byte
+}
+
+// AccountID is a decoded account ID, the 'G...' public key of an account.
+type AccountID struct{ raw [32]byte }
+
+// Seed is a decoded 'S...' secret seed.
+type Seed struct{ raw [32]byte }
+
+// HashTx is a decoded 'T...' pre-authorized transaction signer key.
+type HashTx struct{ raw [32]byte }
+
+// HashX is a decoded 'X...' hash(x) signer key.
+type HashX struct{ raw [32]byte }
+
+var (
+	_ Key = AccountID{}
+	_ Key = Seed{}
+	_ Key = HashTx{}
+	_ Key = HashX{}
+)
+
+// DecodeAny decodes the provided StrKey, checking its checksum, and returns
+// the version byte it was encoded with along with the raw value.  Unlike
+// Decode, the caller need not know which kind of strkey `src` is.
+func DecodeAny(src string) (VersionByte, []byte, error) {
+	raw, err := decodeString(src)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	version := VersionByte(raw[0])
+	vp := raw[0 : len(raw)-2]
+	payload := raw[1 : len(raw)-2]
+	checksum := raw[len(raw)-2:]
+
+	if err := checkValidVersionByte(version); err != nil {
+		return 0, nil, err
+	}
+
+	if err := crc16.Validate(vp, checksum); err != nil {
+		return 0, nil, err
+	}
+
+	return version, payload, nil
+}
+
+// ParseKey decodes the provided StrKey into the typed key of the kind it
+// encodes: an AccountID, Seed, HashTx or HashX.
+func ParseKey(src string) (Key, error) {
+	version, payload, err := DecodeAny(src)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payload) != 32 {
+		return nil, errors.Errorf("invalid %s: payload is %d bytes; expected 32", version, len(payload))
+	}
+
+	var raw [32]byte
+	copy(raw[:], payload)
+
+	switch version {
+	case VersionByteAccountID:
+		return AccountID{raw}, nil
+	case VersionByteSeed:
+		return Seed{raw}, nil
+	case VersionByteHashTx:
+		return HashTx{raw}, nil
+	default:
+		return HashX{raw}, nil
+	}
+}
+
+// ParseAccountID decodes the provided 'G...' StrKey.
+func ParseAccountID(src string) (AccountID, error) {
+	k, err := parseExpected(VersionByteAccountID, src)
+	if err != nil {
+		return AccountID{}, err
+	}
+	return k.(AccountID), nil
+}
+
+// ParseSeed decodes the provided 'S...' StrKey.
+func ParseSeed(src string) (Seed, error) {
+	k, err := parseExpected(VersionByteSeed, src)
+	if err != nil {
+		return Seed{}, err
+	}
+	return k.(Seed), nil
+}
+
+// ParseHashTx decodes the provided 'T...' StrKey.
+func ParseHashTx(src string) (HashTx, error) {
+	k, err := parseExpected(VersionByteHashTx, src)
+	if err != nil {
+		return HashTx{}, err
+	}
+	return k.(HashTx), nil
+}
+
+// ParseHashX decodes the provided 'X...' StrKey.
+func ParseHashX(src string) (HashX, error) {
+	k, err := parseExpected(VersionByteHashX, src)
+	if err != nil {
+		return HashX{}, err
+	}
+	return k.(HashX), nil
+}
+
+// Version implements Key.
+func (k AccountID) Version() VersionByte { return VersionByteAccountID }
+
+// Address implements Key.
+func (k AccountID) Address() string { return MustEncode(VersionByteAccountID, k.raw[:]) }
+
+// Raw implements Key.
+func (k AccountID) Raw() [32]byte { return k.raw }
+
+// Version implements Key.
+func (k Seed) Version() VersionByte { return VersionByteSeed }
+
+// Address implements Key.
+func (k Seed) Address() string { return MustEncode(VersionByteSeed, k.raw[:]) }
+
+// Raw implements Key.
+func (k Seed) Raw() [32]byte { return k.raw }
+
+// Version implements Key.
+func (k HashTx) Version() VersionByte { return VersionByteHashTx }
+
+// Address implements Key.
+func (k HashTx) Address() string { return MustEncode(VersionByteHashTx, k.raw[:]) }
+
+// Raw implements Key.
+func (k HashTx) Raw() [32]byte { return k.raw }
+
+// Version implements Key.
+func (k HashX) Version() VersionByte { return VersionByteHashX }
+
+// Address implements Key.
+func (k HashX) Address() string { return MustEncode(VersionByteHashX, k.raw[:]) }
+
+// Raw implements Key.
+func (k HashX) Raw() [32]byte { return k.raw }
+
+// String returns a human readable name of the kind of strkey the version byte
+// identifies.
+func (v VersionByte) String() string {
+	switch v {
+	case VersionByteAccountID:
+		return "account id"
+	case VersionByteSeed:
+		return "seed"
+	case VersionByteHashTx:
+		return "hash-tx signer"
+	case VersionByteHashX:
+		return "hash-x signer"
+	default:
+		return fmt.Sprintf("unknown version byte %d", byte(v))
+	}
+}
+
+// parseExpected parses `src`, returning an error wrapping
+// ErrInvalidVersionByte that names both kinds if it is not of the `expected`
+// kind.  The error never includes `src` itself, which may be a seed.
+func parseExpected(expected VersionByte, src string) (Key, error) {
+	k, err := ParseKey(src)
+	if err != nil {
+		return nil, err
+	}
+
+	if k.Version() != expected {
+		return nil, errors.Wrapf(ErrInvalidVersionByte, "expected %s, got %s", expected, k.Version())
+	}
+
+	return k, nil
+}
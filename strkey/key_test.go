@@ -0,0 +1,92 @@
+package strkey
+
+import (
+	"encoding/base32"
+	"testing"
+
+	"github.com/stellar/go/crc16"
+	"github.com/stellar/go/support/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKey(t *testing.T) {
+	cases := []struct {
+		Address  string
+		Expected VersionByte
+	}{
+		{"GA3D5KRYM6CB7OWQ6TWYRR3Z4T7GNZLKERYNZGGA5SOAOPIFY6YQHES5", VersionByteAccountID},
+		{"SBU2RRGLXH3E5CQHTD3ODLDF2BWDCYUSSBLLZ5GNW7JXHDIYKXZWHOKR", VersionByteSeed},
+		{"TBU2RRGLXH3E5CQHTD3ODLDF2BWDCYUSSBLLZ5GNW7JXHDIYKXZWHXL7", VersionByteHashTx},
+		{"XBU2RRGLXH3E5CQHTD3ODLDF2BWDCYUSSBLLZ5GNW7JXHDIYKXZWGTOG", VersionByteHashX},
+	}
+
+	for _, kase := range cases {
+		version, payload, err := DecodeAny(kase.Address)
+		require.NoError(t, err, kase.Address)
+		assert.Equal(t, kase.Expected, version)
+		assert.Equal(t, MustDecode(kase.Expected, kase.Address), payload)
+
+		k, err := ParseKey(kase.Address)
+		require.NoError(t, err, kase.Address)
+		assert.Equal(t, kase.Expected, k.Version())
+		assert.Equal(t, kase.Address, k.Address())
+
+		raw := k.Raw()
+		assert.Equal(t, payload, raw[:])
+	}
+
+	k, _ := ParseKey("GA3D5KRYM6CB7OWQ6TWYRR3Z4T7GNZLKERYNZGGA5SOAOPIFY6YQHES5")
+	assert.IsType(t, AccountID{}, k)
+	k, _ = ParseKey("SBU2RRGLXH3E5CQHTD3ODLDF2BWDCYUSSBLLZ5GNW7JXHDIYKXZWHOKR")
+	assert.IsType(t, Seed{}, k)
+	k, _ = ParseKey("TBU2RRGLXH3E5CQHTD3ODLDF2BWDCYUSSBLLZ5GNW7JXHDIYKXZWHXL7")
+	assert.IsType(t, HashTx{}, k)
+	k, _ = ParseKey("XBU2RRGLXH3E5CQHTD3ODLDF2BWDCYUSSBLLZ5GNW7JXHDIYKXZWGTOG")
+	assert.IsType(t, HashX{}, k)
+}
+
+func TestParseKey_Invalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"GA3D5KRYM6CB7OWQ6TWYRR3Z4T7GNZLKERYNZGGA5SOAOPIFY6YQHES6",
+		"GA3D5KRYM6CB7OWQ6TWYRR3Z4T7GNZLKERYNZGGA5SOAOPIFY6YQHES",
+		"gA3D5KRYM6CB7OWQ6TWYRR3Z4T7GNZLKERYNZGGA5SOAOPIFY6YQHES5",
+		MustEncode(VersionByteAccountID, []byte{1, 2, 3}),
+	}
+
+	for _, address := range invalid {
+		_, err := ParseKey(address)
+		assert.Error(t, err, address)
+	}
+
+	// a value whose checksum is valid but whose version byte is not defined
+	raw := append([]byte{1 << 3}, make([]byte, 32)...)
+	raw = append(raw, crc16.Checksum(raw)...)
+	_, _, err := DecodeAny(base32.StdEncoding.EncodeToString(raw))
+	assert.Equal(t, ErrInvalidVersionByte, err)
+}
+
+func TestParseExpected(t *testing.T) {
+	seed := "SBU2RRGLXH3E5CQHTD3ODLDF2BWDCYUSSBLLZ5GNW7JXHDIYKXZWHOKR"
+
+	aid, err := ParseAccountID("GA3D5KRYM6CB7OWQ6TWYRR3Z4T7GNZLKERYNZGGA5SOAOPIFY6YQHES5")
+	require.NoError(t, err)
+	assert.Equal(t, "GA3D5KRYM6CB7OWQ6TWYRR3Z4T7GNZLKERYNZGGA5SOAOPIFY6YQHES5", aid.Address())
+
+	s, err := ParseSeed(seed)
+	require.NoError(t, err)
+	assert.Equal(t, seed, s.Address())
+
+	_, err = ParseAccountID(seed)
+	require.Error(t, err)
+	assert.Equal(t, ErrInvalidVersionByte, errors.Cause(err))
+	assert.Contains(t, err.Error(), "expected account id, got seed")
+	assert.NotContains(t, err.Error(), seed)
+
+	_, err = ParseHashTx("XBU2RRGLXH3E5CQHTD3ODLDF2BWDCYUSSBLLZ5GNW7JXHDIYKXZWGTOG")
+	assert.Equal(t, ErrInvalidVersionByte, errors.Cause(err))
+
+	_, err = ParseHashX("TBU2RRGLXH3E5CQHTD3ODLDF2BWDCYUSSBLLZ5GNW7JXHDIYKXZWHXL7")
+	assert.Equal(t, ErrInvalidVersionByte, errors.Cause(err))
+}
@@ -68,7 +68,7 @@ func isStellarAccountID(i interface{}, context interface{}) bool {
 		return false
 	}
 
-	_, err := strkey.Decode(strkey.VersionByteAccountID, enc)
+	_, err := strkey.ParseAccountID(enc)
 
 	if err == nil {
 		return true
@@ -84,7 +84,7 @@ func isStellarSeed(i interface{}, context interface{}) bool {
 		return false
 	}
 
-	_, err := strkey.Decode(strkey.VersionByteSeed, enc)
+	_, err := strkey.ParseSeed(enc)
 
 	if err == nil {
 		return true
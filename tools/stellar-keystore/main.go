@@ -0,0 +1,339 @@
+// stellar-keystore is a small utility to manage keystores: files holding a
+// stellar seed encrypted with a password.
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/howeyc/gopass"
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/support/errors"
+)
+
+func main() {
+	rootCmd := &cobra.Command{
+		Use:   "stellar-keystore",
+		Short: "manage password protected stellar seeds",
+	}
+
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "create a keystore for a new (or, with --import, an existing) seed",
+		Run:   create,
+	}
+	createCmd.Flags().Bool("import", false, "encrypt an existing seed instead of a random one")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "list the keystores in the keystore directory",
+		Run:   list,
+	}
+
+	reencryptCmd := &cobra.Command{
+		Use:   "reencrypt [path]",
+		Short: "change the password of a keystore",
+		Run:   reencrypt,
+	}
+
+	splitCmd := &cobra.Command{
+		Use:   "split",
+		Short: "split a seed into shares, any threshold of which recover it",
+		Run:   split,
+	}
+	splitCmd.Flags().Int("shares", 5, "number of shares to produce")
+	splitCmd.Flags().Int("threshold", 3, "number of shares needed to recover the seed")
+	splitCmd.Flags().String("keystore", "", "split the seed held by this keystore instead of prompting for one")
+
+	combineCmd := &cobra.Command{
+		Use:   "combine",
+		Short: "recover a seed from shares read from stdin, one per line",
+		Run:   combine,
+	}
+
+	signMessageCmd := &cobra.Command{
+		Use:   "sign-message [message]",
+		Short: "sign a message to prove ownership of an account",
+		Run:   signMessage,
+	}
+	signMessageCmd.Flags().String("keystore", "", "sign with the seed held by this keystore instead of prompting for one")
+
+	verifyMessageCmd := &cobra.Command{
+		Use:   "verify-message [address] [message] [signature]",
+		Short: "verify a signature produced by sign-message",
+		Run:   verifyMessage,
+	}
+
+	rootCmd.PersistentFlags().String("dir", ".", "keystore directory")
+	rootCmd.AddCommand(
+		createCmd,
+		listCmd,
+		reencryptCmd,
+		splitCmd,
+		combineCmd,
+		signMessageCmd,
+		verifyMessageCmd,
+	)
+	rootCmd.Execute()
+}
+
+func create(cmd *cobra.Command, args []string) {
+	var (
+		kp  *keypair.Full
+		err error
+	)
+
+	if imp, _ := cmd.Flags().GetBool("import"); imp {
+		kp, err = readKeypair("")
+		if err != nil {
+			fatal(err)
+		}
+	} else {
+		kp, err = keypair.Random()
+		if err != nil {
+			fatal(err)
+		}
+	}
+
+	password, err := readNewPassword()
+	if err != nil {
+		fatal(err)
+	}
+
+	ks, err := keypair.EncryptKeystore(kp, password)
+	if err != nil {
+		fatal(err)
+	}
+
+	path := filepath.Join(dir(cmd), kp.Address()+".json")
+	if _, err = os.Stat(path); err == nil {
+		fatal(errors.Errorf("keystore already exists: %s", path))
+	}
+
+	err = ks.Write(path)
+	if err != nil {
+		fatal(err)
+	}
+
+	fmt.Printf("Public: %s\n", kp.Address())
+	fmt.Printf("Keystore: %s\n", path)
+}
+
+func list(cmd *cobra.Command, args []string) {
+	paths, err := filepath.Glob(filepath.Join(dir(cmd), "*.json"))
+	if err != nil {
+		fatal(err)
+	}
+
+	for _, path := range paths {
+		ks, err := keypair.ReadKeystore(path)
+		if err != nil {
+			// not every json file is a keystore
+			continue
+		}
+
+		fmt.Printf("%s\t%s\n", ks.Address, path)
+	}
+}
+
+func reencrypt(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Usage()
+		os.Exit(1)
+	}
+	path := args[0]
+
+	ks, err := keypair.ReadKeystore(path)
+	if err != nil {
+		fatal(err)
+	}
+
+	old, err := readSecret("Enter current password: ")
+	if err != nil {
+		fatal(err)
+	}
+
+	// check the old password before asking for a new one
+	_, err = ks.Decrypt(old)
+	if err != nil {
+		fatal(err)
+	}
+
+	password, err := readNewPassword()
+	if err != nil {
+		fatal(err)
+	}
+
+	err = ks.Reencrypt(old, password)
+	if err != nil {
+		fatal(err)
+	}
+
+	err = ks.Write(path)
+	if err != nil {
+		fatal(err)
+	}
+
+	fmt.Printf("Re-encrypted %s\n", ks.Address)
+}
+
+func split(cmd *cobra.Command, args []string) {
+	n, _ := cmd.Flags().GetInt("shares")
+	k, _ := cmd.Flags().GetInt("threshold")
+	path, _ := cmd.Flags().GetString("keystore")
+
+	kp, err := readKeypair(path)
+	if err != nil {
+		fatal(err)
+	}
+
+	shares, err := keypair.SplitSeed(kp, n, k)
+	if err != nil {
+		fatal(err)
+	}
+
+	fmt.Printf("Public: %s\n", kp.Address())
+	fmt.Printf("Any %d of the following %d shares recover the seed:\n", k, n)
+	for i, share := range shares {
+		fmt.Printf("%d: %s\n", i+1, share)
+	}
+}
+
+func combine(cmd *cobra.Command, args []string) {
+	var shares []string
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			break
+		}
+		shares = append(shares, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		fatal(err)
+	}
+
+	kp, err := keypair.CombineShares(shares)
+	if err != nil {
+		fatal(err)
+	}
+
+	fmt.Printf("Secret seed: %s\n", kp.Seed())
+	fmt.Printf("Public: %s\n", kp.Address())
+}
+
+func signMessage(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Usage()
+		os.Exit(1)
+	}
+
+	path, _ := cmd.Flags().GetString("keystore")
+	kp, err := readKeypair(path)
+	if err != nil {
+		fatal(err)
+	}
+
+	sig, err := keypair.SignMessage(kp, []byte(args[0]))
+	if err != nil {
+		fatal(err)
+	}
+
+	fmt.Printf("Public: %s\n", kp.Address())
+	fmt.Printf("Signature: %s\n", sig)
+}
+
+func verifyMessage(cmd *cobra.Command, args []string) {
+	if len(args) != 3 {
+		cmd.Usage()
+		os.Exit(1)
+	}
+
+	err := keypair.VerifyMessage(args[0], []byte(args[1]), args[2])
+	if err != nil {
+		fatal(err)
+	}
+
+	fmt.Println("Signature is valid")
+}
+
+// readKeypair decrypts the keystore at `path`, prompting for its password, or
+// prompts for a seed when `path` is empty.
+func readKeypair(path string) (*keypair.Full, error) {
+	if path != "" {
+		ks, err := keypair.ReadKeystore(path)
+		if err != nil {
+			return nil, err
+		}
+
+		password, err := readSecret(fmt.Sprintf("Enter password for %s: ", ks.Address))
+		if err != nil {
+			return nil, err
+		}
+
+		return ks.Decrypt(password)
+	}
+
+	seed, err := readSecret("Enter seed: ")
+	if err != nil {
+		return nil, err
+	}
+
+	kp, err := keypair.Parse(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	full, ok := kp.(*keypair.Full)
+	if !ok {
+		return nil, errors.New("not a seed")
+	}
+
+	return full, nil
+}
+
+func dir(cmd *cobra.Command) string {
+	return cmd.Flags().Lookup("dir").Value.String()
+}
+
+func readNewPassword() (string, error) {
+	password, err := readSecret("Enter new password: ")
+	if err != nil {
+		return "", err
+	}
+
+	confirm, err := readSecret("Confirm new password: ")
+	if err != nil {
+		return "", err
+	}
+
+	if password != confirm {
+		return "", errors.New("passwords do not match")
+	}
+
+	if password == "" {
+		return "", errors.New("password must not be empty")
+	}
+
+	return password, nil
+}
+
+func readSecret(prompt string) (string, error) {
+	fmt.Fprint(os.Stdout, prompt)
+	str, err := gopass.GetPasswdMasked()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(str)), nil
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -15,12 +15,14 @@ import (
 
 	"github.com/howeyc/gopass"
 	"github.com/stellar/go/build"
+	"github.com/stellar/go/keypair"
 	"github.com/stellar/go/xdr"
 )
 
 var in *bufio.Reader
 
 var infile = flag.String("infile", "", "transaction envelope")
+var keystore = flag.String("keystore", "", "keystore holding the signing seed")
 
 func main() {
 	flag.Parse()
@@ -69,7 +71,7 @@ func main() {
 	// TODO: add operation details
 
 	// read seed
-	seed, err := readLine("Enter seed: ", true)
+	seed, err := readSeed()
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -95,6 +97,31 @@ func main() {
 
 }
 
+// readSeed prompts for the signing seed, or for the password of the keystore
+// holding it when one was provided.
+func readSeed() (string, error) {
+	if *keystore == "" {
+		return readLine("Enter seed: ", true)
+	}
+
+	ks, err := keypair.ReadKeystore(*keystore)
+	if err != nil {
+		return "", err
+	}
+
+	password, err := readLine(fmt.Sprintf("Enter password for %s: ", ks.Address), true)
+	if err != nil {
+		return "", err
+	}
+
+	kp, err := ks.Decrypt(password)
+	if err != nil {
+		return "", err
+	}
+
+	return kp.Seed(), nil
+}
+
 func readLine(prompt string, private bool) (string, error) {
 	fmt.Fprintf(os.Stdout, prompt)
 	var line string
@@ -1,7 +1,6 @@
 package xdr
 
 import (
-	"errors"
 	"fmt"
 
 	"github.com/stellar/go/strkey"
@@ -58,19 +57,12 @@ func (aid *AccountId) SetAddress(address string) error {
 		return nil
 	}
 
-	raw, err := strkey.Decode(strkey.VersionByteAccountID, address)
+	k, err := strkey.ParseAccountID(address)
 	if err != nil {
 		return err
 	}
 
-	if len(raw) != 32 {
-		return errors.New("invalid address")
-	}
-
-	var ui Uint256
-	copy(ui[:], raw)
-
-	*aid, err = NewAccountId(PublicKeyTypePublicKeyTypeEd25519, ui)
+	*aid, err = NewAccountId(PublicKeyTypePublicKeyTypeEd25519, Uint256(k.Raw()))
 
 	return err
 }
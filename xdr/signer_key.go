@@ -68,37 +68,25 @@ func (skey *SignerKey) SetAddress(address string) error {
 		return nil
 	}
 
-	vb, err := strkey.Version(address)
+	k, err := strkey.ParseKey(address)
 	if err != nil {
-		return errors.Wrap(err, "failed to extract address version")
+		return errors.Wrap(err, "failed to parse address")
 	}
 
 	var keytype SignerKeyType
 
-	switch vb {
-	case strkey.VersionByteAccountID:
+	switch k.(type) {
+	case strkey.AccountID:
 		keytype = SignerKeyTypeSignerKeyTypeEd25519
-	case strkey.VersionByteHashX:
+	case strkey.HashX:
 		keytype = SignerKeyTypeSignerKeyTypeHashX
-	case strkey.VersionByteHashTx:
+	case strkey.HashTx:
 		keytype = SignerKeyTypeSignerKeyTypeHashTx
 	default:
-		return errors.Errorf("invalid version byte: %v", vb)
+		return errors.Wrapf(strkey.ErrInvalidVersionByte, "expected signer key, got %s", k.Version())
 	}
 
-	raw, err := strkey.Decode(vb, address)
-	if err != nil {
-		return err
-	}
-
-	if len(raw) != 32 {
-		return errors.New("invalid address")
-	}
-
-	var ui Uint256
-	copy(ui[:], raw)
-
-	*skey, err = NewSignerKey(keytype, ui)
+	*skey, err = NewSignerKey(keytype, Uint256(k.Raw()))
 
 	return err
 }